@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/artwork"
+	"audictl/internal/config"
+	"audictl/internal/provider"
+)
+
+const (
+	// artCols is the width of the album art in the Now Playing panel.
+	artCols = 20
+	// artImageID names the album art for terminals that keep images
+	// (kitty) so it can be replaced and removed.
+	artImageID = 4242
+)
+
+// artCells is the album art rendered as half blocks for a size.
+type artCells struct {
+	img           image.Image
+	width, height int
+	lines         []string
+}
+
+// artPlacement is an image drawn with a graphics protocol and where.
+type artPlacement struct {
+	img  image.Image
+	rect image.Rectangle
+}
+
+// artProtocol returns the protocol album art is drawn with, or "" when
+// it is turned off.
+func artProtocol(cfg *config.Config) artwork.Protocol {
+	switch cfg.AlbumArt {
+	case "off":
+		return ""
+	case "", "auto":
+		return artwork.Detect()
+	}
+	return artwork.Protocol(cfg.AlbumArt)
+}
+
+// loadArt fetches the cover art of track and shows it if track is still
+// playing by then. Low-data mode leaves it out.
+func (p *player) loadArt(track provider.Track) {
+	p.mu.Lock()
+	off := p.artProto == "" || p.lowData
+	p.mu.Unlock()
+	if off {
+		return
+	}
+	img, err := artwork.Fetch(track)
+	if err != nil {
+		img = nil
+	}
+	p.mu.Lock()
+	current := p.currentTrk != nil && p.currentTrk.ID == track.ID
+	p.mu.Unlock()
+	if current {
+		p.app.QueueUpdateDraw(func() { p.setArt(img) })
+	}
+}
+
+// setArtProtocol switches the protocol album art is drawn with, clearing
+// the art drawn with the old one and fetching it again for the new.
+// Called from the UI goroutine.
+func (p *player) setArtProtocol(proto artwork.Protocol) {
+	if proto == p.artProto {
+		return
+	}
+	p.showPreview(nil)
+	p.setArt(nil)
+	p.mu.Lock()
+	p.artProto = proto
+	track := p.currentTrk
+	p.mu.Unlock()
+	if track != nil {
+		go p.loadArt(*track)
+	}
+}
+
+// setArt shows img in the Now Playing panel, or hides the art when img is
+// nil. Called from the UI goroutine.
+func (p *player) setArt(img image.Image) {
+	if p.artProto == "" {
+		return
+	}
+	p.artImg = img
+	p.fitArt()
+}
+
+// fitArt sizes the art view for the image it shows, if any. Called from
+// the UI goroutine.
+func (p *player) fitArt() {
+	width := 0
+	switch {
+	case p.previewImg != nil:
+		width = previewCols + 1
+	case p.artImg != nil:
+		width = artCols + 1
+	}
+	p.nowPanel.ResizeItem(p.artView, width, 0)
+}
+
+// shownArt returns the image the art view shows: the seek preview while
+// there is one, else the album art.
+func (p *player) shownArt() image.Image {
+	if p.previewImg != nil {
+		return p.previewImg
+	}
+	return p.artImg
+}
+
+// drawArtCells draws the album art, or the seek preview, as half blocks into the art view, and
+// records where it is for drawArt.
+func (p *player) drawArtCells(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	width-- // a gap to the text
+	if width <= 0 || height <= 0 {
+		p.artRect = image.Rectangle{}
+		return x, y, 0, 0
+	}
+	p.artRect = image.Rect(x, y, x+width, y+height)
+	img := p.shownArt()
+	if img == nil || p.artProto.Graphics() {
+		return x, y, width, height
+	}
+	c := &p.artCells
+	if c.img != img || c.width != width || c.height != height {
+		text := artwork.HalfBlocks(img, width, height)
+		*c = artCells{img: img, width: width, height: height, lines: strings.Split(text, "\n")}
+	}
+	for i, line := range c.lines {
+		tview.Print(screen, line, x, y+i, width, tview.AlignLeft, tcell.ColorDefault)
+	}
+	return x, y, width, height
+}
+
+// drawArt draws the album art, or the seek preview, with a graphics protocol after the rest of
+// the screen, when it changed or moved. It is hidden while a modal is
+// open, which may cover it.
+func (p *player) drawArt(screen tcell.Screen) {
+	if !p.artProto.Graphics() {
+		return
+	}
+	var want artPlacement
+	if img := p.shownArt(); img != nil && !p.modalOpen() && !p.miniMode() && !p.artRect.Empty() {
+		want = artPlacement{img: img, rect: p.artRect}
+	}
+	if want == p.artShown {
+		return
+	}
+	tty, ok := screen.Tty()
+	if !ok {
+		return
+	}
+	// Let tcell write its cells first so they don't paint over the image
+	screen.Show()
+	if p.artShown.img != nil {
+		if clear := artwork.Clear(p.artProto, artImageID); clear != "" {
+			_, _ = tty.Write([]byte(clear))
+		} else {
+			// The image stays until its cells are redrawn
+			screen.Sync()
+		}
+	}
+	p.artShown = want
+	if want.img == nil {
+		return
+	}
+	cols, rows := artwork.Size(want.img, want.rect.Dx(), want.rect.Dy())
+	seq := artwork.Encode(p.artProto, want.img, cols, rows, artImageID)
+	// Save the cursor, draw at the art's top left, then restore it
+	_, _ = fmt.Fprintf(tty, "\x1b7\x1b[%d;%dH%s\x1b8", want.rect.Min.Y+1, want.rect.Min.X+1, seq)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rivo/tview"
+
+	"audictl/internal/debuglog"
+	"audictl/internal/provider"
+	sprov "audictl/providers/spotify"
+	yprov "audictl/providers/youtube"
+)
+
+// batchWorkers is how many lines of a batch are resolved at once.
+const batchWorkers = 4
+
+// errNoMatch is a batch line's error when its search found nothing.
+var errNoMatch = errors.New("no match")
+
+// readBatch reads the lines of a batch file, or of stdin for "-": one
+// search or link each. Blank lines and lines starting with # are left out.
+func readBatch(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var lines []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, sc.Err()
+}
+
+// queueBatch resolves lines concurrently and appends what they found to
+// the queue in their order, showing the progress as it goes and how many
+// lines failed at the end; the debug console lists those. Links add all
+// their tracks; other lines add the top result of searching for them.
+func (p *player) queueBatch(lines []string) {
+	defer p.recoverPanic()
+	found := make([][]provider.Track, len(lines))
+	errs := make([]error, len(lines))
+
+	var (
+		mu   sync.Mutex
+		done int
+		wg   sync.WaitGroup
+	)
+	next := make(chan int)
+	for range min(batchWorkers, len(lines)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer p.recoverPanic()
+			for i := range next {
+				found[i], errs[i] = p.resolveBatchLine(lines[i])
+				mu.Lock()
+				done++
+				n := done
+				mu.Unlock()
+				p.notify(fmt.Sprintf("[gray]Queueing:[-] %d/%d", n, len(lines)))
+			}
+		}()
+	}
+	for i := range lines {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var tracks []provider.Track
+	var failed []string
+	for i, line := range lines {
+		if errs[i] != nil {
+			debuglog.Printf("app", "batch: failed: %s: %s", line, provider.Explain(errs[i]))
+			failed = append(failed, line)
+			continue
+		}
+		tracks = append(tracks, found[i]...)
+	}
+	p.mu.Lock()
+	p.queue = append(p.queue, tracks...)
+	p.mu.Unlock()
+	p.updateQueueView()
+
+	msg := fmt.Sprintf("[green]+ Queued:[-] %d tracks", len(tracks))
+	if len(failed) > 0 {
+		// The debug console lists them all with the reasons
+		msg += fmt.Sprintf(" [red]%d of %d lines failed[-], first: %s [gray](` for all)[-]",
+			len(failed), len(lines), tview.Escape(truncate(failed[0], 30)))
+	}
+	p.notify(msg)
+}
+
+// resolveBatchLine returns the tracks a batch line stands for.
+func (p *player) resolveBatchLine(line string) ([]provider.Track, error) {
+	var tracks []provider.Track
+	var err error
+	switch {
+	case strings.Contains(line, "youtube.com") || strings.Contains(line, "youtu.be"):
+		tracks, err = yprov.New().FetchTracksFromURL(line, 0)
+	case strings.Contains(line, "spotify.com"):
+		tracks, err = sprov.New().FetchTracksFromURL(line)
+	default:
+		// The first provider with a result, in the order they are searched
+		query, kind := p.searchKind(line)
+		for _, res := range p.providers.SearchAll(query, kind, 1) {
+			if res.Err != nil {
+				err = res.Err
+				continue
+			}
+			if len(res.Tracks) > 0 {
+				return res.Tracks[:1], nil
+			}
+		}
+	}
+	if err == nil && len(tracks) == 0 {
+		err = errNoMatch
+	}
+	return tracks, err
+}
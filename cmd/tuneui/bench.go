@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"audictl/internal/backend"
+	"audictl/internal/provider"
+)
+
+// benchAudioTimeout bounds how long a run waits for the player to start.
+const benchAudioTimeout = 30 * time.Second
+
+// runBench searches for query, resolves the top result and plays it runs
+// times, then prints how long each step took. The first run is reported on
+// its own because later ones are usually served from the caches.
+func runBench(prov provider.Provider, query string, runs int, name string, opts backend.Options) error {
+	b, err := backend.Start(name, opts)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+	events := b.Events()
+
+	var search, resolve, audio []time.Duration
+	for i := 0; i < runs; i++ {
+		start := time.Now()
+		results, err := prov.Search(query, provider.SearchKindTrack, 10)
+		if err != nil {
+			return fmt.Errorf("run %d: search: %w", i+1, err)
+		}
+		if len(results) == 0 {
+			return fmt.Errorf("no results for %q", query)
+		}
+		search = append(search, time.Since(start))
+		track := results[0]
+
+		start = time.Now()
+		stream, err := prov.ResolveStream(track, provider.QualityAny)
+		if err != nil {
+			return fmt.Errorf("run %d: resolve: %w", i+1, err)
+		}
+		resolve = append(resolve, time.Since(start))
+
+		// Time to first audio counts from resolving, as when a track is picked
+		if err := b.Load(playURL(track, stream, false, b.OpensPages()), backend.FileOptions{}); err != nil {
+			return fmt.Errorf("run %d: load: %w", i+1, err)
+		}
+		if err := waitForAudio(events); err != nil {
+			return fmt.Errorf("run %d: %w", i+1, err)
+		}
+		audio = append(audio, time.Since(start))
+		_ = b.Stop()
+
+		fmt.Printf("run %d/%d: %s\n", i+1, runs, track.Title)
+	}
+
+	fmt.Printf("\n%d runs of %q\n", runs, query)
+	printBench("search", search)
+	printBench("resolve", resolve)
+	printBench("first audio", audio)
+	return nil
+}
+
+// waitForAudio waits for the player to report that playback of the loaded
+// file has begun.
+func waitForAudio(events <-chan backend.Event) error {
+	timeout := time.After(benchAudioTimeout)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return fmt.Errorf("player exited")
+			}
+			switch {
+			case ev.Event == "playback-restart":
+				return nil
+			case ev.Event == "end-file" && ev.Reason == "error":
+				return fmt.Errorf("player: %s", ev.FileError)
+			}
+		case <-timeout:
+			return fmt.Errorf("no audio after %s", benchAudioTimeout)
+		}
+	}
+}
+
+// printBench prints one summary line: the first (cold) run, then the
+// min/median/max of the rest.
+func printBench(name string, d []time.Duration) {
+	line := fmt.Sprintf("  %-12s cold %-8s", name, round(d[0]))
+	if rest := append([]time.Duration(nil), d[1:]...); len(rest) > 0 {
+		sort.Slice(rest, func(i, j int) bool { return rest[i] < rest[j] })
+		line += fmt.Sprintf("  warm min %-8s median %-8s max %s",
+			round(rest[0]), round(rest[len(rest)/2]), round(rest[len(rest)-1]))
+	}
+	fmt.Println(line)
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
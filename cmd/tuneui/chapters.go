@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"audictl/internal/provider"
+)
+
+// chapterTries is how many times, a second apart, mpv is asked for the
+// chapters of a track that just started: its ytdl hook adds those of page
+// URLs only once it has loaded them.
+const chapterTries = 5
+
+// chapterRestart is how far into a chapter "previous chapter" goes back to
+// its start rather than to the chapter before.
+const chapterRestart = 3
+
+// trackChapters are the chapters of the playing track.
+type trackChapters struct {
+	trackID  string
+	chapters []provider.Chapter
+	shown    int // index of the chapter shown in Now Playing, -1 for none
+}
+
+// loadChapters finds the chapters of track, which just started: those the
+// provider resolved along with the stream, or else those mpv knows, such
+// as embedded in audiobook files.
+func (p *player) loadChapters(track provider.Track, chapters []provider.Chapter) {
+	defer p.recoverPanic()
+	for try := 0; len(chapters) == 0 && try < chapterTries; try++ {
+		time.Sleep(time.Second)
+		p.mu.Lock()
+		if p.currentTrk == nil || p.currentTrk.ID != track.ID {
+			p.mu.Unlock()
+			return
+		}
+		m := p.mpvCtl()
+		p.mu.Unlock()
+		if m == nil {
+			return
+		}
+		list, err := m.Chapters()
+		if err != nil {
+			continue
+		}
+		for _, c := range list {
+			chapters = append(chapters, provider.Chapter{Start: c.Time, Title: c.Title})
+		}
+	}
+	if len(chapters) < 2 {
+		// One chapter is the whole track
+		return
+	}
+	p.mu.Lock()
+	if p.currentTrk != nil && p.currentTrk.ID == track.ID {
+		p.chapters = &trackChapters{trackID: track.ID, chapters: chapters, shown: -1}
+	}
+	p.mu.Unlock()
+}
+
+// chapterAt returns the index of the chapter pos seconds falls into, or -1
+// before the first.
+func chapterAt(chapters []provider.Chapter, pos float64) int {
+	i := -1
+	for j, c := range chapters {
+		if c.Start <= pos {
+			i = j
+		}
+	}
+	return i
+}
+
+// chapterMarks returns where the chapters of track start, as fractions of
+// its duration, for the progress bar. Must be called with p.mu held.
+func (p *player) chapterMarks(track provider.Track) []float64 {
+	c := p.chapters
+	if c == nil || c.trackID != track.ID || track.Duration <= 0 {
+		return nil
+	}
+	var marks []float64
+	for _, ch := range c.chapters {
+		if ch.Start > 0 {
+			marks = append(marks, ch.Start/float64(track.Duration))
+		}
+	}
+	return marks
+}
+
+// showChapter shows the chapter of track that pos seconds falls into in
+// Now Playing, when it changed.
+func (p *player) showChapter(track provider.Track, pos float64) {
+	p.mu.Lock()
+	c := p.chapters
+	if c == nil || c.trackID != track.ID {
+		p.mu.Unlock()
+		return
+	}
+	i := chapterAt(c.chapters, pos)
+	if i == c.shown {
+		p.mu.Unlock()
+		return
+	}
+	c.shown = i
+	details := p.nowDetails
+	p.mu.Unlock()
+	p.updateNowPlaying(nowPlayingText(track) + details + p.chapterLine(track))
+}
+
+// chapterLine is the Now Playing line of the chapter of track last shown,
+// or "" when there is none.
+func (p *player) chapterLine(track provider.Track) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c := p.chapters
+	if c == nil || c.trackID != track.ID || c.shown < 0 {
+		return ""
+	}
+	title := c.chapters[c.shown].Title
+	if title == "" {
+		title = clock(c.chapters[c.shown].Start)
+	}
+	return fmt.Sprintf("\n[gray]Chapter %d/%d:[-] %s", c.shown+1, len(c.chapters), tview.Escape(title))
+}
+
+// skipChapter seeks to the next chapter of the playing track for dir 1,
+// or for -1 to the start of the current one, or the one before when
+// already there.
+func (p *player) skipChapter(dir int) {
+	p.mu.Lock()
+	c := p.chapters
+	track := p.currentTrk
+	pos := p.position()
+	p.mu.Unlock()
+	if track == nil || c == nil || c.trackID != track.ID {
+		p.notify("[yellow]No chapters in this track[-]")
+		return
+	}
+
+	i := chapterAt(c.chapters, pos)
+	target := i + dir
+	if dir < 0 && i >= 0 && pos-c.chapters[i].Start > chapterRestart {
+		target = i
+	}
+	if target >= len(c.chapters) {
+		p.notify("[yellow]This is the last chapter[-]")
+		return
+	}
+	target = max(target, 0)
+	p.seekTo(c.chapters[target].Start)
+	p.showChapter(*track, c.chapters[target].Start)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/fuzzy"
+)
+
+// command is an action of the TUI, listed in the help and, when it can run
+// on its own, in the command palette.
+type command struct {
+	group string
+	key   string // as shown in the help, "" when there is none
+	name  string
+	run   func(p *player) // nil for keys that only make sense in a panel
+}
+
+// send returns a command run that queues a for processActions.
+func send(a action) func(p *player) {
+	return func(p *player) { p.act(a) }
+}
+
+// commands lists every action, grouped as in the help. The keys themselves
+// are handled in setupHandlers; keep both in step.
+func commands() []command {
+	return []command{
+		{"Playback", "Space", "Play/Pause", send(actionPause)},
+		{"Playback", "Enter", "Play selected", nil},
+		{"Playback", "n", "Next track", send(actionNext)},
+		{"Playback", "p", "Previous track", send(actionPrevious)},
+		{"Playback", "s", "Stop", send(actionStop)},
+		{"Playback", "→ ←", "Forward/Rewind 10s", nil},
+		{"Playback", "", "Forward 10s", send(actionFastForward)},
+		{"Playback", "", "Rewind 10s", send(actionRewind)},
+		{"Playback", ":", "Seek to time", (*player).promptSeek},
+		{"Playback", ".", "Next chapter", send(actionNextChapter)},
+		{"Playback", ",", "Previous chapter", send(actionPrevChapter)},
+		{"Playback", "b", "A-B loop", send(actionCycleLoop)},
+		{"Playback", "[", "Slower", send(actionSlower)},
+		{"Playback", "]", "Faster", send(actionFaster)},
+		{"Playback", "Bksp", "Normal speed", send(actionResetSpeed)},
+		{"Playback", "-", "Volume down", send(actionVolumeDown)},
+		{"Playback", "+", "Volume up", send(actionVolumeUp)},
+		{"Playback", "m", "Mute/Unmute", send(actionToggleMute)},
+		{"Playback", "r", "Repeat mode", send(actionCycleRepeat)},
+		{"Playback", "z", "Shuffle", send(actionToggleShuffle)},
+		{"Playback", "u", "Radio mode", send(actionToggleRadio)},
+		{"Playback", "g", "Normalize loudness", send(actionToggleNormalize)},
+		{"Playback", "w", "Video window", send(actionToggleVideo)},
+		{"Playback", "", "Low-data mode", send(actionToggleLowData)},
+		{"Playback", "t", "Elapsed/Remaining time", (*player).toggleRemaining},
+
+		{"Queue", "a", "Add to queue", send(actionAddToQueue)},
+		{"Queue", "i", "Play next", send(actionInsertNext)},
+		{"Queue", "*", "Pin result", send(actionTogglePin)},
+		{"Queue", "d", "Remove from queue", send(actionRemoveFromQueue)},
+		{"Queue", "K", "Move up in queue", send(actionMoveUp)},
+		{"Queue", "J", "Move down in queue", send(actionMoveDown)},
+		{"Queue", "S-↑ ↓", "Move in queue", nil},
+		{"Queue", "f", "Find playing track", (*player).jumpToCurrent},
+		{"Queue", "c", "Clear queue", send(actionClearQueue)},
+
+		{"Library", "^S", "Save playlist", (*player).promptSavePlaylist},
+		{"Library", "^O", "Playlists", (*player).showPlaylists},
+		{"Library", "", "Add track to playlist", (*player).promptAddToPlaylist},
+		{"Library", "^E", "Export queue as M3U", (*player).promptExportQueue},
+		{"Library", "^T", "Sessions", (*player).showSessions},
+		{"Library", "^B", "Music library", (*player).showLibrary},
+		{"Library", "H", "History", (*player).showHistory},
+		{"Library", "h", "Recently played", (*player).showRecent},
+		{"Library", "^G", "Suggestions from history", (*player).showRecommendations},
+		{"Library", "^W", "Listening stats", (*player).showStats},
+
+		{"Search", "^R", "Find past search", (*player).showSearchHistory},
+		{"Search", "↑ ↓", "Past searches (in the search box)", nil},
+		{"Search", "0-9", "Result tabs", nil},
+		{"Search", "l", "Lyrics", (*player).toggleLyrics},
+		{"Search", "y", "Copy link", send(actionCopyLink)},
+		{"Search", "o", "Open in browser", send(actionOpenLink)},
+		{"Search", "", "Clear search cache", (*player).clearSearchCache},
+
+		{"Audio", "e", "Equalizer", (*player).showEQ},
+		{"Audio", "^D", "Switch audio device", (*player).showDevices},
+		{"Audio", "^K", "Snapcast clients", (*player).showSnapcast},
+
+		{"View", "Tab", "Next panel", (*player).nextFocus},
+		{"View", "S-Tab", "Previous panel", (*player).prevFocus},
+		{"View", "Esc", "Unfocus", nil},
+		{"View", "F3", "Results/Queue view", (*player).cycleView},
+		{"View", "<", "Narrow results", func(p *player) { p.moveSplit(-splitStep) }},
+		{"View", ">", "Widen results", func(p *player) { p.moveSplit(splitStep) }},
+		{"View", "F4", "Mini mode", (*player).toggleMini},
+		{"View", "v", "Visualizer (bars, waveform, off)", (*player).toggleViz},
+		{"View", "`", "Debug console", (*player).toggleConsole},
+		{"View", "? F1", "Help", (*player).toggleHelp},
+		{"View", "^P", "Command palette", nil},
+		{"View", "^Z", "Suspend", (*player).suspend},
+		{"View", "q", "Quit", send(actionForceQuit)},
+	}
+}
+
+// helpNotes follow the keys in the help.
+const helpNotes = "[yellow]Mouse:[-]   click the progress bar to seek, scroll on Now Playing for\n" +
+	"         volume, drag queue rows to move them\n" +
+	"\n" +
+	"[yellow]YouTube:[-] yt.be/xxx or youtube.com/...\n" +
+	"[yellow]Spotify:[-] open.spotify.com/track/xxx [gray](→ searches YouTube)[-]\n" +
+	"[yellow]Files:[-]   ~/path/to/list.m3u8, .pls, .xspf"
+
+// helpText lists the keys of commands by group, two to a row.
+func helpText() string {
+	var b strings.Builder
+	group := ""
+	col := 0
+	for _, c := range commands() {
+		if c.key == "" {
+			continue
+		}
+		if c.group != group {
+			if col == 1 {
+				b.WriteString("\n")
+			}
+			group = c.group
+			col = 0
+			fmt.Fprintf(&b, "\n[yellow]%s[-]\n", group)
+		}
+		cell := fmt.Sprintf("  [green]%s[-] %s", tview.Escape(pad(c.key, 6)), tview.Escape(pad(c.name, 30)))
+		if col == 1 {
+			cell = strings.TrimRight(cell, " ") + "\n"
+		}
+		b.WriteString(cell)
+		col = 1 - col
+	}
+	if col == 1 {
+		b.WriteString("\n")
+	}
+	b.WriteString("\n" + helpNotes)
+	return b.String()
+}
+
+// pad fills s with spaces to width runes.
+func pad(s string, width int) string {
+	if n := len([]rune(s)); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// toggleHelp opens the help over the whole screen, or closes it. Called
+// from the UI goroutine.
+func (p *player) toggleHelp() {
+	const name = "help"
+	if p.pages.HasPage(name) {
+		p.hideModal(name)
+		return
+	}
+	view := tview.NewTextView()
+	view.SetDynamicColors(true)
+	view.SetBorder(true).SetTitle(" Help [↑↓=Scroll, ^P=Command palette, Esc=Close] ")
+	view.SetText(helpText())
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc, event.Key() == tcell.KeyF1,
+			event.Rune() == '?', event.Rune() == 'q':
+			p.hideModal(name)
+			return nil
+		case event.Key() == tcell.KeyCtrlP:
+			p.hideModal(name)
+			p.showPalette()
+			return nil
+		}
+		return event
+	})
+	p.showModal(name, view, 0, 0)
+}
+
+// toggleRemaining switches the progress between elapsed and remaining time.
+func (p *player) toggleRemaining() {
+	p.mu.Lock()
+	p.showRemaining = !p.showRemaining
+	p.mu.Unlock()
+}
+
+// showPalette opens the command palette: typing fuzzy-matches the commands
+// by name and Enter runs the selected one. Called from the UI goroutine.
+func (p *player) showPalette() {
+	const name = "palette"
+	var runnable []command
+	var names []string
+	for _, c := range commands() {
+		if c.run != nil {
+			runnable = append(runnable, c)
+			names = append(names, c.name)
+		}
+	}
+
+	input := tview.NewInputField()
+	input.SetLabel(" > ")
+	input.SetFieldWidth(0)
+	input.SetFieldBackgroundColor(p.theme.Field)
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetHighlightFullLine(true)
+	list.SetSelectedBackgroundColor(p.theme.Selection)
+	list.SetSelectedTextColor(p.theme.SelectionText)
+
+	var matches []int
+	refresh := func(pattern string) {
+		matches = fuzzy.Rank(names, pattern)
+		list.Clear()
+		for _, i := range matches {
+			c := runnable[i]
+			list.AddItem(fmt.Sprintf("%s [gray]%s[-] [green]%s[-]",
+				tview.Escape(pad(c.name, 34)), pad(c.group, 9), tview.Escape(c.key)), "", 0, nil)
+		}
+		if len(matches) == 0 {
+			list.AddItem("[gray]No matching commands[-]", "", 0, nil)
+		}
+	}
+	refresh("")
+	input.SetChangedFunc(refresh)
+
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc, tcell.KeyCtrlP:
+			p.hideModal(name)
+			return nil
+		case tcell.KeyEnter:
+			idx := list.GetCurrentItem()
+			if idx < 0 || idx >= len(matches) {
+				return nil
+			}
+			// Run it against the panel that had the focus
+			p.hideModal(name)
+			runnable[matches[idx]].run(p)
+			return nil
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+			// Move through the matches while typing
+			if handler := list.InputHandler(); handler != nil {
+				handler(event, func(tview.Primitive) {})
+			}
+			return nil
+		}
+		return event
+	})
+
+	box := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	box.SetBorder(true).SetTitle(" Commands [Enter=Run, Esc=Close] ")
+	p.showModal(name, box, 64, 20)
+}
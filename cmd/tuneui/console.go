@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rivo/tview"
+
+	"audictl/internal/debuglog"
+)
+
+// consoleHeight is the height of the debug console, borders included.
+const consoleHeight = 12
+
+// consoleColors tell the sources apart in the debug console.
+var consoleColors = map[string]string{
+	"app":    "yellow",
+	"yt-dlp": "red",
+	"mpv":    "green",
+}
+
+// toggleConsole shows or hides the debug console below the panels: the
+// latest log lines, the yt-dlp commands run and the traffic with mpv,
+// newest at the bottom. Called from the UI goroutine.
+func (p *player) toggleConsole() {
+	p.consoleOn = !p.consoleOn
+	if p.consoleOn {
+		var pending atomic.Bool
+		debuglog.OnWrite(func() {
+			// One redraw at a time however fast lines come in
+			if pending.CompareAndSwap(false, true) {
+				p.app.QueueUpdateDraw(func() {
+					pending.Store(false)
+					p.renderConsole()
+				})
+			}
+		})
+		p.renderConsole()
+	} else {
+		debuglog.OnWrite(nil)
+	}
+	p.fillRoot()
+}
+
+// renderConsole fills the debug console with the kept lines, scrolled to
+// the newest. Called from the UI goroutine.
+func (p *player) renderConsole() {
+	if !p.consoleOn {
+		return
+	}
+	var b strings.Builder
+	for _, l := range debuglog.Lines() {
+		color, ok := consoleColors[l.Source]
+		if !ok {
+			color = "white"
+		}
+		fmt.Fprintf(&b, "[gray]%s[-] [%s]%-6s[-] %s\n", l.Time.Format("15:04:05.000"), color, l.Source, tview.Escape(l.Text))
+	}
+	p.consoleView.SetText(strings.TrimSuffix(b.String(), "\n"))
+	p.consoleView.ScrollToEnd()
+}
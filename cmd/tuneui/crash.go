@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"audictl/internal/debuglog"
+	"audictl/internal/store"
+)
+
+// crashDir is where crash reports go, inside the data directory.
+const crashDir = "crashes"
+
+// crashMu is held from the moment a crash is handled until the process
+// exits, so main doesn't return under it once the UI has stopped.
+var crashMu sync.Mutex
+
+// recoverPanic handles a panic in the goroutine it is deferred in: it
+// puts the terminal back, stops mpv, writes a crash report and exits,
+// instead of leaving the terminal raw with the music still playing.
+func (p *player) recoverPanic() {
+	if r := recover(); r != nil {
+		p.crash(r, debug.Stack(), true)
+	}
+}
+
+// recoverUIPanic is recoverPanic for the UI goroutine, deferred around
+// the application's event loop.
+func (p *player) recoverUIPanic() {
+	if r := recover(); r != nil {
+		p.crash(r, debug.Stack(), false)
+	}
+}
+
+// crash ends the program after a panic. restore is false when the UI
+// goroutine panicked: tview has put the terminal back already, and may
+// have been holding its lock.
+func (p *player) crash(r interface{}, stack []byte, restore bool) {
+	crashMu.Lock() // never unlocked
+	if restore {
+		p.app.Stop()
+	}
+	// The goroutine that panicked may hold p.mu, so don't wait for it
+	if b := p.backend; b != nil {
+		_ = b.Close()
+	}
+	if p.viz != nil {
+		p.viz.Stop()
+	}
+
+	fmt.Fprintf(os.Stderr, "tuneui crashed: %v\n", r)
+	path, err := writeCrashReport(r, stack)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not write a crash report (%v):\n\n%s", err, stack)
+	} else {
+		fmt.Fprintf(os.Stderr, "A crash report was written to %s\nPlease attach it when reporting the problem.\n", path)
+	}
+	os.Exit(2)
+}
+
+// waitForCrash returns at once, unless a crash is being handled: then the
+// process exits before it returns.
+func waitForCrash() {
+	crashMu.Lock()
+	crashMu.Unlock()
+}
+
+// writeCrashReport saves what panicked, where, and the latest debug log
+// lines, and returns the file's path.
+func writeCrashReport(r interface{}, stack []byte) (string, error) {
+	dir, err := store.Path(crashDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	path := filepath.Join(dir, "crash-"+now.Format("20060102-150405")+".log")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "tuneui crashed at %s\n\npanic: %v\n\n%s\n", now.Format(time.RFC3339), r, stack)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(&b, "\nbuild: %s %s\n", info.GoVersion, info.Main.Version)
+	}
+	b.WriteString("\nlatest log lines:\n")
+	for _, l := range debuglog.Lines() {
+		fmt.Fprintf(&b, "%s %-6s %s\n", l.Time.Format("15:04:05.000"), l.Source, l.Text)
+	}
+	return path, os.WriteFile(path, []byte(b.String()), 0o644)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"audictl/internal/config"
+	"audictl/internal/mpv"
+	"audictl/internal/store"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// deviceFile remembers the output chosen in the device picker across runs.
+const deviceFile = "device.json"
+
+// loadDevice returns the audio device to start mpv with: that of zone if
+// given, AUDICTL_DEVICE if set, otherwise the one last picked in the TUI
+// ("" for mpv's default).
+func loadDevice(cfg *config.Config, zone string) (string, error) {
+	if zone != "" {
+		dev, ok := cfg.Zones[zone]
+		if !ok {
+			return "", fmt.Errorf("unknown zone %q", zone)
+		}
+		return dev, nil
+	}
+	if dev := os.Getenv("AUDICTL_DEVICE"); dev != "" {
+		return dev, nil
+	}
+	var dev string
+	_ = store.Load(deviceFile, &dev)
+	return dev, nil
+}
+
+// zoneDevices returns the zones of the config as devices described by the
+// zone name, sorted by it.
+func zoneDevices(cfg *config.Config) []mpv.Device {
+	var devices []mpv.Device
+	for zone, dev := range cfg.Zones {
+		devices = append(devices, mpv.Device{Name: dev, Description: zone})
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Description < devices[j].Description })
+	return devices
+}
+
+// zoneOf returns the name of the zone playing on device, or "".
+func zoneOf(cfg *config.Config, device string) string {
+	for _, d := range zoneDevices(cfg) {
+		if d.Name == device {
+			return d.Description
+		}
+	}
+	return ""
+}
+
+// showDevices lists the available audio outputs in a picker. mpv is asked
+// in the background since probing the outputs can take a moment.
+// Must be called from the UI goroutine.
+func (p *player) showDevices() {
+	if msg := p.mpvMissing("Choosing a device"); msg != "" {
+		p.showNotice(msg)
+		return
+	}
+	p.showNotice("[yellow]Listing audio devices...[-]")
+	go func() {
+		devices, err := mpv.ListDevices()
+		if err != nil {
+			p.notify(fmt.Sprintf("[red]Device error:[-] %v", err))
+			return
+		}
+		p.app.QueueUpdateDraw(func() {
+			p.showDevicePicker(devices)
+		})
+	}()
+}
+
+// showDevicePicker shows the zones and then devices with the active one
+// marked; Enter switches to the selected one, moving the playback there.
+// Must be called from the UI goroutine.
+func (p *player) showDevicePicker(devices []mpv.Device) {
+	const name = "devices"
+	zones := zoneDevices(p.config())
+	devices = append(zones, devices...)
+	p.mu.Lock()
+	current := p.device
+	p.mu.Unlock()
+	if current == "" {
+		current = "auto"
+	}
+
+	view := tview.NewList()
+	view.SetBorder(true).SetTitle(" Zones and Audio Devices [Enter=Use, Esc=Close] ")
+	view.SetHighlightFullLine(true)
+	view.SetSelectedBackgroundColor(p.theme.Selection)
+	view.SetSelectedTextColor(p.theme.SelectionText)
+	view.SetSecondaryTextColor(p.theme.Muted)
+	marked := false
+	for i, d := range devices {
+		prefix := "  "
+		if d.Name == current && !marked {
+			prefix = "► "
+			marked = true
+			view.SetCurrentItem(i)
+		}
+		text := tview.Escape(d.Description)
+		if i < len(zones) {
+			text = "[::b]" + text + "[::-] [gray](zone)[-]"
+		}
+		view.AddItem(prefix+text, "    "+tview.Escape(d.Name), 0, nil)
+	}
+	if len(devices) == 0 {
+		view.AddItem("[gray]No audio devices found[-]", "", 0, nil)
+	}
+
+	view.SetSelectedFunc(func(idx int, _ string, _ string, _ rune) {
+		if idx < 0 || idx >= len(devices) {
+			return
+		}
+		p.hideModal(name)
+		go p.setDevice(devices[idx])
+	})
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			p.hideModal(name)
+			return nil
+		}
+		return event
+	})
+
+	p.showModal(name, view, 80, 20)
+}
+
+// setDevice switches the live mpv to d and remembers it for next time.
+func (p *player) setDevice(d mpv.Device) {
+	p.mu.Lock()
+	p.device = d.Name
+	m := p.mpvCtl()
+	p.mu.Unlock()
+
+	if m != nil {
+		if err := m.SetDevice(d.Name); err != nil {
+			p.notify(fmt.Sprintf("[red]Device error:[-] %v", err))
+			return
+		}
+	}
+	if err := store.Save(deviceFile, d.Name); err != nil {
+		p.notify(fmt.Sprintf("[red]Device error:[-] %v", err))
+		return
+	}
+	p.notify(fmt.Sprintf("[green]🔈 Output:[-] %s", d.Description))
+}
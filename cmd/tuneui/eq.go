@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"audictl/internal/config"
+	"audictl/internal/eq"
+	"audictl/internal/store"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// eqFile remembers the equalizer as last set in the TUI across runs.
+const eqFile = "eq.json"
+
+// eqState is the active equalizer.
+type eqState struct {
+	Preset string    `json:"preset"` // preset the bands came from, "custom" once edited
+	Bands  []eq.Band `json:"bands"`
+}
+
+// loadEQ returns the equalizer last set in the TUI, falling back to the
+// preset named in the config.
+func loadEQ(cfg *config.Config) eqState {
+	var st eqState
+	if err := store.Load(eqFile, &st); err == nil && len(st.Bands) > 0 {
+		return st
+	}
+	name := cfg.EQ
+	if name == "" {
+		name = "flat"
+	}
+	presets := eq.Presets(cfg.EQPresets)
+	pr, ok := eq.Find(presets, name)
+	if !ok {
+		pr = presets[0]
+	}
+	return eqState{Preset: pr.Name, Bands: append([]eq.Band(nil), pr.Bands...)}
+}
+
+// applyEQ pushes the current equalizer to mpv.
+func (p *player) applyEQ() {
+	p.mu.Lock()
+	bands := append([]eq.Band(nil), p.eq.Bands...)
+	m := p.mpvCtl()
+	p.mu.Unlock()
+	if m == nil {
+		// ensurePlayer applies it once mpv is up
+		return
+	}
+	filter := eq.Filter(bands)
+	if p.config().BitPerfect {
+		filter = ""
+	}
+	_ = m.SetEQ(filter)
+}
+
+// showEQ opens the equalizer panel: ↑/↓ pick a band, ←/→ change its gain
+// by 1 dB, 0 resets the band, p cycles through the presets. Changes are
+// applied live and remembered when the panel is closed.
+// Must be called from the UI goroutine.
+func (p *player) showEQ() {
+	const name = "eq"
+	if msg := p.mpvMissing("The equalizer"); msg != "" {
+		p.showNotice(msg)
+		return
+	}
+	if p.config().BitPerfect {
+		p.showNotice("[yellow]The equalizer is disabled in bit-perfect mode[-]")
+		return
+	}
+	presets := eq.Presets(p.config().EQPresets)
+	selected := 0
+
+	view := tview.NewTextView()
+	view.SetDynamicColors(true)
+	view.SetBorder(true).SetTitle(" Equalizer [↑↓=Band, ←→=Gain, 0=Reset band, p=Preset, Esc=Close] ")
+
+	render := func() {
+		p.mu.Lock()
+		st := eqState{Preset: p.eq.Preset, Bands: append([]eq.Band(nil), p.eq.Bands...)}
+		p.mu.Unlock()
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "\n  Preset: [yellow]%s[-]\n\n", tview.Escape(st.Preset))
+		for i, band := range st.Bands {
+			prefix := "  "
+			if i == selected {
+				prefix = "[aqua]►[-] "
+			}
+			fmt.Fprintf(&b, "%s%5s Hz  %s  %+5.1f dB\n", prefix, eq.Label(band.Freq), eqSlider(band.Gain), band.Gain)
+		}
+		view.SetText(b.String())
+	}
+
+	// update changes the bands and applies them without blocking the UI
+	update := func(fn func(st *eqState)) {
+		p.mu.Lock()
+		fn(&p.eq)
+		p.mu.Unlock()
+		render()
+		go p.applyEQ()
+	}
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		p.mu.Lock()
+		n := len(p.eq.Bands)
+		p.mu.Unlock()
+
+		switch event.Key() {
+		case tcell.KeyEsc:
+			p.hideModal(name)
+			p.mu.Lock()
+			st := eqState{Preset: p.eq.Preset, Bands: append([]eq.Band(nil), p.eq.Bands...)}
+			p.mu.Unlock()
+			go func() {
+				if err := store.Save(eqFile, st); err != nil {
+					p.notify(fmt.Sprintf("[red]EQ error:[-] %v", err))
+				}
+			}()
+			return nil
+		case tcell.KeyUp:
+			if selected > 0 {
+				selected--
+			}
+			render()
+			return nil
+		case tcell.KeyDown:
+			if selected < n-1 {
+				selected++
+			}
+			render()
+			return nil
+		case tcell.KeyLeft, tcell.KeyRight:
+			step := 1.0
+			if event.Key() == tcell.KeyLeft {
+				step = -1
+			}
+			update(func(st *eqState) {
+				if selected < len(st.Bands) {
+					st.Bands[selected].Gain = eq.Clamp(math.Round(st.Bands[selected].Gain + step))
+					st.Preset = "custom"
+				}
+			})
+			return nil
+		}
+		switch event.Rune() {
+		case '0':
+			update(func(st *eqState) {
+				if selected < len(st.Bands) {
+					st.Bands[selected].Gain = 0
+					st.Preset = "custom"
+				}
+			})
+			return nil
+		case 'p', 'P':
+			update(func(st *eqState) {
+				next := presets[0]
+				for i, pr := range presets {
+					if strings.EqualFold(pr.Name, st.Preset) {
+						next = presets[(i+1)%len(presets)]
+						break
+					}
+				}
+				st.Preset = next.Name
+				st.Bands = append([]eq.Band(nil), next.Bands...)
+			})
+			if selected >= n {
+				selected = 0
+			}
+			render()
+			return nil
+		}
+		return nil
+	})
+
+	render()
+	p.showModal(name, view, 72, len(p.eq.Bands)+6)
+}
+
+// eqSlider draws a horizontal slider for gain with 0 dB in the middle.
+func eqSlider(gain float64) string {
+	const half = int(eq.MaxGain)
+	pos := int(math.Round(eq.Clamp(gain))) + half
+	var b strings.Builder
+	for i := 0; i <= 2*half; i++ {
+		switch {
+		case i == pos:
+			b.WriteString("[aqua]●[-]")
+		case i == half:
+			b.WriteString("┼")
+		case (i > half && i < pos) || (i < half && i > pos):
+			b.WriteString("[aqua]━[-]")
+		default:
+			b.WriteString("─")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"audictl/internal/artwork"
+	"audictl/internal/backend"
+	"audictl/internal/config"
+	"audictl/internal/mpv"
+	"audictl/internal/netproxy"
+	"audictl/internal/provider"
+	"audictl/internal/ratelimit"
+	sprov "audictl/providers/spotify"
+)
+
+// appendedTrack is the queued track handed to mpv ahead of time so it
+// follows the current one without a gap.
+type appendedTrack struct {
+	track    provider.Track
+	queueIdx int
+	chapters []provider.Chapter
+}
+
+// startBackend starts a player backend; tests swap in a fake.
+var startBackend = backend.Start
+
+// ensurePlayer starts the shared player backend if it isn't running yet.
+// One player plays every track so the audio device stays open between them.
+func (p *player) ensurePlayer() error {
+	p.backendMu.Lock()
+	defer p.backendMu.Unlock()
+
+	p.mu.Lock()
+	running := p.backend != nil
+	video := p.video
+	device := p.device
+	ao := p.ao
+	p.mu.Unlock()
+	if running {
+		return nil
+	}
+
+	cfg := p.config()
+	if cfg.BitPerfect && !mpv.IsHardwareDevice(device) {
+		// Anything but a raw hw: device goes through ALSA's software mixer
+		if devices, err := mpv.ListDevices(); err == nil {
+			if d, ok := mpv.HardwareDevice(devices); ok {
+				device = d.Name
+			}
+		}
+	}
+
+	b, err := startBackend(cfg.Backend, backend.Options{
+		Device:       device,
+		Resample:     os.Getenv("AUDICTL_RESAMPLE") == "1",
+		Video:        video,
+		AudioOutput:  ao,
+		BitPerfect:   cfg.BitPerfect,
+		YtDlpPath:    expandHome(cfg.YtDlpPath),
+		YtDlpOptions: ytDlpOptions(cfg),
+		HTTPProxy:    httpProxy(cfg),
+		PCMFile:      expandHome(cfg.Snapcast.Pipe),
+	})
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.backend = b
+	muted := p.muted
+	p.mu.Unlock()
+	go p.watchPlayer(b)
+	if muted {
+		// A new player after a crash starts unmuted
+		_ = b.SetMute(true)
+	}
+	p.applyNormalize()
+	p.applyEQ()
+	p.applySpeed()
+	return nil
+}
+
+// ytDlpOptions returns the yt-dlp options the config asks for: cookies
+// and a proxy. They go to the provider's yt-dlp runs and mpv's ytdl_hook.
+func ytDlpOptions(cfg *config.Config) map[string]string {
+	opts := map[string]string{}
+	if cfg.Cookies != "" {
+		opts["cookies"] = expandHome(cfg.Cookies)
+	}
+	if cfg.CookiesFromBrowser != "" {
+		opts["cookies-from-browser"] = cfg.CookiesFromBrowser
+	}
+	if proxy := cfg.ProxyFor("youtube"); proxy != "" {
+		opts["proxy"] = proxy
+	}
+	return opts
+}
+
+// httpProxy returns the configured proxy if the player can use it for its
+// own requests, which only works for HTTP proxies.
+func httpProxy(cfg *config.Config) string {
+	if proxy := cfg.ProxyFor("player"); strings.HasPrefix(proxy, "http://") {
+		return proxy
+	}
+	return ""
+}
+
+// setProxies checks the proxies configured and points the HTTP lookups
+// made in process at them.
+func setProxies(cfg *config.Config) error {
+	for _, name := range []string{"youtube", "player"} {
+		if _, err := netproxy.Parse(cfg.ProxyFor(name)); err != nil {
+			return err
+		}
+	}
+	if err := ratelimit.SetProxy(cfg.Proxy); err != nil {
+		return err
+	}
+	if err := artwork.SetProxy(cfg.ProxyFor("youtube")); err != nil {
+		return err
+	}
+	return sprov.SetProxy(cfg.ProxyFor("spotify"))
+}
+
+// out returns the running player backend, or nil.
+func (p *player) out() backend.Player {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.backend
+}
+
+// mpvCtl returns the running mpv, the only backend with filters, devices,
+// speed, A-B loops and video, or nil. Must be called with p.mu held.
+func (p *player) mpvCtl() *mpv.Instance {
+	if m, ok := p.backend.(backend.MpvPlayer); ok {
+		return m.Mpv()
+	}
+	return nil
+}
+
+// mpvMissing returns a message saying what needs mpv when another backend
+// is configured, or "" when mpv is in use.
+func (p *player) mpvMissing(what string) string {
+	name := p.config().Backend
+	if backend.IsMpv(name) {
+		return ""
+	}
+	return fmt.Sprintf("[yellow]%s needs the mpv backend[-] [gray](using %s)[-]", what, name)
+}
+
+// watchPlayer follows the player's events until it exits.
+func (p *player) watchPlayer(b backend.Player) {
+	defer p.recoverPanic()
+	for ev := range b.Events() {
+		switch ev.Event {
+		case "start-file":
+			p.mu.Lock()
+			p.currentEntry = ev.PlaylistEntryID
+			p.mu.Unlock()
+		case "end-file":
+			// stop/quit/redirect mean we replaced or stopped the file ourselves
+			if ev.Reason == "eof" || ev.Reason == "error" {
+				p.fileEnded(ev)
+			}
+		}
+	}
+
+	p.mu.Lock()
+	if p.backend != b {
+		// Shut down on purpose
+		p.mu.Unlock()
+		return
+	}
+	p.backend = nil
+	track := p.currentTrk
+	var pos float64
+	if track != nil {
+		p.recordPlay(*track, p.playbackStart)
+		pos = p.position()
+	}
+	p.currentTrk = nil
+	p.currentEntry = 0
+	p.appended = nil
+	if p.stopProgress != nil {
+		close(p.stopProgress)
+		p.stopProgress = nil
+	}
+	p.mu.Unlock()
+
+	if track != nil {
+		// Played again in a new player, where it stopped
+		p.playbackFailed(*track, pos, fmt.Errorf("%s exited unexpectedly", b.Name()))
+	}
+}
+
+// fileEnded handles the player reaching the end of a playlist entry. If
+// the next track was appended, mpv is already playing it and only our state
+// moves on; otherwise the next track is loaded as usual. A file that ended
+// with an error, or was cut off, is retried instead, see playbackFailed.
+func (p *player) fileEnded(ev backend.Event) {
+	p.mu.Lock()
+	if p.currentTrk == nil || ev.PlaylistEntryID != p.currentEntry {
+		// A file we already moved away from
+		p.mu.Unlock()
+		return
+	}
+	p.recordPlay(*p.currentTrk, p.playbackStart)
+	p.currentEntry = 0
+	if track, pos := *p.currentTrk, p.position(); ev.Reason == "error" || cutShort(track, pos) {
+		p.currentTrk = nil
+		p.appended = nil
+		p.mu.Unlock()
+		var err error
+		if ev.FileError != "" {
+			err = errors.New(ev.FileError)
+		}
+		p.playbackFailed(track, pos, err)
+		return
+	}
+	p.retry = nil
+	p.failStreak = 0
+	next := p.appended
+	p.appended = nil
+	if next != nil && next.queueIdx < len(p.queue) && p.queue[next.queueIdx].ID == next.track.ID {
+		p.queueIdx = next.queueIdx
+		p.mu.Unlock()
+		p.startedTrack(next.track, p.fileOptions(next.track, 0).StartPos, next.chapters)
+		return
+	}
+	p.currentTrk = nil
+	p.mu.Unlock()
+
+	p.updateNowPlaying("[gray]Track finished[-]")
+	p.writeNowPlaying(nil)
+	go func() {
+		defer p.recoverPanic()
+		time.Sleep(500 * time.Millisecond)
+		p.advance(true)
+	}()
+}
+
+// appendNext hands the prefetched next track to mpv so it plays gaplessly
+// after the current one.
+func (p *player) appendNext() {
+	track, ok := p.upNext()
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	pf := p.prefetch
+	b := p.backend
+	if b == nil || p.currentTrk == nil || p.appended != nil || track.ID == p.currentTrk.ID ||
+		pf == nil || pf.trackID != track.ID || !pf.fresh() {
+		// Repeating a single-track queue is left to next(), which reloads it
+		p.mu.Unlock()
+		return
+	}
+	idx := p.nextIndex(true)
+	if idx < 0 || p.queue[idx].ID != track.ID {
+		// The queue changed meanwhile
+		p.mu.Unlock()
+		return
+	}
+	p.appended = &appendedTrack{track: track, queueIdx: idx, chapters: pf.stream.Chapters}
+	video := p.video
+	p.mu.Unlock()
+
+	opts := p.fileOptions(track, 0)
+	if !video {
+		opts.YtdlFormat = pf.stream.Meta["ytdl-format"]
+	}
+	if err := b.Append(playURL(track, pf.stream, video, b.OpensPages()), opts); err != nil {
+		// Backends without gapless playback load it when this track ends
+		p.mu.Lock()
+		p.appended = nil
+		p.mu.Unlock()
+	}
+}
+
+// syncAppended drops the track appended to mpv when the queue no longer has
+// it up next, then lines up whatever is next now.
+func (p *player) syncAppended() {
+	p.mu.Lock()
+	stale := false
+	if a := p.appended; a != nil {
+		idx := p.nextIndex(true)
+		stale = idx != a.queueIdx || idx < 0 || p.queue[idx].ID != a.track.ID
+	}
+	if stale {
+		p.appended = nil
+	}
+	lineUp := p.currentTrk != nil && p.appended == nil
+	b := p.backend
+	p.mu.Unlock()
+
+	if stale && b != nil {
+		_ = b.ClearAppended()
+	}
+	if lineUp {
+		go p.prefetchNext()
+	}
+}
+
+// shutdownPlayer stops the shared player backend.
+func (p *player) shutdownPlayer() {
+	p.mu.Lock()
+	b := p.backend
+	p.backend = nil
+	p.mu.Unlock()
+	if b != nil {
+		_ = b.Close()
+	}
+}
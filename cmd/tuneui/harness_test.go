@@ -0,0 +1,256 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/backend"
+	"audictl/internal/backend/backendtest"
+	"audictl/internal/config"
+	"audictl/internal/provider"
+	"audictl/internal/provider/providertest"
+	"audictl/internal/theme"
+)
+
+// waitTimeout is how long harness.waitFor waits before failing the test.
+const waitTimeout = 5 * time.Second
+
+// harness runs a player with its whole UI on a simulated screen, playing
+// the tracks of a mock provider on fake backends. Keys typed with press go
+// through the same handlers as a user's.
+type harness struct {
+	t    *testing.T
+	p    *player
+	mock *providertest.Provider
+
+	mu    sync.Mutex
+	fakes []*backendtest.Player // started so far, a new one after each crash
+	proxy net.Listener          // stands in for the network, see goOffline
+}
+
+// newHarness starts a player like main does, with the config and data
+// directories in temporary ones.
+func newHarness(t *testing.T) *harness {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	// Retries probe the player's proxy to tell whether the network is down
+	proxy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock := providertest.New("mock")
+	app := tview.NewApplication()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.SetSize(140, 40)
+	app.SetScreen(screen)
+	p := &player{
+		queue:        []provider.Track{},
+		queueIdx:     -1,
+		yt:           mock,
+		app:          app,
+		actionChan:   make(chan action, 10),
+		done:         make(chan struct{}),
+		statusKick:   make(chan struct{}, 1),
+		speed:        1,
+		loopA:        -1,
+		loopB:        -1,
+		searchRecall: -1,
+	}
+	p.providers = provider.NewRegistry(mock)
+	p.cfg = config.Default()
+	p.cfg.Proxies = map[string]string{"player": "http://" + proxy.Addr().String()}
+	p.layout = loadLayout(p.cfg)
+	p.theme, _ = theme.Resolve("dark", nil)
+	h := &harness{t: t, p: p, mock: mock, proxy: proxy}
+
+	prevStart := startBackend
+	startBackend = func(string, backend.Options) (backend.Player, error) {
+		fake := backendtest.New(0)
+		h.mu.Lock()
+		h.fakes = append(h.fakes, fake)
+		h.mu.Unlock()
+		return fake, nil
+	}
+
+	p.buildUI()
+	app.SetFocus(p.searchView)
+	go p.processActions()
+	go p.followStatus()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := app.Run(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	t.Cleanup(func() {
+		p.cleanup()
+		app.Stop()
+		<-done
+		startBackend = prevStart
+		h.proxy.Close()
+	})
+	return h
+}
+
+// tracks makes tracks of the mock provider with the given IDs, 200s long
+// and titled "Track <id>".
+func (h *harness) tracks(ids ...string) []provider.Track {
+	var tracks []provider.Track
+	for _, id := range ids {
+		tracks = append(tracks, h.mock.Track(id, "Track "+id, 200))
+	}
+	return tracks
+}
+
+// enqueue replaces the queue with tracks.
+func (h *harness) enqueue(tracks ...provider.Track) {
+	h.p.mu.Lock()
+	h.p.queue = tracks
+	h.p.queueIdx = 0
+	h.p.mu.Unlock()
+	h.p.updateQueueView()
+}
+
+// playQueue focuses the queue and starts its first track with Enter, then
+// waits for it to play.
+func (h *harness) playQueue() {
+	h.focus(h.p.queueView)
+	h.press(tcell.KeyEnter, 0)
+	h.waitPlaying(h.queued(0))
+}
+
+// queued returns the ID of the queued track at idx.
+func (h *harness) queued(idx int) string {
+	h.p.mu.Lock()
+	defer h.p.mu.Unlock()
+	return h.p.queue[idx].ID
+}
+
+// fake returns the player backend started last, failing the test when
+// none was.
+func (h *harness) fake() *backendtest.Player {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.fakes) == 0 {
+		h.t.Fatal("no player was started")
+	}
+	return h.fakes[len(h.fakes)-1]
+}
+
+// started returns how many player backends were started.
+func (h *harness) started() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.fakes)
+}
+
+// goOffline makes the network look down to the retries.
+func (h *harness) goOffline() {
+	h.proxy.Close()
+}
+
+// onUI runs f on the UI goroutine and waits for it.
+func (h *harness) onUI(f func()) {
+	done := make(chan struct{})
+	h.p.app.QueueUpdate(func() {
+		defer close(done)
+		f()
+	})
+	select {
+	case <-done:
+	case <-time.After(waitTimeout):
+		h.t.Fatal("timed out waiting for the UI")
+	}
+}
+
+// focus moves the focus to the panel prim.
+func (h *harness) focus(prim tview.Primitive) {
+	h.onUI(func() { h.p.app.SetFocus(prim) })
+}
+
+// press types key, or the rune r for tcell.KeyRune, and waits until the
+// UI handled it. The key takes the path tview gives it, on the UI
+// goroutine, so it is handled in order with onUI's functions.
+func (h *harness) press(key tcell.Key, r rune) {
+	h.onUI(func() {
+		event := tcell.NewEventKey(key, r, tcell.ModNone)
+		if capture := h.p.app.GetInputCapture(); capture != nil {
+			if event = capture(event); event == nil {
+				return
+			}
+		}
+		h.p.pages.InputHandler()(event, func(prim tview.Primitive) {
+			h.p.app.SetFocus(prim)
+		})
+	})
+}
+
+// typeKeys types each of keys as a rune.
+func (h *harness) typeKeys(keys string) {
+	for _, r := range keys {
+		h.press(tcell.KeyRune, r)
+	}
+}
+
+// playing returns the ID of the track playing, or "".
+func (h *harness) playing() string {
+	h.p.mu.Lock()
+	defer h.p.mu.Unlock()
+	if h.p.currentTrk == nil {
+		return ""
+	}
+	return h.p.currentTrk.ID
+}
+
+// waitPlaying waits until the track with id plays, in the player state and
+// on the fake backend.
+func (h *harness) waitPlaying(id string) {
+	h.t.Helper()
+	h.waitFor("playing "+id, func() bool {
+		return h.playing() == id && h.started() > 0 && h.fake().Playing() == "mock://"+id
+	})
+}
+
+// nowPlaying returns the text of the Now Playing panel.
+func (h *harness) nowPlaying() string {
+	var text string
+	h.onUI(func() { text = h.p.nowView.GetText(true) })
+	return text
+}
+
+// notice returns the notice shown in the status bar.
+func (h *harness) notice() string {
+	var text string
+	h.onUI(func() { text = h.p.noticeView.GetText(true) })
+	return text
+}
+
+// waitText waits until text() contains want.
+func (h *harness) waitText(what string, text func() string, want string) {
+	h.t.Helper()
+	h.waitFor(what+" showing "+want, func() bool {
+		return strings.Contains(text(), want)
+	})
+}
+
+// waitFor polls cond until it holds, failing the test after waitTimeout.
+func (h *harness) waitFor(what string, cond func() bool) {
+	h.t.Helper()
+	deadline := time.Now().Add(waitTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"audictl/internal/history"
+	"audictl/internal/provider"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// recordPlay logs a finished, skipped or stopped track to the history,
+// and remembers where a long one was left. Must be called with p.mu held.
+func (p *player) recordPlay(track provider.Track, started time.Time) {
+	p.rememberPosition(track, p.position())
+	listened := time.Since(started)
+	if listened < 2*time.Second {
+		// Skipped straight away; not worth remembering
+		return
+	}
+	// Picking the same track up again right after it was logged continues
+	// that listen rather than starting a new one
+	window := p.cfg.ReplayWindowDuration()
+	if track.ID == p.lastLogged && started.Sub(p.lastLoggedAt) < window {
+		p.lastLoggedAt = time.Now()
+		return
+	}
+	p.lastLogged = track.ID
+	p.lastLoggedAt = time.Now()
+	e := history.Entry{
+		Track:      track,
+		PlayedAt:   started,
+		Completion: history.Completion(listened, track.Duration),
+		Listened:   int(listened.Seconds()),
+	}
+	p.writes.Add(1)
+	go func() {
+		defer p.writes.Done()
+		if err := history.Record(e); err != nil {
+			p.notify(fmt.Sprintf("[red]History error:[-] %v", err))
+		}
+	}()
+}
+
+// showHistory opens the playback history browser, newest first. Enter plays
+// the selected track again and 'a' adds it to the queue.
+// Must be called from the UI goroutine.
+func (p *player) showHistory() {
+	const name = "history"
+	entries, err := history.Recent(500)
+	if err != nil {
+		p.showNotice(fmt.Sprintf("[red]History error:[-] %v", err))
+		return
+	}
+
+	view := tview.NewList().ShowSecondaryText(false)
+	view.SetBorder(true).SetTitle(" History [Enter=Play, a=Queue, Esc=Close] ")
+	view.SetHighlightFullLine(true)
+	view.SetSelectedBackgroundColor(p.theme.Selection)
+	view.SetSelectedTextColor(p.theme.SelectionText)
+	for _, e := range entries {
+		when := e.PlayedAt.Local().Format("Jan 02 15:04")
+		done := ""
+		if e.Track.Duration > 0 {
+			done = fmt.Sprintf(" [gray](%.0f%%)[-]", e.Completion)
+		}
+		view.AddItem(fmt.Sprintf("[gray]%s[-]  %s - %s%s", when, e.Track.Artist, e.Track.Title, done), "", 0, nil)
+	}
+	if len(entries) == 0 {
+		view.AddItem("[gray]Nothing played yet[-]", "", 0, nil)
+	}
+
+	view.SetSelectedFunc(func(idx int, _ string, _ string, _ rune) {
+		if idx < 0 || idx >= len(entries) {
+			return
+		}
+		p.hideModal(name)
+		go p.playTrack(entries[idx].Track)
+	})
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			p.hideModal(name)
+			return nil
+		}
+		if event.Rune() == 'a' || event.Rune() == 'A' {
+			idx := view.GetCurrentItem()
+			if idx >= 0 && idx < len(entries) {
+				track := entries[idx].Track
+				go p.enqueue(track)
+			}
+			return nil
+		}
+		return event
+	})
+
+	p.showModal(name, view, 90, 24)
+}
+
+// recentLimit is how many tracks the quick recent popup offers.
+const recentLimit = 20
+
+// showRecent opens a small popup with the last distinct tracks played. The
+// digit next to a track (or Enter/'a' on the selection) adds it to the queue
+// without closing the popup, so several can be re-queued in a row.
+// Must be called from the UI goroutine.
+func (p *player) showRecent() {
+	const name = "recent"
+	entries, err := history.Recent(500)
+	if err != nil {
+		p.showNotice(fmt.Sprintf("[red]History error:[-] %v", err))
+		return
+	}
+	var tracks []provider.Track
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if seen[e.Track.ID] {
+			continue
+		}
+		seen[e.Track.ID] = true
+		tracks = append(tracks, e.Track)
+		if len(tracks) == recentLimit {
+			break
+		}
+	}
+
+	view := tview.NewList().ShowSecondaryText(false)
+	view.SetBorder(true).SetTitle(" Recent [1-0/Enter=Queue, Esc=Close] ")
+	view.SetHighlightFullLine(true)
+	view.SetSelectedBackgroundColor(p.theme.Selection)
+	view.SetSelectedTextColor(p.theme.SelectionText)
+	for i, t := range tracks {
+		key := ' '
+		if i < 10 {
+			key = rune('0' + (i+1)%10)
+		}
+		view.AddItem(fmt.Sprintf("%s - %s", tview.Escape(t.Artist), tview.Escape(t.Title)), "", key, nil)
+	}
+	if len(tracks) == 0 {
+		view.AddItem("[gray]Nothing played yet[-]", "", 0, nil)
+	}
+
+	add := func(idx int) {
+		if idx >= 0 && idx < len(tracks) {
+			go p.enqueue(tracks[idx])
+		}
+	}
+	view.SetSelectedFunc(func(idx int, _ string, _ string, _ rune) {
+		add(idx)
+	})
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Rune() == 'h' {
+			p.hideModal(name)
+			return nil
+		}
+		if event.Rune() == 'a' || event.Rune() == 'A' {
+			add(view.GetCurrentItem())
+			return nil
+		}
+		// Digits are the list's own shortcuts and trigger SetSelectedFunc
+		return event
+	})
+
+	p.showModal(name, view, 70, max(len(tracks), 1)+2)
+}
+
+// enqueue appends a single track to the queue.
+func (p *player) enqueue(track provider.Track) {
+	p.mu.Lock()
+	p.queue = append(p.queue, track)
+	p.mu.Unlock()
+	p.updateQueueView()
+	p.notify(fmt.Sprintf("[green]+ Added:[-] %s", track.Title))
+}
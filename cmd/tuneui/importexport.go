@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"audictl/internal/history"
+	"audictl/internal/listens"
+	"audictl/internal/playlist"
+	"audictl/internal/provider"
+	"audictl/providers/direct"
+	sprov "audictl/providers/spotify"
+	yprov "audictl/providers/youtube"
+)
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// playlistFilePath returns the expanded path if link names an existing
+// playlist file, or "" otherwise.
+func playlistFilePath(link string) string {
+	path := expandHome(strings.Trim(link, `"'`))
+	if !playlist.IsPlaylistFile(path) {
+		return ""
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return ""
+	}
+	return path
+}
+
+// entryTracks turns a playlist file entry into queue tracks, resolving links
+// through the matching provider.
+func entryTracks(e playlist.Entry) ([]provider.Track, error) {
+	loc := e.Location
+	switch {
+	case strings.Contains(loc, "youtube.com") || strings.Contains(loc, "youtu.be"):
+		if id := yprov.VideoID(loc); id != "" && e.Title != "" {
+			// Metadata is already in the file; skip the yt-dlp round trip
+			return []provider.Track{{
+				ID:       "youtube:" + id,
+				Provider: "youtube",
+				Title:    e.Title,
+				Artist:   e.Artist,
+				Duration: e.Duration,
+				Links:    map[string]string{"youtube": "https://www.youtube.com/watch?v=" + id},
+			}}, nil
+		}
+		return yprov.New().FetchTracksFromURL(loc, 0)
+	case strings.Contains(loc, "spotify.com"):
+		return sprov.New().FetchTracksFromURL(loc)
+	case direct.IsPlayable(loc):
+		return []provider.Track{direct.NewTrack(loc, e.Title, e.Artist, e.Duration)}, nil
+	case e.Title != "":
+		// The file is gone but we know what it was: look it up on YouTube at play time
+		query := e.Title
+		if e.Artist != "" {
+			query = e.Artist + " - " + e.Title
+		}
+		return []provider.Track{{
+			ID:       "search:" + query,
+			Provider: "youtube",
+			Title:    e.Title,
+			Artist:   e.Artist,
+			Duration: e.Duration,
+		}}, nil
+	}
+	return nil, fmt.Errorf("cannot resolve %s", loc)
+}
+
+// importPlaylistFile appends every resolvable entry of an M3U/PLS/XSPF file
+// to the queue.
+func (p *player) importPlaylistFile(path string) {
+	entries, err := playlist.ReadFile(path)
+	if err != nil {
+		p.notify(fmt.Sprintf("[red]Import error:[-] %v", err))
+		return
+	}
+
+	var tracks []provider.Track
+	failed := 0
+	for i, e := range entries {
+		p.notify(fmt.Sprintf("[yellow]Importing %s[-] (%d/%d)", filepath.Base(path), i+1, len(entries)))
+		ts, err := entryTracks(e)
+		if err != nil || len(ts) == 0 {
+			failed++
+			continue
+		}
+		tracks = append(tracks, ts...)
+	}
+
+	p.mu.Lock()
+	p.queue = append(p.queue, tracks...)
+	p.mu.Unlock()
+	p.updateQueueView()
+
+	msg := fmt.Sprintf("[green]+ Imported:[-] %d tracks from %s", len(tracks), filepath.Base(path))
+	if failed > 0 {
+		msg += fmt.Sprintf("\n[yellow]%d entries could not be resolved[-]", failed)
+	}
+	p.notify(msg)
+}
+
+// promptExport asks for a file name and writes tracks to it as M3U.
+// Must be called from the UI goroutine.
+func (p *player) promptExport(what string, tracks []provider.Track, defaultName string) {
+	if len(tracks) == 0 {
+		p.showNotice("[yellow]Nothing to export[-]")
+		return
+	}
+	p.prompt("Export "+what+" as M3U", " File: ", "~/"+defaultName+".m3u8", func(path string) {
+		path = expandHome(strings.TrimSpace(path))
+		if path == "" {
+			return
+		}
+		go func() {
+			if err := playlist.ExportM3U(path, tracks); err != nil {
+				p.notify(fmt.Sprintf("[red]Export error:[-] %v", err))
+				return
+			}
+			p.notify(fmt.Sprintf("[green]✓ Exported %d tracks to[-] %s", len(tracks), path))
+		}()
+	})
+}
+
+// promptExportQueue exports the current queue. Must be called from the UI
+// goroutine.
+func (p *player) promptExportQueue() {
+	p.mu.Lock()
+	queueCopy := make([]provider.Track, len(p.queue))
+	copy(queueCopy, p.queue)
+	p.mu.Unlock()
+	p.promptExport("queue", queueCopy, "queue")
+}
+
+// spotifyLikedPlaylist is the playlist the liked songs of a Spotify export
+// are saved to.
+const spotifyLikedPlaylist = "Spotify Liked Songs"
+
+// runImport adds the listens of a Spotify data export folder or a Last.fm
+// CSV export to the history, and the Spotify liked songs to a playlist,
+// then reports what it did.
+func runImport(spotifyDir, lastfmCSV string) error {
+	var entries []history.Entry
+	if spotifyDir != "" {
+		exp, err := listens.Spotify(expandHome(spotifyDir))
+		if err != nil {
+			return err
+		}
+		entries = append(entries, exp.Listens...)
+		if len(exp.Liked) > 0 {
+			if _, err := playlist.Create(spotifyLikedPlaylist, exp.Liked); err != nil {
+				return err
+			}
+			fmt.Printf("Saved %d liked songs to the playlist %q\n", len(exp.Liked), spotifyLikedPlaylist)
+		}
+	}
+	if lastfmCSV != "" {
+		scrobbles, err := listens.Lastfm(expandHome(lastfmCSV))
+		if err != nil {
+			return err
+		}
+		entries = append(entries, scrobbles...)
+	}
+	added, err := history.Import(entries)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %d of %d listens to the history\n", added, len(entries))
+	return nil
+}
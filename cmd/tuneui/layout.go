@@ -0,0 +1,124 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/config"
+	"audictl/internal/store"
+)
+
+// layoutFile remembers the panel layout as last arranged in the TUI.
+const layoutFile = "layout.json"
+
+const (
+	// narrowWidth is the terminal width below which the panels are
+	// stacked in one column.
+	narrowWidth = 100
+	// splitStep is how far the split moves per key press, in percent.
+	splitStep = 5
+)
+
+// loadLayout returns the layout last arranged in the TUI, falling back to
+// the one in the config.
+func loadLayout(cfg *config.Config) config.Layout {
+	var l config.Layout
+	if err := store.Load(layoutFile, &l); err == nil && l != (config.Layout{}) {
+		return l
+	}
+	return cfg.Layout
+}
+
+// applyLayout fills mainFlex and rightPanel for the current layout and
+// terminal width. Called from the UI goroutine.
+func (p *player) applyLayout() {
+	l := p.layout
+	p.rightPanel.Clear()
+	p.rightPanel.AddItem(p.nowPanel, 0, 2, false)
+	if p.vizMode != vizOff {
+		p.rightPanel.AddItem(p.vizView, vizHeight, 0, false)
+	}
+	p.rightPanel.AddItem(p.queueView, 0, 3, false)
+
+	p.mainFlex.Clear()
+	switch {
+	case l.View == config.ViewResults:
+		p.mainFlex.SetDirection(tview.FlexColumn)
+		p.mainFlex.AddItem(p.leftPanel, 0, 1, true)
+	case l.View == config.ViewQueue:
+		p.mainFlex.SetDirection(tview.FlexColumn)
+		p.mainFlex.AddItem(p.rightPanel, 0, 1, false)
+	case p.narrow:
+		p.mainFlex.SetDirection(tview.FlexRow)
+		p.mainFlex.AddItem(p.leftPanel, 0, 1, true)
+		p.mainFlex.AddItem(p.rightPanel, 0, 1, false)
+	default:
+		split := l.SplitPercent()
+		p.mainFlex.SetDirection(tview.FlexColumn)
+		p.mainFlex.AddItem(p.leftPanel, 0, split, true)
+		p.mainFlex.AddItem(p.rightPanel, 0, 100-split, false)
+	}
+}
+
+// fitLayout stacks the panels when the terminal is narrow, and puts them
+// side by side again once it is wide enough. It runs before every draw,
+// with the application locked.
+func (p *player) fitLayout(screen tcell.Screen) bool {
+	width, _ := screen.Size()
+	if narrow := width < narrowWidth; narrow != p.narrow {
+		p.narrow = narrow
+		p.applyLayout()
+	}
+	return false
+}
+
+// shown reports whether prim is in a visible panel.
+func (p *player) shown(prim tview.Primitive) bool {
+	switch prim {
+	case p.searchView, p.linkView, p.resultsView, p.lyricsView:
+		return p.layout.View != config.ViewQueue
+	case p.queueView:
+		return p.layout.View != config.ViewResults
+	}
+	return true
+}
+
+// changeLayout rearranges the panels with change and remembers the new
+// layout. Called from the UI goroutine.
+func (p *player) changeLayout(change func(l *config.Layout)) {
+	change(&p.layout)
+	p.applyLayout()
+	if !p.shown(p.app.GetFocus()) && !p.modalOpen() {
+		// Don't leave the focus on a panel that is gone
+		p.nextFocus()
+	}
+	l := p.layout
+	go func() {
+		if err := store.Save(layoutFile, l); err != nil {
+			p.notify("[red]Layout error:[-] " + err.Error())
+		}
+	}()
+}
+
+// cycleView switches between the split view, the results only and the
+// queue only.
+func (p *player) cycleView() {
+	p.changeLayout(func(l *config.Layout) {
+		switch l.View {
+		case config.ViewResults:
+			l.View = config.ViewQueue
+		case config.ViewQueue:
+			l.View = config.ViewSplit
+		default:
+			l.View = config.ViewResults
+		}
+	})
+}
+
+// moveSplit widens the results side by delta percent (narrows it when
+// negative).
+func (p *player) moveSplit(delta int) {
+	p.changeLayout(func(l *config.Layout) {
+		l.Split = max(30, min(80, l.SplitPercent()+delta))
+	})
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/format"
+	"audictl/internal/provider"
+	"audictl/providers/local"
+)
+
+// libEntry is what a row of the library browser stands for: an artist or
+// an album with all its tracks, or a single track.
+type libEntry struct {
+	what   string // "artist", "album" or "track"
+	name   string
+	tracks []provider.Track
+}
+
+// showLibrary opens the browser of the music folder, by artist, album and
+// track. Typing after / filters it, Enter replaces the queue with the
+// selected artist or album (or plays the selected track), 'a' appends it,
+// Space or →/← expand and collapse, R reads the folder again.
+// Must be called from the UI goroutine.
+func (p *player) showLibrary() {
+	const name = "library"
+	if p.library == nil {
+		p.showNotice("[yellow]Set music_dir in the config to browse your music[-]")
+		return
+	}
+
+	filter := tview.NewInputField()
+	filter.SetLabel(" / ")
+	filter.SetFieldWidth(0)
+	filter.SetFieldBackgroundColor(p.theme.Field)
+	root := tview.NewTreeNode("")
+	tree := tview.NewTreeView().SetRoot(root).SetTopLevel(1)
+	tree.SetGraphicsColor(tcell.ColorGray)
+
+	var tracks []provider.Track
+	note := func(text string) {
+		root.ClearChildren()
+		root.AddChild(tview.NewTreeNode(text).SetSelectable(false))
+	}
+	render := func() {
+		query := filter.GetText()
+		var shown []provider.Track
+		for _, t := range tracks {
+			if local.Matches(t, query) {
+				shown = append(shown, t)
+			}
+		}
+		if len(shown) == 0 {
+			if len(tracks) == 0 {
+				note("[gray]No music found in " + tview.Escape(p.config().MusicDir) + "[-]")
+			} else {
+				note("[gray]Nothing matches[-]")
+			}
+			return
+		}
+		// Open everything up while filtering, there is less of it
+		expand := strings.TrimSpace(query) != ""
+		root.ClearChildren()
+		for _, a := range local.Group(shown) {
+			artist := orUnknown(a.Name, "Unknown artist")
+			an := tview.NewTreeNode(fmt.Sprintf("%s [gray](%d albums)[-]", tview.Escape(artist), len(a.Albums)))
+			an.SetReference(&libEntry{what: "artist", name: artist, tracks: a.Tracks()}).SetExpanded(expand)
+			for _, al := range a.Albums {
+				album := orUnknown(al.Name, "Unknown album")
+				aln := tview.NewTreeNode(fmt.Sprintf("%s [gray](%d tracks)[-]", tview.Escape(album), len(al.Tracks)))
+				aln.SetReference(&libEntry{what: "album", name: album, tracks: al.Tracks}).SetExpanded(expand)
+				for i, t := range al.Tracks {
+					row := format.Row(p.rowFormat(format.DefaultQueueRow), i+1, escapeTrack(t))
+					aln.AddChild(tview.NewTreeNode(row).SetReference(&libEntry{what: "track", name: t.Title, tracks: []provider.Track{t}}))
+				}
+				an.AddChild(aln)
+			}
+			root.AddChild(an)
+		}
+		tree.SetCurrentNode(root.GetChildren()[0])
+	}
+
+	scan := func() {
+		note("[gray]Reading the music folder…[-]")
+		go func() {
+			found, err := p.library.Tracks()
+			p.app.QueueUpdateDraw(func() {
+				if err != nil {
+					note("[red]" + tview.Escape(err.Error()) + "[-]")
+					return
+				}
+				tracks = found
+				render()
+			})
+		}()
+	}
+	scan()
+	filter.SetChangedFunc(func(string) { render() })
+
+	load := func(replace bool) {
+		node := tree.GetCurrentNode()
+		if node == nil {
+			return
+		}
+		e, ok := node.GetReference().(*libEntry)
+		if !ok {
+			return
+		}
+		switch {
+		case e.what == "track" && replace:
+			p.hideModal(name)
+			go p.playTrack(e.tracks[0])
+		case e.what == "track":
+			go p.enqueue(e.tracks[0])
+		default:
+			p.hideModal(name)
+			go p.loadTracks(e.what, e.name, e.tracks, replace)
+		}
+	}
+
+	filter.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			filter.SetText("")
+			p.app.SetFocus(tree)
+			return nil
+		case tcell.KeyEnter, tcell.KeyTab, tcell.KeyDown:
+			p.app.SetFocus(tree)
+			return nil
+		}
+		return event
+	})
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		node := tree.GetCurrentNode()
+		switch event.Key() {
+		case tcell.KeyEsc:
+			p.hideModal(name)
+			return nil
+		case tcell.KeyEnter:
+			load(true)
+			return nil
+		case tcell.KeyRight, tcell.KeyLeft:
+			if node != nil && len(node.GetChildren()) > 0 {
+				node.SetExpanded(event.Key() == tcell.KeyRight)
+			}
+			return nil
+		}
+		switch event.Rune() {
+		case '/':
+			p.app.SetFocus(filter)
+			return nil
+		case 'a', 'A':
+			load(false)
+			return nil
+		case ' ':
+			if node != nil && len(node.GetChildren()) > 0 {
+				node.SetExpanded(!node.IsExpanded())
+			}
+			return nil
+		case 'R':
+			p.library.Rescan()
+			scan()
+			return nil
+		}
+		return event
+	})
+
+	box := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(filter, 1, 0, false).
+		AddItem(tree, 0, 1, true)
+	box.SetBorder(true).SetTitle(" Library [Enter=Load, a=Append, Space=Expand, /=Filter, R=Rescan, Esc=Close] ")
+	p.showModal(name, box, 90, 28)
+	p.app.SetFocus(tree)
+}
+
+// orUnknown returns name, or unknown when it is empty.
+func orUnknown(name, unknown string) string {
+	if name == "" {
+		return unknown
+	}
+	return name
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"audictl/internal/format"
+)
+
+// currentPosition returns the playback position, from the player when it
+// answers.
+func (p *player) currentPosition() float64 {
+	if b := p.out(); b != nil {
+		if pos, err := b.Position(); err == nil {
+			return pos
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.position()
+}
+
+// cycleLoop marks loop point A at the current position, then B, and clears
+// the loop on the third press.
+func (p *player) cycleLoop() {
+	if msg := p.mpvMissing("A-B looping"); msg != "" {
+		p.notify(msg)
+		return
+	}
+	p.mu.Lock()
+	playing := p.currentTrk != nil
+	p.mu.Unlock()
+	if !playing {
+		p.notify("[yellow]Nothing is playing[-]")
+		return
+	}
+	pos := p.currentPosition()
+
+	p.mu.Lock()
+	var msg string
+	switch {
+	case p.loopA < 0:
+		p.loopA = pos
+		msg = fmt.Sprintf("[green]Loop A:[-] %s [gray](b again to set B)[-]", clock(pos))
+	case p.loopB < 0:
+		if pos <= p.loopA {
+			p.mu.Unlock()
+			p.notify("[yellow]Loop B must come after A[-]")
+			return
+		}
+		p.loopB = pos
+		msg = fmt.Sprintf("[green]Looping[-] %s → %s [gray](b to clear)[-]", clock(p.loopA), clock(pos))
+	default:
+		p.loopA, p.loopB = -1, -1
+		msg = "[yellow]Loop cleared[-]"
+	}
+	a, b := p.loopA, p.loopB
+	m := p.mpvCtl()
+	p.mu.Unlock()
+	if m == nil {
+		return
+	}
+
+	if err := m.SetABLoop(a, b); err != nil {
+		p.notify(fmt.Sprintf("[red]mpv error:[-] %v", err))
+		return
+	}
+	p.notify(msg)
+}
+
+// promptSeek asks for a timestamp and jumps there in the current track.
+// Must be called from the UI goroutine.
+func (p *player) promptSeek() {
+	p.prompt("Seek to", " Time (m:ss): ", "", func(text string) {
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		secs, err := format.ParseDuration(text)
+		if err != nil {
+			p.showNotice(fmt.Sprintf("[red]%v[-]", err))
+			return
+		}
+		go p.seekTo(secs)
+	})
+}
+
+// seekTo jumps to secs in the current track.
+func (p *player) seekTo(secs float64) {
+	b := p.out()
+	if b == nil || b.SeekTo(secs) != nil {
+		p.notify("[yellow]Nothing is playing[-]")
+		return
+	}
+	p.mu.Lock()
+	p.posBase = secs
+	p.posAt = time.Now()
+	p.mu.Unlock()
+	p.previewSeek(secs)
+}
+
+// seekBy seeks delta seconds from the playback position.
+func (p *player) seekBy(delta float64) {
+	b := p.out()
+	if b == nil {
+		return
+	}
+	p.mu.Lock()
+	target := max(p.position()+delta, 0)
+	p.mu.Unlock()
+	if b.Seek(delta) == nil {
+		p.previewSeek(target)
+	}
+}
+
+// clock formats seconds as m:ss.
+func clock(secs float64) string {
+	return fmt.Sprintf("%d:%02d", int(secs)/60, int(secs)%60)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"audictl/internal/provider"
+)
+
+// fallbackKbps is the bitrate assumed for a stream until mpv measures it,
+// about that of YouTube's usual audio.
+const fallbackKbps = 128
+
+// streamQuality is the quality streams are resolved at: the lowest in
+// low-data mode, else the one picked at startup.
+func (p *player) streamQuality() provider.QualityPref {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.lowData {
+		return provider.QualityLow
+	}
+	return p.quality
+}
+
+// toggleLowData turns low-data mode on or off for the session. Turning it
+// on drops the next track resolved ahead, along with its gapless entry in
+// mpv; the playing track keeps its stream.
+func (p *player) toggleLowData() {
+	p.mu.Lock()
+	p.lowData = !p.lowData
+	on := p.lowData
+	appended := p.appended != nil
+	if on {
+		p.prefetch = nil
+		p.appended = nil
+	}
+	b := p.backend
+	track := p.currentTrk
+	p.mu.Unlock()
+
+	if !on {
+		p.notify("[yellow]Low-data mode off[-]")
+		if track != nil {
+			go p.loadArt(*track)
+			go p.prefetchNext()
+		}
+		return
+	}
+	if appended && b != nil {
+		_ = b.ClearAppended()
+	}
+	p.app.QueueUpdateDraw(func() { p.setArt(nil) })
+	p.notify("[green]Low-data mode on[-] [gray](lowest quality, no prefetching or art)[-]")
+}
+
+// countData adds streaming track for d to the session's estimate of the
+// data used, at the bitrate mpv measured. Files on disk don't count. Must
+// be called with p.mu held.
+func (p *player) countData(track provider.Track, d time.Duration) {
+	if onDisk(track) {
+		return
+	}
+	kbps := p.streamKbps
+	if kbps <= 0 {
+		kbps = fallbackKbps
+	}
+	p.dataUsed += float64(kbps) * d.Seconds() / 8 / 1000
+}
+
+// dataUsedText is the stats line of the data streamed this session.
+func (p *player) dataUsedText() string {
+	p.mu.Lock()
+	mb := p.dataUsed
+	lowData := p.lowData
+	p.mu.Unlock()
+	mode := ""
+	if lowData {
+		mode = ", low-data mode on"
+	}
+	return fmt.Sprintf("[yellow]This session:[-] [green]~%.1f MB[-] streamed [gray](estimated from the bitrates%s)[-]\n\n", mb, mode)
+}
+
+// onDisk reports whether track plays from a file rather than the network.
+func onDisk(track provider.Track) bool {
+	switch track.Provider {
+	case "local":
+		return true
+	case "direct":
+		return !strings.Contains(track.Links["url"], "://")
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"audictl/internal/lyrics"
+	"audictl/internal/provider"
+)
+
+// trackLyrics are the lyrics of a track, once fetched.
+type trackLyrics struct {
+	trackID string
+	lyrics  lyrics.Lyrics
+	err     error
+	done    bool
+}
+
+// toggleLyrics swaps the results list for the lyrics of the playing track,
+// or back. Called from the UI goroutine.
+func (p *player) toggleLyrics() {
+	p.mu.Lock()
+	stop := p.stopLyrics
+	showing := stop == nil
+	if showing {
+		stop = make(chan struct{})
+		p.stopLyrics = stop
+	} else {
+		p.stopLyrics = nil
+	}
+	p.mu.Unlock()
+
+	if !showing {
+		close(stop)
+		p.leftPages.SwitchToPage("results")
+		return
+	}
+	p.leftPages.SwitchToPage("lyrics")
+	if p.app.GetFocus() == p.resultsView {
+		// Keep the focus on something visible
+		p.focusIdx = 3
+		p.app.SetFocus(p.queueView)
+	}
+	go p.followLyrics(stop)
+}
+
+// hideLyrics brings the results list back if the lyrics are shown.
+func (p *player) hideLyrics() {
+	p.mu.Lock()
+	shown := p.stopLyrics != nil
+	p.mu.Unlock()
+	if shown {
+		p.toggleLyrics()
+	}
+}
+
+// followLyrics keeps the lyrics panel on the playing track and its current
+// line until stop is closed.
+func (p *player) followLyrics(stop chan struct{}) {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	type state struct {
+		trackID string
+		done    bool
+		line    int
+	}
+	last := state{line: -2}
+	for {
+		now := state{line: -1}
+		p.mu.Lock()
+		track := p.currentTrk
+		l := p.lyrics
+		if track != nil {
+			if l == nil || l.trackID != track.ID {
+				// Fetched once per track, even when the panel is toggled
+				l = &trackLyrics{trackID: track.ID}
+				p.lyrics = l
+				go p.fetchLyrics(*track, l)
+			}
+			now.trackID, now.done = track.ID, l.done
+			if l.done {
+				now.line = l.lyrics.LineAt(p.position())
+			}
+		}
+		p.mu.Unlock()
+
+		if now != last {
+			last = now
+			p.app.QueueUpdateDraw(func() { p.renderLyrics(track, l, now.line) })
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchLyrics fills in l with the lyrics of track.
+func (p *player) fetchLyrics(track provider.Track, l *trackLyrics) {
+	res, err := lyrics.Fetch(track.Artist, track.Title, track.Duration)
+	p.mu.Lock()
+	l.lyrics, l.err, l.done = res, err, true
+	p.mu.Unlock()
+}
+
+// renderLyrics shows l, the lyrics of track, with line highlighted and
+// centred. Called from the UI goroutine.
+func (p *player) renderLyrics(track *provider.Track, l *trackLyrics, line int) {
+	v := p.lyricsView
+	if track == nil {
+		v.SetTitle(" Lyrics [l=Results] ")
+		v.SetText("\n[gray]Nothing playing[-]")
+		return
+	}
+	v.SetTitle(fmt.Sprintf(" Lyrics: %s [l=Results] ", tview.Escape(track.Title)))
+	p.mu.Lock()
+	done, res, err := l.done, l.lyrics, l.err
+	p.mu.Unlock()
+	switch {
+	case !done:
+		v.SetText("\n[yellow]Looking up lyrics...[-]")
+		return
+	case errors.Is(err, lyrics.ErrNotFound):
+		v.SetText("\n[gray]No lyrics found[-]")
+		return
+	case err != nil:
+		v.SetText(fmt.Sprintf("\n[red]Lyrics unavailable:[-] %s", tview.Escape(err.Error())))
+		return
+	case res.Instrumental:
+		v.SetText("\n[gray]♪ Instrumental ♪[-]")
+		return
+	case len(res.Synced) == 0:
+		v.SetText("[gray](not synced)[-]\n\n" + tview.Escape(res.Plain))
+		v.ScrollToBeginning()
+		return
+	}
+
+	var b strings.Builder
+	for i, s := range res.Synced {
+		text := tview.Escape(s.Text)
+		if text == "" {
+			text = "♪"
+		}
+		switch {
+		case i == line:
+			fmt.Fprintf(&b, "[yellow::b]%s[-::-]\n", text)
+		case i < line:
+			fmt.Fprintf(&b, "[gray]%s[-]\n", text)
+		default:
+			b.WriteString(text + "\n")
+		}
+	}
+	v.SetText(b.String())
+	_, _, _, height := v.GetInnerRect()
+	v.ScrollTo(max(line-height/2, 0), 0)
+}
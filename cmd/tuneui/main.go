@@ -3,15 +3,30 @@ package main
 import (
 	"flag"
 	"fmt"
+	"image"
 	"os"
-	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"syscall"
 	"time"
 
+	"audictl/internal/artwork"
+	"audictl/internal/backend"
+	"audictl/internal/config"
+	"audictl/internal/debuglog"
+	"audictl/internal/duck"
+	"audictl/internal/format"
+	"audictl/internal/lang"
 	"audictl/internal/mpv"
+	"audictl/internal/proc"
 	"audictl/internal/provider"
+	"audictl/internal/store"
+	"audictl/internal/testmode"
+	"audictl/internal/theme"
+	"audictl/internal/visualizer"
+	"audictl/providers/direct"
+	"audictl/providers/local"
 	sprov "audictl/providers/spotify"
 	yprov "audictl/providers/youtube"
 	"strings"
@@ -45,32 +60,149 @@ const (
 	actionFastForward
 	actionRewind
 	actionForceQuit
+	actionTogglePin
+	actionInsertNext
+	actionRemoveFromQueue
+	actionMoveUp
+	actionMoveDown
+	actionToggleMute
+	actionToggleVideo
+	actionToggleNormalize
+	actionSlower
+	actionFaster
+	actionResetSpeed
+	actionCycleLoop
+	actionCopyLink
+	actionOpenLink
+	actionVolumeDown
+	actionVolumeUp
+	actionCycleRepeat
+	actionToggleShuffle
+	actionToggleRadio
+	actionNextChapter
+	actionPrevChapter
+	actionToggleLowData
 )
 
 type player struct {
 	mu            sync.Mutex
 	queue         []provider.Track
-	queueIdx      int
-	currentCmd    *exec.Cmd
+	queueIdx      int            // queue position played last, -1 before the start
+	backend       backend.Player // running player, nil until first needed
+	backendMu     sync.Mutex     // serializes starting the player
 	currentTrk    *provider.Track
+	currentEntry  int            // mpv playlist entry of currentTrk, 0 until it starts
+	appended      *appendedTrack // next track already handed to mpv
+	prefetching   string         // ID of the track being prefetched
 	playbackStart time.Time
+	loopA, loopB  float64   // A-B loop points in seconds, -1 when unset
+	posBase       float64   // playback position in seconds at posAt
+	posAt         time.Time // when posBase was last known
+	speed         float64
+	repeat        repeatMode
+	shuffle       bool
+	radio         bool            // fill the queue with related tracks at its end
+	radioMu       sync.Mutex      // one radio fill at a time
+	shuffleNext   string          // ID of the track shuffle picked to play next
+	shufflePlayed map[string]bool // IDs of the tracks shuffle already played
+	lastLogged    string          // ID of the track last written to the history
+	lastLoggedAt  time.Time       // when it was written
+	writes        sync.WaitGroup  // history writes in flight, finished before quitting
+	cleanupOnce   sync.Once
 	paused        bool
+	muted         bool
+	normalize     bool
+	device        string // mpv --audio-device, "" for the default
+	ao            string // mpv --ao, "" for the default
+	eq            eqState
+	video         bool
+	showRemaining bool
+	duckTimer     *time.Timer
+	stopDuck      func()
 	searching     bool
 	stopSpinner   chan struct{}
 	stopProgress  chan struct{}
 	yt            provider.Provider
+	providers     *provider.Registry
+	library       *local.LocalProvider // nil without a music_dir
+
+	// Visualizer, used from the UI goroutine only
+	vizMode       vizMode
+	viz           *visualizer.Analyzer // nil while off or without a tap
+	vizErr        string               // why there is no picture
+	vizStop       chan struct{}
+	prefetch      *prefetched
+	retry         *playbackRetry // track being retried after failing
+	segments      *trackSegments // SponsorBlock segments of currentTrk
+	chapters      *trackChapters // chapters of currentTrk, once known
+	nowDetails    string         // what mpv told of currentTrk, shown below it in Now Playing
+	lyrics        *trackLyrics   // lyrics of the last track shown in the lyrics panel
+	stopLyrics    chan struct{}  // non-nil while the lyrics panel is shown
+	failStreak    int            // tracks skipped in a row after failing
+	nowPlayingMu  sync.Mutex     // serializes writes of the now-playing files
+	nowPlayingSeq int            // bumped on every track change
+	cfg           *config.Config
+	theme         theme.Theme // UI goroutine only once the UI runs
+	autoTheme     string      // theme "auto" picked at startup, "" if not asked
 	app           *tview.Application
+	pages         *tview.Pages
+	modalPrev     tview.Primitive
 	nowView       *tview.TextView
+	nowPanel      *tview.Flex
+	vizView       *tview.Box
+	consoleView   *tview.TextView
+	consoleOn     bool // debug console shown; UI goroutine only
+	artView       *tview.Box
+	artProto      artwork.Protocol // "" when album art is off; set on the UI goroutine under p.mu
+	artImg        image.Image      // album art of currentTrk; UI goroutine only
+	artRect       image.Rectangle  // where artImg goes on screen
+	artCells      artCells         // artImg as half blocks
+	artShown      artPlacement     // art last drawn with artProto
+	previewImg    image.Image      // seek preview shown over artImg; UI goroutine only
+	previewSeq    int              // bumped by each seek, so older previews aren't shown
+	boards        boardCache       // storyboard for seek previews
 	progressView  *tview.TextView
+	barWidth      int // cells of the progress bar, without the time after it; UI goroutine only
+	dragFrom      int // queue row a mouse drag started on, -1 for none; UI goroutine only
+	mainFlex      *tview.Flex
+	leftPanel     *tview.Flex
+	rightPanel    *tview.Flex
+	layout        config.Layout // UI goroutine only
+	narrow        bool          // panels stacked for a narrow terminal
+	root          *tview.Flex
+	statusBar     *tview.Flex
+	statusView    *tview.TextView // player state and modes at the bottom
+	miniView      *tview.TextView // the single line of mini mode
+	miniOn        bool
+	mini          miniState       // UI goroutine only
+	noticeView    *tview.TextView // notices next to statusView
+	noticeSeq     int             // bumped by every notice; UI goroutine only
+	statusKick    chan struct{}
 	queueView     *tview.List
 	searchView    *tview.InputField
 	linkView      *tview.InputField
 	resultsView   *tview.List
-	helpView      *tview.TextView
-	searchRes     []provider.Track
+	lyricsView    *tview.TextView
+	leftPages     *tview.Pages
+	searchRes     []provider.Track // the results shown
+	searchAll     []provider.Track // the results of all providers
+	searchTabs    []searchTab
+	searchTab     int // 0 for all providers, else index into searchTabs plus one
+	lastQuery     string
+	pins          *store.Pins
+	positions     *store.Positions // where long tracks were left
+	fromStart     bool             // don't resume long tracks this session
+	quality       provider.QualityPref
+	lowData       bool    // save bandwidth, see config.LowData
+	streamKbps    int     // bitrate mpv measures for the playing stream, 0 until known
+	dataUsed      float64 // estimated megabytes streamed this session
+	searches      *store.Searches
+	searchRecall  int    // position in the search history shown in the search box, -1 for none
+	searchDraft   string // what was typed before recalling the history
 	focusables    []tview.Primitive
 	focusIdx      int
 	actionChan    chan action
+	done          chan struct{} // closed by cleanup; actions are dropped after
 }
 
 func main() {
@@ -78,122 +210,161 @@ func main() {
 	var urls urlList
 	flag.Var(&urls, "url", "URL to open on startup (may be repeated)")
 	flag.Var(&urls, "u", "shorthand for --url")
+	ao := flag.String("ao", os.Getenv("AUDICTL_AO"), "mpv audio output driver, e.g. null to play without a sound device")
+	bench := flag.Int("bench", 0, "measure search, resolve and time-to-first-audio over N runs, then exit")
+	benchQuery := flag.String("bench-query", "lofi hip hop", "search query used by --bench")
+	mini := flag.Bool("mini", false, "start in mini mode: a single now-playing line (F4 toggles)")
+	zone := flag.String("zone", "", "play on the audio output of a zone named in the config")
+	importSpotify := flag.String("import-spotify", "", "add the listens and liked songs of an unpacked Spotify data export folder, then exit")
+	importLastfm := flag.String("import-lastfm", "", "add the scrobbles of a Last.fm CSV export to the history, then exit")
+	qualityName := flag.String("quality", "", "stream quality: best, lossless or low (default from the config)")
+	fromStart := flag.Bool("from-start", false, "play long tracks from the start instead of where they were left")
+	fromFile := flag.String("from-file", "", "queue every line of a file (- for stdin) on startup, each a search or a link")
 	flag.Parse()
 
 	app := tview.NewApplication()
 	p := &player{
 		queue:      []provider.Track{},
-		yt:         yprov.New(),
+		queueIdx:   -1,
+		yt:         provider.NewCached(yprov.New(), 100, 30*time.Minute),
 		app:        app,
 		actionChan: make(chan action, 10),
+		done:       make(chan struct{}),
+		statusKick: make(chan struct{}, 1),
+		speed:      1,
+		loopA:      -1,
+		loopB:      -1,
+		ao:         *ao,
+		fromStart:  *fromStart,
 	}
-
-	// Create UI components
-	p.searchView = tview.NewInputField()
-	p.searchView.SetLabel(" Search: ")
-	p.searchView.SetFieldWidth(0)
-	p.searchView.SetFieldBackgroundColor(tcell.ColorDarkSlateGray)
-
-	p.linkView = tview.NewInputField()
-	p.linkView.SetLabel(" Paste link: ")
-	p.linkView.SetFieldWidth(0)
-	p.linkView.SetFieldBackgroundColor(tcell.ColorDarkSlateGray)
-	p.linkView.SetDoneFunc(func(key tcell.Key) {
-		switch key {
-		case tcell.KeyEnter:
-			link := strings.TrimSpace(p.linkView.GetText())
-			if link != "" {
-				// Process in goroutine so we don't block the UI
-				go p.handleLink(link)
-				p.linkView.SetText("")
-			}
-		case tcell.KeyEsc, tcell.KeyTab, tcell.KeyBacktab:
-			// handled by global
+	if testmode.Enabled() {
+		// Generated tones instead of YouTube, played without a sound device
+		tp, err := testmode.New(filepath.Join(os.TempDir(), "audictl-testmode"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
 		}
-	})
-
-	p.resultsView = tview.NewList().ShowSecondaryText(false)
-	p.resultsView.SetBorder(true).SetTitle(" Results [Enter=Play, a=Queue] ")
-	p.resultsView.SetHighlightFullLine(true)
-	p.resultsView.SetSelectedBackgroundColor(tcell.ColorDarkCyan)
-
-	p.nowView = tview.NewTextView()
-	p.nowView.SetDynamicColors(true)
-	p.nowView.SetBorder(true)
-	p.nowView.SetTitle(" Now Playing ")
-	p.nowView.SetText("[yellow]No track playing[-]\n\nType to search, press Enter")
-
-	p.progressView = tview.NewTextView()
-	p.progressView.SetDynamicColors(true)
-	p.progressView.SetBorder(true)
-	p.progressView.SetTitle(" Progress ")
-	p.progressView.SetText("")
-
-	p.queueView = tview.NewList().ShowSecondaryText(false)
-	p.queueView.SetBorder(true).SetTitle(" Queue [Enter=Play] ")
-	p.queueView.SetHighlightFullLine(true)
-	p.queueView.SetSelectedBackgroundColor(tcell.ColorDarkCyan)
-
-	p.helpView = tview.NewTextView()
-	p.helpView.SetDynamicColors(true)
-	p.helpView.SetBorder(true)
-	p.helpView.SetTitle(" Controls ")
-	p.helpView.SetText(
-		"[green]Tab[-]    Next panel    [green]S-Tab[-]  Prev panel\n" +
-			"[green]Enter[-]  Play selected  [green]a[-]      Add to queue\n" +
-			"[green]n[-]      Next track     [green]p[-]      Prev track\n" +
-			"[green]Space[-]  Play/Pause     [green]s[-]      Stop\n" +
-			"[green]→ ←[-]    Fwd/Rewind     [green]c[-]      Clear queue\n" +
-			"[green]Esc[-]    Unfocus        [green]q[-]      Force Quit\n" +
-			"\n" +
-			"[yellow]YouTube:[-] yt.be/xxx or youtube.com/...\n" +
-			"[yellow]Spotify:[-] open.spotify.com/track/xxx [gray](→ searches YouTube)[-]",
-	)
+		p.yt = tp
+		if p.ao == "" {
+			p.ao = testmode.AudioOutput
+		}
+	}
+	p.providers = provider.NewRegistry(p.yt, direct.New())
 
-	// Track focusable items
-	p.focusables = []tview.Primitive{p.searchView, p.linkView, p.resultsView, p.queueView}
-	p.focusIdx = 0
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+	}
+	p.cfg = cfg
+	p.showRemaining = cfg.TimeDisplay == "remaining"
+	p.artProto = artProtocol(cfg)
+	p.normalize = cfg.Normalize
+	p.lowData = cfg.LowData
+	p.radio = cfg.Radio
+	p.device, err = loadDevice(cfg, *zone)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+	p.eq = loadEQ(cfg)
+	p.layout = loadLayout(cfg)
+	if err := setProxies(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "proxy: %v\n", err)
+		os.Exit(1)
+	}
+	yprov.SetYtDlpPath(expandHome(cfg.YtDlpPath))
+	yprov.SetOptions(ytDlpOptions(cfg))
+	yprov.SetQuality(yprov.Quality{Codecs: cfg.Quality.Codecs, MinKbps: cfg.Quality.MinKbps, MaxKbps: cfg.Quality.MaxKbps})
+	preset := cfg.Quality.Preset
+	if *qualityName != "" {
+		preset = *qualityName
+	}
+	if p.quality, err = provider.ParseQuality(preset); err != nil {
+		fmt.Fprintf(os.Stderr, "quality: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.MusicDir != "" {
+		p.library = local.New(expandHome(cfg.MusicDir))
+		p.providers.Register(p.library)
+	}
 
-	// Layout
-	searchBox := tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(tview.NewFlex().
-			AddItem(nil, 1, 0, false).
-			AddItem(p.searchView, 0, 1, true).
-			AddItem(nil, 1, 0, false), 3, 0, true).
-		AddItem(tview.NewFlex().
-			AddItem(nil, 1, 0, false).
-			AddItem(p.linkView, 0, 1, false).
-			AddItem(nil, 1, 0, false), 3, 0, false)
+	if *importSpotify != "" || *importLastfm != "" {
+		if err := runImport(*importSpotify, *importLastfm); err != nil {
+			fmt.Fprintf(os.Stderr, "import: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	leftPanel := tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(searchBox, 3, 0, true).
-		AddItem(p.resultsView, 0, 1, false).
-		AddItem(p.progressView, 3, 0, false)
+	// Read before the UI takes over the terminal
+	var batch []string
+	if *fromFile != "" {
+		batch, err = readBatch(*fromFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "from-file: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	rightPanel := tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(p.nowView, 0, 2, false).
-		AddItem(p.queueView, 0, 3, false).
-		AddItem(p.helpView, 7, 0, false)
+	if *bench > 0 {
+		err := runBench(p.yt, *benchQuery, *bench, cfg.Backend, backend.Options{
+			Device:       p.device,
+			AudioOutput:  p.ao,
+			YtDlpPath:    expandHome(cfg.YtDlpPath),
+			YtDlpOptions: ytDlpOptions(cfg),
+			HTTPProxy:    httpProxy(cfg),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	mainFlex := tview.NewFlex().
-		AddItem(leftPanel, 0, 2, true).
-		AddItem(rightPanel, 0, 1, false)
+	p.theme, err = theme.Resolve(cfg.Theme, cfg.Themes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		p.theme, _ = theme.Resolve("dark", nil)
+	}
+	if cfg.Theme == "" || cfg.Theme == "auto" {
+		p.autoTheme = p.theme.Name
+	}
+	p.theme.Apply()
 
-	app.SetRoot(mainFlex, true).EnableMouse(true)
+	pins, err := store.LoadPins()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pins: %v\n", err)
+	}
+	p.pins = pins
+	positions, err := store.LoadPositions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "positions: %v\n", err)
+	}
+	p.positions = positions
+	searches, err := store.LoadSearches()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "search history: %v\n", err)
+	}
+	p.searches = searches
+	p.searchRecall = -1
 
-	// Setup handlers
-	p.setupHandlers()
+	p.buildUI()
 
 	// Set initial focus
 	app.SetFocus(p.searchView)
+	if *mini {
+		p.toggleMini()
+	}
 
 	// Start action processor
 	go p.processActions()
+	go p.followStatus()
 
 	// If startup URLs were provided, process them shortly after initialization.
 	// Behavior: multiple occurrences allowed. Single-track single-URL will play immediately.
 	if len(urls) > 0 {
 		go func() {
+			defer p.recoverPanic()
 			// Small delay to ensure UI has initialised enough for updates
 			time.Sleep(150 * time.Millisecond)
 			for i, link := range urls {
@@ -202,21 +373,21 @@ func main() {
 					continue
 				}
 
-				// Debug print so CLI users see what's happening on startup
-				fmt.Fprintf(os.Stderr, "startup: processing url [%d]: %s\n", i+1, link)
+				// Written to the debug console: stderr is hidden behind the UI
+				debuglog.Printf("app", "startup: processing url [%d]: %s", i+1, link)
 
 				// YouTube
 				if strings.Contains(link, "youtube.com") || strings.Contains(link, "youtu.be") {
 					y := yprov.New()
 					tracks, err := y.FetchTracksFromURL(link, 0)
 					if err != nil {
-						fmt.Fprintf(os.Stderr, "startup: youtube extraction error: %v\n", err)
-						p.updateNowPlaying(fmt.Sprintf("[red]Link error:[-] %v", err))
+						debuglog.Printf("app", "startup: youtube extraction error: %v", err)
+						p.notify(fmt.Sprintf("[red]Link error:[-] %s", provider.Explain(err)))
 						continue
 					}
-					fmt.Fprintf(os.Stderr, "startup: youtube returned %d tracks\n", len(tracks))
+					debuglog.Printf("app", "startup: youtube returned %d tracks", len(tracks))
 					if len(tracks) == 0 {
-						p.updateNowPlaying("[yellow]No tracks found in link[-]")
+						p.notify("[yellow]No tracks found in link[-]")
 						continue
 					}
 					// If single URL and single track, auto-play
@@ -228,23 +399,23 @@ func main() {
 					p.queue = append(p.queue, tracks...)
 					p.mu.Unlock()
 					p.updateQueueView()
-					p.updateNowPlaying(fmt.Sprintf("[green]+ Added playlist:[-] %d tracks", len(tracks)))
+					p.notify(fmt.Sprintf("[green]+ Added playlist:[-] %d tracks", len(tracks)))
 					continue
 				}
 
 				// Spotify
 				if strings.Contains(link, "spotify.com") {
-					fmt.Fprintf(os.Stderr, "startup: spotify url -> %s\n", link)
+					debuglog.Printf("app", "startup: spotify url -> %s", link)
 					sp := sprov.New()
 					tracks, err := sp.FetchTracksFromURL(link)
 					if err != nil {
-						fmt.Fprintf(os.Stderr, "startup: spotify extraction error: %v\n", err)
-						p.updateNowPlaying(fmt.Sprintf("[red]Spotify error:[-] %v", err))
+						debuglog.Printf("app", "startup: spotify extraction error: %v", err)
+						p.notify(fmt.Sprintf("[red]Spotify error:[-] %v", err))
 						continue
 					}
-					fmt.Fprintf(os.Stderr, "startup: spotify returned %d tracks\n", len(tracks))
+					debuglog.Printf("app", "startup: spotify returned %d tracks", len(tracks))
 					if len(tracks) == 0 {
-						p.updateNowPlaying("[yellow]No tracks found in Spotify link[-]")
+						p.notify("[yellow]No tracks found in Spotify link[-]")
 						continue
 					}
 					if len(tracks) == 1 && len(urls) == 1 {
@@ -256,20 +427,33 @@ func main() {
 					p.mu.Unlock()
 					p.updateQueueView()
 					if len(tracks) == 1 {
-						p.updateNowPlaying(fmt.Sprintf("[green]+ Added:[-] %s", tracks[0].Title))
+						p.notify(fmt.Sprintf("[green]+ Added:[-] %s", tracks[0].Title))
 					} else {
-						p.updateNowPlaying(fmt.Sprintf("[green]+ Added playlist:[-] %d items", len(tracks)))
+						p.notify(fmt.Sprintf("[green]+ Added playlist:[-] %d items", len(tracks)))
 					}
 					continue
 				}
 
 				// Unsupported
-				p.updateNowPlaying("[yellow]Unsupported link type[-]")
+				p.notify("[yellow]Unsupported link type[-]")
 				_ = i
 			}
 		}()
 	}
 
+	if len(batch) > 0 {
+		go p.queueBatch(batch)
+	}
+
+	// Optionally duck playback while desktop notifications fire
+	if cfg.Duck {
+		p.startDucking(cfg.DuckMatch)
+	}
+
+	if !testmode.Enabled() {
+		go p.checkYtDlp()
+	}
+
 	// Handle system signals
 	go func() {
 		sigs := make(chan os.Signal, 1)
@@ -279,12 +463,149 @@ func main() {
 		app.Stop()
 	}()
 
-	if err := app.Run(); err != nil {
+	// SIGHUP reloads the config file without interrupting playback
+	go func() {
+		hups := make(chan os.Signal, 1)
+		signal.Notify(hups, syscall.SIGHUP)
+		for range hups {
+			p.reloadConfig()
+		}
+	}()
+
+	defer p.recoverUIPanic()
+	err = app.Run()
+	// A panic elsewhere stops the app too; let its crash report finish
+	waitForCrash()
+	// The UI loop is over, so its state is ours now
+	if p.viz != nil {
+		p.viz.Stop()
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "TUI error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// buildUI creates the panels and lays them out, with their key and mouse
+// handlers.
+func (p *player) buildUI() {
+	p.searchView = tview.NewInputField()
+	p.searchView.SetLabel(" Search: ")
+	p.searchView.SetFieldWidth(0)
+	p.searchView.SetFieldBackgroundColor(p.theme.Field)
+
+	p.linkView = tview.NewInputField()
+	p.linkView.SetLabel(" Paste link: ")
+	p.linkView.SetFieldWidth(0)
+	p.linkView.SetFieldBackgroundColor(p.theme.Field)
+	p.linkView.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			link := strings.TrimSpace(p.linkView.GetText())
+			if link != "" {
+				// Process in goroutine so we don't block the UI
+				go p.handleLink(link)
+				p.linkView.SetText("")
+			}
+		case tcell.KeyEsc, tcell.KeyTab, tcell.KeyBacktab:
+			// handled by global
+		}
+	})
+
+	p.resultsView = tview.NewList().ShowSecondaryText(false)
+	p.resultsView.SetBorder(true).SetTitle(" Results " + resultsHelp + " ")
+	p.resultsView.SetHighlightFullLine(true)
+	p.resultsView.SetSelectedBackgroundColor(p.theme.Selection)
+	p.resultsView.SetSelectedTextColor(p.theme.SelectionText)
+	p.resultsView.SetFocusFunc(p.hideLyrics)
+
+	p.lyricsView = tview.NewTextView()
+	p.lyricsView.SetDynamicColors(true)
+	p.lyricsView.SetTextAlign(tview.AlignCenter)
+	p.lyricsView.SetWrap(false)
+	p.lyricsView.SetBorder(true).SetTitle(" Lyrics [l=Results] ")
+
+	p.nowView = tview.NewTextView()
+	p.nowView.SetDynamicColors(true)
+	p.nowView.SetText("[yellow]No track playing[-]\n\nType to search, press Enter\n[gray]? for help, Ctrl+P for all commands[-]")
+
+	p.progressView = tview.NewTextView()
+	p.progressView.SetDynamicColors(true)
+	p.progressView.SetBorder(true)
+	p.progressView.SetTitle(" Progress ")
+	p.progressView.SetWrap(false)
+	p.progressView.SetText("")
+
+	p.vizView = tview.NewBox()
+	p.vizView.SetBorder(true).SetTitle(" Visualizer ")
+	p.vizView.SetDrawFunc(p.drawViz)
+
+	p.consoleView = tview.NewTextView()
+	p.consoleView.SetDynamicColors(true)
+	p.consoleView.SetWrap(false)
+	p.consoleView.SetBorder(true).SetTitle(" Debug console [`=Close] ")
+
+	p.queueView = tview.NewList().ShowSecondaryText(false)
+	p.queueView.SetBorder(true).SetTitle(" Queue " + queueHelp + " ")
+	p.queueView.SetHighlightFullLine(true)
+	p.queueView.SetSelectedBackgroundColor(p.theme.Selection)
+	p.queueView.SetSelectedTextColor(p.theme.SelectionText)
+
+	// Track focusable items
+	p.focusables = []tview.Primitive{p.searchView, p.linkView, p.resultsView, p.queueView}
+	p.focusIdx = 0
+
+	// Layout
+	searchBox := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(nil, 1, 0, false).
+			AddItem(p.searchView, 0, 1, true).
+			AddItem(nil, 1, 0, false), 3, 0, true).
+		AddItem(tview.NewFlex().
+			AddItem(nil, 1, 0, false).
+			AddItem(p.linkView, 0, 1, false).
+			AddItem(nil, 1, 0, false), 3, 0, false)
+
+	p.leftPages = tview.NewPages().
+		AddPage("results", p.resultsView, true, true).
+		AddPage("lyrics", p.lyricsView, true, false)
+
+	p.leftPanel = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(searchBox, 3, 0, true).
+		AddItem(p.leftPages, 0, 1, false).
+		AddItem(p.progressView, 3, 0, false)
+
+	p.artView = tview.NewBox()
+	p.artView.SetDrawFunc(p.drawArtCells)
+	p.nowPanel = tview.NewFlex().
+		AddItem(p.artView, 0, 0, false).
+		AddItem(p.nowView, 0, 1, false)
+	p.nowPanel.SetBorder(true).SetTitle(" Now Playing ")
+	p.app.SetAfterDrawFunc(p.drawArt)
+
+	p.rightPanel = tview.NewFlex().SetDirection(tview.FlexRow)
+	p.mainFlex = tview.NewFlex()
+	p.applyLayout()
+	p.app.SetBeforeDrawFunc(p.fitLayout)
+
+	p.statusView = tview.NewTextView().SetDynamicColors(true)
+	p.noticeView = tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignRight)
+	p.statusBar = tview.NewFlex().
+		AddItem(p.statusView, 0, 1, false).
+		AddItem(p.noticeView, 0, 1, false)
+	p.miniView = tview.NewTextView().SetDynamicColors(true)
+	p.root = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(p.mainFlex, 0, 1, true).
+		AddItem(p.statusBar, 1, 0, false)
+
+	p.pages = tview.NewPages().AddPage("main", p.root, true, true)
+	p.app.SetRoot(p.pages, true).EnableMouse(true)
+
+	// Setup handlers
+	p.setupHandlers()
+	p.setupMouse()
+}
+
 func (p *player) setupHandlers() {
 	// Search input - Enter to search, Esc to leave
 	p.searchView.SetDoneFunc(func(key tcell.Key) {
@@ -292,6 +613,7 @@ func (p *player) setupHandlers() {
 		case tcell.KeyEnter:
 			query := p.searchView.GetText()
 			if query != "" {
+				p.rememberSearch(query)
 				p.performSearch(query)
 			}
 		case tcell.KeyEsc, tcell.KeyTab, tcell.KeyBacktab:
@@ -316,36 +638,20 @@ func (p *player) setupHandlers() {
 	p.resultsView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Rune() {
 		case 'a', 'A':
-			p.actionChan <- actionAddToQueue
-			return nil
-		case 'n', 'N':
-			p.actionChan <- actionNext
-			return nil
-		case 'p', 'P':
-			p.actionChan <- actionPrevious
+			p.act(actionAddToQueue)
 			return nil
-		case 's', 'S':
-			p.actionChan <- actionStop
+		case '*':
+			p.act(actionTogglePin)
 			return nil
-		case 'c', 'C':
-			p.actionChan <- actionClearQueue
-			return nil
-		case ' ':
-			p.actionChan <- actionPause
-			return nil
-		case 'q', 'Q':
-			p.actionChan <- actionForceQuit
+		case 'i', 'I':
+			p.act(actionInsertNext)
 			return nil
 		}
-		switch event.Key() {
-		case tcell.KeyRight:
-			p.actionChan <- actionFastForward
-			return nil
-		case tcell.KeyLeft:
-			p.actionChan <- actionRewind
+		if r := event.Rune(); r >= '0' && r <= '9' && event.Modifiers() == tcell.ModNone {
+			p.switchSearchTab(int(r - '0'))
 			return nil
 		}
-		return p.handleGlobalKey(event)
+		return p.handlePlaybackKey(event)
 	})
 
 	// Queue list
@@ -365,40 +671,47 @@ func (p *player) setupHandlers() {
 	// Intercept keys on queue list
 	p.queueView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Rune() {
-		case 'n', 'N':
-			p.actionChan <- actionNext
+		case 'd', 'D':
+			p.act(actionRemoveFromQueue)
 			return nil
-		case 'p', 'P':
-			p.actionChan <- actionPrevious
+		case 'K':
+			p.act(actionMoveUp)
 			return nil
-		case 's', 'S':
-			p.actionChan <- actionStop
+		case 'J':
+			p.act(actionMoveDown)
 			return nil
-		case 'c', 'C':
-			p.actionChan <- actionClearQueue
-			return nil
-		case ' ':
-			p.actionChan <- actionPause
-			return nil
-		case 'q', 'Q':
-			p.actionChan <- actionForceQuit
+		case 'f', 'F':
+			p.jumpToCurrent()
 			return nil
 		}
-		switch event.Key() {
-		case tcell.KeyRight:
-			p.actionChan <- actionFastForward
+		switch {
+		case event.Key() == tcell.KeyDelete:
+			p.act(actionRemoveFromQueue)
 			return nil
-		case tcell.KeyLeft:
-			p.actionChan <- actionRewind
+		case event.Key() == tcell.KeyUp && event.Modifiers()&tcell.ModShift != 0:
+			p.act(actionMoveUp)
+			return nil
+		case event.Key() == tcell.KeyDown && event.Modifiers()&tcell.ModShift != 0:
+			p.act(actionMoveDown)
 			return nil
 		}
-		return p.handleGlobalKey(event)
+		return p.handlePlaybackKey(event)
 	})
 
 	// Global input capture
 	p.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		focused := p.app.GetFocus()
 
+		// Modals handle their own keys (Esc closes them)
+		if p.modalOpen() {
+			if event.Key() == tcell.KeyCtrlC {
+				p.cleanup()
+				p.app.Stop()
+				return nil
+			}
+			return event
+		}
+
 		// If in search box, only intercept Tab/Esc/Ctrl+C
 		if focused == p.searchView {
 			switch event.Key() {
@@ -415,6 +728,20 @@ func (p *player) setupHandlers() {
 				p.cleanup()
 				p.app.Stop()
 				return nil
+			case tcell.KeyCtrlZ:
+				p.suspend()
+				return nil
+			case tcell.KeyUp:
+				p.recallSearch(1)
+				return nil
+			case tcell.KeyDown:
+				p.recallSearch(-1)
+				return nil
+			case tcell.KeyCtrlR:
+				p.showSearchHistory()
+				return nil
+			case tcell.KeyF1, tcell.KeyF3, tcell.KeyF4, tcell.KeyCtrlP:
+				return p.handleGlobalKey(event)
 			}
 			return event
 		}
@@ -423,6 +750,121 @@ func (p *player) setupHandlers() {
 	})
 }
 
+// handlePlaybackKey handles the transport keys shared by the results and
+// queue lists, falling back to the global keys for anything else.
+func (p *player) handlePlaybackKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Rune() {
+	case 'n', 'N':
+		p.act(actionNext)
+		return nil
+	case 'p', 'P':
+		p.act(actionPrevious)
+		return nil
+	case 's', 'S':
+		p.act(actionStop)
+		return nil
+	case 'c', 'C':
+		p.act(actionClearQueue)
+		return nil
+	case ' ':
+		p.act(actionPause)
+		return nil
+	case 'm', 'M':
+		p.act(actionToggleMute)
+		return nil
+	case '`':
+		p.toggleConsole()
+		return nil
+	case 'w', 'W':
+		p.act(actionToggleVideo)
+		return nil
+	case 'g', 'G':
+		p.act(actionToggleNormalize)
+		return nil
+	case 'H':
+		p.showHistory()
+		return nil
+	case 'e', 'E':
+		p.showEQ()
+		return nil
+	case 'h':
+		p.showRecent()
+		return nil
+	case 't', 'T':
+		p.toggleRemaining()
+		return nil
+	case 'q', 'Q':
+		p.act(actionForceQuit)
+		return nil
+	case 'b', 'B':
+		p.act(actionCycleLoop)
+		return nil
+	case ':':
+		p.promptSeek()
+		return nil
+	case 'l', 'L':
+		p.toggleLyrics()
+		return nil
+	case 'y', 'Y':
+		p.act(actionCopyLink)
+		return nil
+	case 'o', 'O':
+		p.act(actionOpenLink)
+		return nil
+	case '[':
+		p.act(actionSlower)
+		return nil
+	case ']':
+		p.act(actionFaster)
+		return nil
+	case '.':
+		p.act(actionNextChapter)
+		return nil
+	case ',':
+		p.act(actionPrevChapter)
+		return nil
+	case '-':
+		p.act(actionVolumeDown)
+		return nil
+	case '+', '=':
+		p.act(actionVolumeUp)
+		return nil
+	case 'r', 'R':
+		p.act(actionCycleRepeat)
+		return nil
+	case 'z', 'Z':
+		p.act(actionToggleShuffle)
+		return nil
+	case '<':
+		p.moveSplit(-splitStep)
+		return nil
+	case '>':
+		p.moveSplit(splitStep)
+		return nil
+	case '?':
+		p.toggleHelp()
+		return nil
+	case 'v', 'V':
+		p.toggleViz()
+		return nil
+	case 'u', 'U':
+		p.act(actionToggleRadio)
+		return nil
+	}
+	switch event.Key() {
+	case tcell.KeyRight:
+		p.act(actionFastForward)
+		return nil
+	case tcell.KeyLeft:
+		p.act(actionRewind)
+		return nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		p.act(actionResetSpeed)
+		return nil
+	}
+	return p.handleGlobalKey(event)
+}
+
 func (p *player) handleGlobalKey(event *tcell.EventKey) *tcell.EventKey {
 	switch event.Key() {
 	case tcell.KeyCtrlC:
@@ -430,7 +872,52 @@ func (p *player) handleGlobalKey(event *tcell.EventKey) *tcell.EventKey {
 		p.app.Stop()
 		return nil
 	case tcell.KeyCtrlQ:
-		p.actionChan <- actionForceQuit
+		p.act(actionForceQuit)
+		return nil
+	case tcell.KeyCtrlZ:
+		p.suspend()
+		return nil
+	case tcell.KeyCtrlR:
+		p.showSearchHistory()
+		return nil
+	case tcell.KeyCtrlS:
+		p.promptSavePlaylist()
+		return nil
+	case tcell.KeyCtrlO:
+		p.showPlaylists()
+		return nil
+	case tcell.KeyCtrlE:
+		p.promptExportQueue()
+		return nil
+	case tcell.KeyCtrlD:
+		p.showDevices()
+		return nil
+	case tcell.KeyCtrlT:
+		p.showSessions()
+		return nil
+	case tcell.KeyCtrlB:
+		p.showLibrary()
+		return nil
+	case tcell.KeyCtrlK:
+		p.showSnapcast()
+		return nil
+	case tcell.KeyCtrlG:
+		p.showRecommendations()
+		return nil
+	case tcell.KeyCtrlW:
+		p.showStats()
+		return nil
+	case tcell.KeyF1:
+		p.toggleHelp()
+		return nil
+	case tcell.KeyCtrlP:
+		p.showPalette()
+		return nil
+	case tcell.KeyF3:
+		p.cycleView()
+		return nil
+	case tcell.KeyF4:
+		p.toggleMini()
 		return nil
 	case tcell.KeyTab:
 		p.nextFocus()
@@ -447,7 +934,14 @@ func (p *player) handleGlobalKey(event *tcell.EventKey) *tcell.EventKey {
 }
 
 func (p *player) processActions() {
-	for action := range p.actionChan {
+	defer p.recoverPanic()
+	for {
+		var action action
+		select {
+		case action = <-p.actionChan:
+		case <-p.done:
+			return
+		}
 		switch action {
 		case actionAddToQueue:
 			p.addToQueue()
@@ -456,33 +950,102 @@ func (p *player) processActions() {
 		case actionPrevious:
 			p.previous()
 		case actionStop:
+			p.cancelRetry()
 			p.stop()
+			p.writeNowPlaying(nil)
 			p.updateNowPlaying("[yellow]Stopped[-]")
 		case actionClearQueue:
 			p.clearQueue()
 		case actionPlay:
-			mpv.Play()
+			if b := p.out(); b != nil {
+				b.Resume()
+			}
 		case actionPause:
-			mpv.Pause()
+			if b := p.out(); b != nil {
+				b.TogglePause()
+			}
 		case actionFastForward:
-			mpv.Seek(10) // Skip forward 10 seconds
+			p.seekBy(seekStep)
 		case actionRewind:
-			mpv.Seek(-10) // Rewind 10 seconds
+			p.seekBy(-seekStep)
+		case actionNextChapter:
+			p.skipChapter(1)
+		case actionPrevChapter:
+			p.skipChapter(-1)
+		case actionToggleLowData:
+			p.toggleLowData()
 		case actionForceQuit:
 			p.forceQuit()
+		case actionTogglePin:
+			p.togglePin()
+		case actionInsertNext:
+			p.insertNext()
+		case actionRemoveFromQueue:
+			p.removeFromQueue(p.queueView.GetCurrentItem())
+		case actionMoveUp:
+			idx := p.queueView.GetCurrentItem()
+			p.moveInQueue(idx, idx-1)
+		case actionMoveDown:
+			idx := p.queueView.GetCurrentItem()
+			p.moveInQueue(idx, idx+1)
+		case actionToggleMute:
+			p.toggleMute()
+		case actionToggleVideo:
+			p.toggleVideo()
+		case actionToggleNormalize:
+			p.toggleNormalize()
+		case actionSlower:
+			p.changeSpeed(-speedStep)
+		case actionFaster:
+			p.changeSpeed(speedStep)
+		case actionResetSpeed:
+			p.changeSpeed(0)
+		case actionCycleLoop:
+			p.cycleLoop()
+		case actionCopyLink:
+			p.copyLink()
+		case actionOpenLink:
+			p.openLink()
+		case actionVolumeDown:
+			p.changeVolume(-volumeStep)
+		case actionVolumeUp:
+			p.changeVolume(volumeStep)
+		case actionCycleRepeat:
+			p.cycleRepeat()
+		case actionToggleShuffle:
+			p.toggleShuffle()
+		case actionToggleRadio:
+			p.toggleRadio()
 		}
+		p.refreshStatus()
+	}
+}
+
+// act queues a for processActions. Keys pressed while quitting are
+// dropped rather than waited on.
+func (p *player) act(a action) {
+	select {
+	case p.actionChan <- a:
+	case <-p.done:
 	}
 }
 
 func (p *player) nextFocus() {
-	p.focusIdx = (p.focusIdx + 1) % len(p.focusables)
-	p.app.SetFocus(p.focusables[p.focusIdx])
+	p.cycleFocus(1)
 }
 
 func (p *player) prevFocus() {
-	p.focusIdx--
-	if p.focusIdx < 0 {
-		p.focusIdx = len(p.focusables) - 1
+	p.cycleFocus(-1)
+}
+
+// cycleFocus moves the focus by step through the panels that are shown.
+func (p *player) cycleFocus(step int) {
+	n := len(p.focusables)
+	for range n {
+		p.focusIdx = (p.focusIdx + step + n) % n
+		if p.shown(p.focusables[p.focusIdx]) {
+			break
+		}
 	}
 	p.app.SetFocus(p.focusables[p.focusIdx])
 }
@@ -490,7 +1053,7 @@ func (p *player) prevFocus() {
 func (p *player) addToQueue() {
 	focused := p.app.GetFocus()
 	if focused != p.resultsView {
-		p.updateNowPlaying("[yellow]Select a result first (Tab to results, then 'a')[-]")
+		p.notify("[yellow]Select a result first (Tab to results, then 'a')[-]")
 		return
 	}
 
@@ -498,7 +1061,7 @@ func (p *player) addToQueue() {
 	p.mu.Lock()
 	if idx < 0 || idx >= len(p.searchRes) {
 		p.mu.Unlock()
-		p.updateNowPlaying("[yellow]No result selected[-]")
+		p.notify("[yellow]No result selected[-]")
 		return
 	}
 	track := p.searchRes[idx]
@@ -507,17 +1070,47 @@ func (p *player) addToQueue() {
 	p.mu.Unlock()
 
 	p.updateQueueView()
-	p.updateNowPlaying(fmt.Sprintf("[green]+ Added:[-] %s", title))
+	p.notify(fmt.Sprintf("[green]+ Added:[-] %s", title))
 }
 
-func (p *player) performSearch(query string) {
+// togglePin pins (or unpins) the selected result for the last search query so
+// it is ranked first the next time the same query is searched.
+func (p *player) togglePin() {
+	if p.pins == nil {
+		return
+	}
+	idx := p.resultsView.GetCurrentItem()
 	p.mu.Lock()
-	if p.stopSpinner != nil {
-		close(p.stopSpinner)
+	if idx < 0 || idx >= len(p.searchRes) {
+		p.mu.Unlock()
+		p.notify("[yellow]No result selected[-]")
+		return
 	}
-	p.stopSpinner = make(chan struct{})
-	p.searching = true
-	stopCh := p.stopSpinner
+	track := p.searchRes[idx]
+	query := p.lastQuery
+	p.mu.Unlock()
+
+	pinned, err := p.pins.Toggle(query, track)
+	if err != nil {
+		p.notify(fmt.Sprintf("[red]Pin error:[-] %v", err))
+		return
+	}
+	p.updateResultsView()
+	if pinned {
+		p.notify(fmt.Sprintf("[green]★ Pinned for '%s':[-] %s", query, track.Title))
+	} else {
+		p.notify(fmt.Sprintf("[yellow]Unpinned:[-] %s", track.Title))
+	}
+}
+
+func (p *player) performSearch(query string) {
+	p.mu.Lock()
+	if p.stopSpinner != nil {
+		close(p.stopSpinner)
+	}
+	p.stopSpinner = make(chan struct{})
+	p.searching = true
+	stopCh := p.stopSpinner
 	p.mu.Unlock()
 
 	p.resultsView.Clear()
@@ -535,7 +1128,7 @@ func (p *player) performSearch(query string) {
 				return
 			case <-ticker.C:
 				p.app.QueueUpdateDraw(func() {
-					p.nowView.SetText(fmt.Sprintf("[yellow]%s Searching for '%s'...[-]", frames[i], query))
+					p.showNotice(fmt.Sprintf("[yellow]%s Searching for '%s'...[-]", frames[i], query))
 				})
 				i = (i + 1) % len(frames)
 			}
@@ -543,7 +1136,37 @@ func (p *player) performSearch(query string) {
 	}()
 
 	go func() {
-		results, err := p.yt.Search(query, provider.SearchKindTrack, 10)
+		defer p.recoverPanic()
+		// "lang:xx" keeps only results in that language; fetch more to
+		// leave enough after filtering
+		terms, language := lang.ParseFilter(query)
+		terms, kind := p.searchKind(terms)
+		limit := 10
+		if language != "" {
+			limit = 20
+		}
+		var tabs []searchTab
+		var failed []string
+		var err error
+		for _, res := range p.providers.SearchAll(terms, kind, limit) {
+			if res.Err != nil {
+				failed = append(failed, res.Provider)
+				err = res.Err
+				continue
+			}
+			tracks := res.Tracks
+			if language != "" {
+				tracks = lang.Filter(tracks, language)
+			}
+			if len(tracks) > 0 {
+				tabs = append(tabs, searchTab{provider: res.Provider, tracks: tracks})
+			}
+		}
+		if len(tabs) > 0 {
+			// Report failures only when nothing at all came back
+			err = nil
+		}
+		results := mergeTabs(tabs)
 
 		p.mu.Lock()
 		if p.stopSpinner == stopCh {
@@ -554,31 +1177,51 @@ func (p *player) performSearch(query string) {
 		p.mu.Unlock()
 
 		if err != nil {
-			p.updateNowPlaying(fmt.Sprintf("[red]Search error:[-] %v", err))
+			p.notify(fmt.Sprintf("[red]Search error:[-] %s", provider.Explain(err)))
 			return
 		}
 		if len(results) == 0 {
-			p.updateNowPlaying("[yellow]No results found[-]")
+			if language != "" {
+				p.notify(fmt.Sprintf("[yellow]No results in language '%s'[-]", language))
+				return
+			}
+			p.notify("[yellow]No results found[-]")
 			return
 		}
 
+		if p.pins != nil {
+			results = p.pins.Rank(terms, results)
+			for i := range tabs {
+				tabs[i].tracks = rankTab(p.pins, terms, tabs[i])
+			}
+		}
+
 		p.mu.Lock()
 		p.searchRes = results
+		p.searchAll = results
+		p.searchTabs = tabs
+		p.searchTab = 0
+		p.lastQuery = terms
 		p.mu.Unlock()
 
+		p.updateResultsView()
 		p.app.QueueUpdateDraw(func() {
-			p.resultsView.Clear()
-			for i, track := range results {
-				dur := ""
-				if track.Duration > 0 {
-					dur = fmt.Sprintf(" [%d:%02d]", track.Duration/60, track.Duration%60)
-				}
-				title := fmt.Sprintf("%d. %s - %s%s", i+1, track.Artist, track.Title, dur)
-				p.resultsView.AddItem(title, "", 0, nil)
+			if !p.modalOpen() {
+				// Don't pull the focus out of a modal opened meanwhile
+				p.focusIdx = 2
+				p.app.SetFocus(p.resultsView)
+			}
+			msg := fmt.Sprintf("[green]✓ Found %d results[-]", len(results))
+			if len(tabs) > 1 {
+				msg += fmt.Sprintf(" from %d providers", len(tabs))
+			}
+			if len(failed) > 0 {
+				msg += fmt.Sprintf(" [gray](%s failed)[-]", strings.Join(failed, ", "))
 			}
-			p.focusIdx = 1
-			p.app.SetFocus(p.resultsView)
-			p.nowView.SetText(fmt.Sprintf("[green]✓ Found %d results[-]\n\nUse [yellow]↑/↓[-] to navigate\n[yellow]Enter[-] to play, [yellow]a[-] to queue", len(results)))
+			if len(tabs) > 1 {
+				msg += " · [yellow]0-9[-] to show one provider"
+			}
+			p.showNotice(msg)
 		})
 	}()
 }
@@ -587,32 +1230,50 @@ func (p *player) performSearch(query string) {
 // as playlists. For playlists, all entries are added to the queue; single tracks are played
 // (YouTube) or added to the queue (Spotify metadata, DRM).
 func (p *player) handleLink(link string) {
+	defer p.recoverPanic()
 	link = strings.TrimSpace(link)
 	if link == "" {
 		return
 	}
 
+	// Local M3U/PLS/XSPF playlist files
+	if path := playlistFilePath(link); path != "" {
+		p.importPlaylistFile(path)
+		return
+	}
+
 	// YouTube links (video or playlist)
 	if strings.Contains(link, "youtube.com") || strings.Contains(link, "youtu.be") {
-		y := yprov.New()
-		tracks, err := y.FetchTracksFromURL(link, 0)
+		// Queue entries as they're listed and completed, but hold the first
+		// back until a second shows up: a lone video is played instead
+		var held []provider.Track
+		queued := false
+		n, err := yprov.New().StreamTracksFromURL(link, func(ready []provider.Track) {
+			if !queued {
+				held = append(held, ready...)
+				if len(held) < 2 {
+					return
+				}
+				ready, held, queued = held, nil, true
+			}
+			p.mu.Lock()
+			p.queue = append(p.queue, ready...)
+			p.mu.Unlock()
+			p.updateQueueView()
+		}, func(done, listed int) {
+			if listed > 1 && done < listed {
+				p.notify(fmt.Sprintf("[gray]Loading playlist:[-] %d/%d", done, listed))
+			}
+		})
 		if err != nil {
-			p.updateNowPlaying(fmt.Sprintf("[red]Link error:[-] %v", err))
+			p.notify(fmt.Sprintf("[red]Link error:[-] %s", provider.Explain(err)))
 			return
 		}
-		if len(tracks) == 0 {
-			p.updateNowPlaying("[yellow]No tracks found in link[-]")
-			return
-		}
-		if len(tracks) == 1 {
-			go p.playTrack(tracks[0])
+		if !queued && len(held) == 1 {
+			go p.playTrack(held[0])
 			return
 		}
-		p.mu.Lock()
-		p.queue = append(p.queue, tracks...)
-		p.mu.Unlock()
-		p.updateQueueView()
-		p.updateNowPlaying(fmt.Sprintf("[green]+ Added playlist:[-] %d tracks", len(tracks)))
+		p.notify(fmt.Sprintf("[green]+ Added playlist:[-] %d tracks", n))
 		return
 	}
 
@@ -621,11 +1282,11 @@ func (p *player) handleLink(link string) {
 		sp := sprov.New()
 		tracks, err := sp.FetchTracksFromURL(link)
 		if err != nil {
-			p.updateNowPlaying(fmt.Sprintf("[red]Spotify error:[-] %v", err))
+			p.notify(fmt.Sprintf("[red]Spotify error:[-] %v", err))
 			return
 		}
 		if len(tracks) == 0 {
-			p.updateNowPlaying("[yellow]No tracks found in Spotify link[-]")
+			p.notify("[yellow]No tracks found in Spotify link[-]")
 			return
 		}
 
@@ -636,20 +1297,28 @@ func (p *player) handleLink(link string) {
 		p.updateQueueView()
 
 		if len(tracks) == 1 {
-			p.updateNowPlaying(fmt.Sprintf("[yellow]⚠ Spotify added (requires premium + auth):[-]\n%s", tracks[0].Title))
+			p.notify(fmt.Sprintf("[yellow]⚠ Spotify added (requires premium + auth):[-]\n%s", tracks[0].Title))
 		} else {
-			p.updateNowPlaying(fmt.Sprintf("[yellow]⚠ Spotify added (requires premium + auth):[-]\n%d items", len(tracks)))
+			p.notify(fmt.Sprintf("[yellow]⚠ Spotify added (requires premium + auth):[-]\n%d items", len(tracks)))
 		}
 		return
 	}
 
-	p.updateNowPlaying("[yellow]Unsupported link type[-]")
+	p.notify("[yellow]Unsupported link type[-]")
 }
 
 func (p *player) playTrack(track provider.Track) {
+	p.playTrackFrom(track, 0)
+}
+
+// playTrackFrom resolves and plays track starting at startPos seconds.
+func (p *player) playTrackFrom(track provider.Track, startPos float64) {
 	p.stop()
 
 	p.mu.Lock()
+	if p.retry != nil && p.retry.track.ID != track.ID {
+		p.retry = nil
+	}
 	if p.stopSpinner != nil {
 		close(p.stopSpinner)
 	}
@@ -677,7 +1346,12 @@ func (p *player) playTrack(track provider.Track) {
 	}()
 
 	go func() {
-		stream, err := p.yt.ResolveStream(track, provider.QualityAny)
+		defer p.recoverPanic()
+		stream, ok := p.takePrefetched(track)
+		var err error
+		if !ok {
+			stream, err = p.providers.ResolveStream(track, p.streamQuality())
+		}
 
 		p.mu.Lock()
 		if p.stopSpinner == stopCh {
@@ -687,92 +1361,206 @@ func (p *player) playTrack(track provider.Track) {
 		p.mu.Unlock()
 
 		if err != nil {
-			p.updateNowPlaying(fmt.Sprintf("[red]Resolve error:[-] %v", err))
+			if p.retrying(track) {
+				p.playbackFailed(track, startPos, err)
+				return
+			}
+			p.updateNowPlaying(fmt.Sprintf("[red]Resolve error:[-] %s", provider.Explain(err)))
 			return
 		}
 
-		device := os.Getenv("AUDICTL_DEVICE")
-		resample := os.Getenv("AUDICTL_RESAMPLE") == "1"
-		cmd, err := mpv.Start(stream.URL, track.Title, device, resample)
-		if err != nil {
-			p.updateNowPlaying(fmt.Sprintf("[red]mpv error:[-] %v", err))
+		if err := p.ensurePlayer(); err != nil {
+			p.updateNowPlaying(fmt.Sprintf("[red]Player error:[-] %v", err))
 			return
 		}
 
 		p.mu.Lock()
-		p.currentCmd = cmd
-		p.currentTrk = &track
-		p.playbackStart = time.Now()
-		p.paused = false
-		if p.stopProgress != nil {
-			close(p.stopProgress)
-		}
-		p.stopProgress = make(chan struct{})
-		stopProgressCh := p.stopProgress
+		video := p.video
+		b := p.backend
 		p.mu.Unlock()
+		if b == nil {
+			p.updateNowPlaying("[red]Player exited[-]")
+			return
+		}
 
-		dur := ""
-		if track.Duration > 0 {
-			dur = fmt.Sprintf(" [%d:%02d]", track.Duration/60, track.Duration%60)
+		opts := p.fileOptions(track, startPos)
+		if !video {
+			// Page URLs are resolved by mpv, which should pick the same format
+			opts.YtdlFormat = stream.Meta["ytdl-format"]
 		}
-		p.updateNowPlaying(fmt.Sprintf("[green]♪ Playing:[-]\n[white]%s[-]\n[gray]%s[-]%s", track.Title, track.Artist, dur))
-		p.updateQueueView()
+		if err := b.Load(playURL(track, stream, video, b.OpensPages()), opts); err != nil {
+			p.updateNowPlaying(fmt.Sprintf("[red]Player error:[-] %v", err))
+			return
+		}
+		// Pause carries over between files in the same player
+		_ = b.Resume()
 
-		// Start progress bar updater
-		go p.updateProgress(track, stopProgressCh)
+		p.startedTrack(track, opts.StartPos, stream.Chapters)
+	}()
+}
 
-		go func() {
-			_ = cmd.Wait()
-			p.mu.Lock()
-			wasCurrent := p.currentCmd == cmd
-			if wasCurrent {
-				p.currentCmd = nil
-				p.currentTrk = nil
-			}
-			p.mu.Unlock()
+// startedTrack updates the player once mpv is playing track, whether it was
+// loaded directly or followed the previous track gaplessly.
+func (p *player) startedTrack(track provider.Track, startPos float64, chapters []provider.Chapter) {
+	p.mu.Lock()
+	p.currentTrk = &track
+	p.playbackStart = time.Now().Add(-time.Duration(startPos * float64(time.Second)))
+	p.posBase = startPos
+	p.posAt = time.Now()
+	p.paused = false
+	p.shuffleNext = ""
+	if p.shuffle {
+		if p.shufflePlayed == nil {
+			p.shufflePlayed = map[string]bool{}
+		}
+		p.shufflePlayed[track.ID] = true
+	}
+	// mpv keeps loop points across files
+	looping := p.loopA >= 0 || p.loopB >= 0
+	m := p.mpvCtl()
+	p.segments = nil
+	p.chapters = nil
+	p.nowDetails = ""
+	p.streamKbps = 0
+	p.loopA, p.loopB = -1, -1
+	if p.stopProgress != nil {
+		close(p.stopProgress)
+	}
+	p.stopProgress = make(chan struct{})
+	stopProgressCh := p.stopProgress
+	p.mu.Unlock()
 
-			if wasCurrent {
-				p.updateNowPlaying("[gray]Track finished[-]")
-				time.Sleep(500 * time.Millisecond)
-				p.next()
-			}
-		}()
-	}()
+	p.updateNowPlaying(nowPlayingText(track))
+	p.updateQueueView()
+	p.writeNowPlaying(&track)
+	p.noteResumed(track, startPos)
+	p.app.QueueUpdateDraw(func() {
+		p.showPreview(nil)
+		p.setArt(nil)
+	})
+	go p.loadArt(track)
+	go p.fillRadio(track)
+
+	if looping && m != nil {
+		_ = m.SetABLoop(-1, -1)
+	}
+
+	// Start progress bar updater
+	go p.updateProgress(track, stopProgressCh)
+
+	if m != nil {
+		go p.followMetadata(m, track, stopProgressCh)
+	}
+
+	go p.loadSegments(track)
+	go p.loadChapters(track, chapters)
+
+	// Resolve the next track now so it starts without a gap
+	go p.prefetchNext()
+}
+
+// nowPlayingText is the Now Playing panel content for track.
+func nowPlayingText(track provider.Track) string {
+	dur := ""
+	if track.Duration > 0 {
+		dur = " [" + format.Duration(track.Duration) + "]"
+	}
+	return fmt.Sprintf("[green]♪ Playing:[-]\n[white]%s[-]\n[gray]%s[-]%s", track.Title, track.Artist, dur)
+}
+
+// playURL returns what the player should load for track's resolved stream.
+// Players that can't open web pages get the direct media URL when the
+// provider fell back to the page.
+func playURL(track provider.Track, stream provider.Stream, video, pages bool) string {
+	if !pages {
+		if direct := stream.Meta["direct"]; direct != "" {
+			return direct
+		}
+		return stream.URL
+	}
+	if video && track.Links["youtube"] != "" {
+		// Resolved streams are audio-only; let mpv pick a video format itself
+		return track.Links["youtube"]
+	}
+	return stream.URL
+}
+
+// fileOptions returns the per-file mpv options for track started at startPos.
+func (p *player) fileOptions(track provider.Track, startPos float64) mpv.FileOptions {
+	if startPos == 0 {
+		startPos = p.resumePosition(track)
+	}
+	opts := mpv.FileOptions{StartPos: startPos}
+	// Per-channel rules skip long intros/outros automatically
+	channel := track.Tags["channel"]
+	if channel == "" {
+		channel = track.Artist
+	}
+	if rule, ok := p.config().SkipRuleFor(channel); ok {
+		if startPos == 0 {
+			opts.StartPos = rule.SkipStart
+		}
+		opts.CutEnd = rule.SkipEnd
+	}
+	return opts
 }
 
 func (p *player) stop() {
 	p.mu.Lock()
-	cmd := p.currentCmd
-	p.currentCmd = nil
+	playing := p.currentTrk != nil
+	if playing {
+		p.recordPlay(*p.currentTrk, p.playbackStart)
+	}
 	p.currentTrk = nil
+	p.currentEntry = 0
+	p.appended = nil
 	if p.stopProgress != nil {
 		close(p.stopProgress)
 		p.stopProgress = nil
 	}
+	b := p.backend
 	p.mu.Unlock()
 
-	if cmd != nil {
-		_ = mpv.KillCmd(cmd)
+	if playing && b != nil {
+		// The player stays up, idle, for the next track
+		_ = b.Stop()
 	}
 
 	// Clear progress bar
 	p.app.QueueUpdateDraw(func() {
 		p.progressView.SetText("")
+		p.setArt(nil)
 	})
 }
 
+// next skips to the next track in the queue.
 func (p *player) next() {
+	p.advance(false)
+}
+
+// advance plays the track after the current one (see nextIndex).
+func (p *player) advance(auto bool) {
 	p.mu.Lock()
 	if len(p.queue) == 0 {
 		p.mu.Unlock()
-		p.updateNowPlaying("[yellow]Queue is empty - add songs with 'a'[-]")
+		p.notify("[yellow]Queue is empty - add songs with 'a'[-]")
 		return
 	}
-
-	p.queueIdx++
-	if p.queueIdx >= len(p.queue) {
-		p.queueIdx = 0
+	idx := p.nextIndex(auto)
+	if idx < 0 && p.radio && p.queueIdx >= 0 && p.queueIdx < len(p.queue) {
+		// Radio mode: go on with something like what just played
+		seed := p.queue[p.queueIdx]
+		p.mu.Unlock()
+		p.fillRadio(seed)
+		p.mu.Lock()
+		idx = p.nextIndex(auto)
 	}
+	if idx < 0 {
+		p.mu.Unlock()
+		p.notify("[gray]End of queue[-]")
+		return
+	}
+	p.queueIdx = idx
 	track := p.queue[p.queueIdx]
 	p.mu.Unlock()
 
@@ -783,7 +1571,7 @@ func (p *player) previous() {
 	p.mu.Lock()
 	if len(p.queue) == 0 {
 		p.mu.Unlock()
-		p.updateNowPlaying("[yellow]Queue is empty - add songs with 'a'[-]")
+		p.notify("[yellow]Queue is empty - add songs with 'a'[-]")
 		return
 	}
 
@@ -797,39 +1585,251 @@ func (p *player) previous() {
 	p.playTrack(track)
 }
 
+// insertNext places the selected search result right after the track that is
+// currently playing, so it is played next.
+func (p *player) insertNext() {
+	idx := p.resultsView.GetCurrentItem()
+	p.mu.Lock()
+	if idx < 0 || idx >= len(p.searchRes) {
+		p.mu.Unlock()
+		p.notify("[yellow]No result selected[-]")
+		return
+	}
+	track := p.searchRes[idx]
+	pos := 0
+	if len(p.queue) > 0 {
+		pos = p.queueIdx + 1
+	}
+	p.mu.Unlock()
+
+	p.insertIntoQueue(pos, track)
+	p.notify(fmt.Sprintf("[green]+ Playing next:[-] %s", track.Title))
+}
+
+// insertIntoQueue inserts track at position idx, clamped to the queue bounds.
+func (p *player) insertIntoQueue(idx int, track provider.Track) {
+	p.mu.Lock()
+	if idx < 0 {
+		idx = 0
+	}
+	if idx > len(p.queue) {
+		idx = len(p.queue)
+	}
+	p.queue = append(p.queue, provider.Track{})
+	copy(p.queue[idx+1:], p.queue[idx:])
+	p.queue[idx] = track
+	if idx <= p.queueIdx && len(p.queue) > 1 {
+		p.queueIdx++
+	}
+	p.mu.Unlock()
+
+	p.updateQueueView()
+}
+
+// removeFromQueue deletes the queue entry at idx. The queue position is
+// adjusted so that "next" still continues with the track that followed.
+func (p *player) removeFromQueue(idx int) {
+	p.mu.Lock()
+	if idx < 0 || idx >= len(p.queue) {
+		p.mu.Unlock()
+		return
+	}
+	title := p.queue[idx].Title
+	p.queue = append(p.queue[:idx], p.queue[idx+1:]...)
+	if idx <= p.queueIdx {
+		// -1 when the first entry goes: "next" plays the new first one
+		p.queueIdx--
+	}
+	if len(p.queue) == 0 {
+		p.queueIdx = -1
+	}
+	p.mu.Unlock()
+
+	p.updateQueueView()
+	p.notify(fmt.Sprintf("[yellow]- Removed:[-] %s", title))
+}
+
+// moveInQueue moves the queue entry at from to position to and keeps the
+// selection on the moved entry.
+func (p *player) moveInQueue(from, to int) {
+	p.mu.Lock()
+	if from < 0 || from >= len(p.queue) || to < 0 || to >= len(p.queue) || from == to {
+		p.mu.Unlock()
+		return
+	}
+	track := p.queue[from]
+	p.queue = append(p.queue[:from], p.queue[from+1:]...)
+	p.queue = append(p.queue[:to], append([]provider.Track{track}, p.queue[to:]...)...)
+	switch {
+	case p.queueIdx == from:
+		p.queueIdx = to
+	case from < p.queueIdx && to >= p.queueIdx:
+		p.queueIdx--
+	case from > p.queueIdx && to <= p.queueIdx:
+		p.queueIdx++
+	}
+	p.mu.Unlock()
+
+	p.updateQueueView()
+	p.app.QueueUpdateDraw(func() {
+		p.queueView.SetCurrentItem(to)
+	})
+}
+
 func (p *player) clearQueue() {
 	p.mu.Lock()
 	p.queue = []provider.Track{}
-	p.queueIdx = 0
+	p.queueIdx = -1
 	p.mu.Unlock()
 	p.updateQueueView()
-	p.updateNowPlaying("[green]Queue cleared[-]")
+	p.notify("[green]Queue cleared[-]")
 }
 
 func (p *player) updateQueueView() {
+	p.syncAppended()
+
 	p.mu.Lock()
 	queueCopy := make([]provider.Track, len(p.queue))
 	copy(queueCopy, p.queue)
 	currentTrk := p.currentTrk
+	title := p.queueTitle()
 	p.mu.Unlock()
 
 	p.app.QueueUpdateDraw(func() {
+		current := p.queueView.GetCurrentItem()
+		p.queueView.SetTitle(title)
 		p.queueView.Clear()
 		for i, track := range queueCopy {
 			prefix := "  "
 			if currentTrk != nil && track.ID == currentTrk.ID {
 				prefix = "► "
 			}
-			dur := ""
-			if track.Duration > 0 {
-				dur = fmt.Sprintf(" [%d:%02d]", track.Duration/60, track.Duration%60)
+			row := format.Row(p.rowFormat(format.DefaultQueueRow), i+1, escapeTrack(track))
+			p.queueView.AddItem(prefix+row+radioNote(track), "", 0, nil)
+		}
+		if current >= 0 && current < len(queueCopy) {
+			p.queueView.SetCurrentItem(current)
+		}
+	})
+}
+
+func (p *player) updateResultsView() {
+	p.mu.Lock()
+	resultsCopy := make([]provider.Track, len(p.searchRes))
+	copy(resultsCopy, p.searchRes)
+	query := p.lastQuery
+	title := p.resultsTitle()
+	badges := make([]string, len(resultsCopy))
+	for i, track := range resultsCopy {
+		badges[i] = p.resultBadge(track)
+	}
+	p.mu.Unlock()
+
+	p.app.QueueUpdateDraw(func() {
+		current := p.resultsView.GetCurrentItem()
+		p.resultsView.Clear()
+		p.resultsView.SetTitle(title)
+		for i, track := range resultsCopy {
+			prefix := badges[i]
+			if p.pins != nil && p.pins.IsPinned(query, track.ID) {
+				prefix += "[yellow]★[-] "
 			}
-			title := fmt.Sprintf("%s%d. %s%s", prefix, i+1, track.Title, dur)
-			p.queueView.AddItem(title, "", 0, nil)
+			row := format.Row(p.rowFormat(format.DefaultResultRow), i+1, escapeTrack(track))
+			p.resultsView.AddItem(prefix+row, "", 0, nil)
+		}
+		if current >= 0 && current < len(resultsCopy) {
+			p.resultsView.SetCurrentItem(current)
 		}
 	})
 }
 
+// config returns the current configuration. It may be swapped by
+// reloadConfig at any time, so callers should not hold on to it.
+func (p *player) config() *config.Config {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cfg
+}
+
+// reloadConfig re-reads the config file and applies it: the theme, layout,
+// row format, time display, album art, normalization, EQ, pitch
+// correction, low-data and radio modes and ducking. An invalid file keeps the previous
+// settings. Keys are built in. The backend, yt-dlp path, cookies, proxies,
+// quality, music directory, zones and Snapcast pipe are read at startup
+// only; SponsorBlock, skip rules, YouTube search and the now-playing
+// output are read as they are used.
+func (p *player) reloadConfig() {
+	cfg, err := config.Load()
+	if err != nil {
+		p.notify(fmt.Sprintf("[red]Config error:[-] %v\n[gray]Keeping previous settings[-]", err))
+		return
+	}
+	th, err := p.reloadTheme(cfg)
+	if err != nil {
+		p.notify(fmt.Sprintf("[red]Config error:[-] %v\n[gray]Keeping previous settings[-]", err))
+		return
+	}
+	p.mu.Lock()
+	old := p.cfg
+	p.cfg = cfg
+	p.showRemaining = cfg.TimeDisplay == "remaining"
+	p.normalize = cfg.Normalize
+	p.eq = loadEQ(cfg)
+	// Low data and radio are toggled with keys too; only a change in the
+	// file overrides the toggle
+	lowData := cfg.LowData != old.LowData && cfg.LowData != p.lowData
+	radio := cfg.Radio != old.Radio && cfg.Radio != p.radio
+	p.mu.Unlock()
+	if cfg.Duck != old.Duck || cfg.DuckMatch != old.DuckMatch {
+		p.restartDucking(cfg)
+	}
+	layout := loadLayout(cfg)
+
+	p.applyNormalize()
+	p.applyEQ()
+	p.applySpeed()
+	if lowData {
+		p.toggleLowData()
+	}
+	if radio {
+		p.toggleRadio()
+	}
+	p.app.QueueUpdateDraw(func() {
+		p.setTheme(th)
+		p.layout = layout
+		p.applyLayout()
+		p.setArtProtocol(artProtocol(cfg))
+	})
+	p.updateResultsView()
+	p.updateQueueView()
+	p.notify("[green]✓ Config reloaded[-]")
+}
+
+// checkYtDlp warns up front when yt-dlp is missing, rather than letting
+// the first search fail with an exec error.
+func (p *player) checkYtDlp() {
+	if _, err := yprov.CheckYtDlp(); err != nil {
+		p.notify(fmt.Sprintf("[red]YouTube unavailable:[-] %v", err))
+	}
+}
+
+// rowFormat returns the configured row template, or def when none is set.
+func (p *player) rowFormat(def string) string {
+	if cfg := p.config(); cfg.RowFormat != "" {
+		return cfg.RowFormat
+	}
+	return def
+}
+
+// escapeTrack escapes the text fields of t so titles containing [brackets]
+// aren't interpreted as tview color tags.
+func escapeTrack(t provider.Track) provider.Track {
+	t.Title = tview.Escape(t.Title)
+	t.Artist = tview.Escape(t.Artist)
+	t.Album = tview.Escape(t.Album)
+	return t
+}
+
 func (p *player) updateNowPlaying(text string) {
 	p.app.QueueUpdateDraw(func() {
 		p.nowView.SetText(text)
@@ -837,6 +1837,7 @@ func (p *player) updateNowPlaying(text string) {
 }
 
 func (p *player) updateProgress(track provider.Track, stopCh chan struct{}) {
+	defer p.recoverPanic()
 	if stopCh == nil || track.Duration <= 0 {
 		p.app.QueueUpdateDraw(func() {
 			p.progressView.SetText("")
@@ -847,18 +1848,39 @@ func (p *player) updateProgress(track provider.Track, stopCh chan struct{}) {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
+	ticks := 0
 	for {
 		select {
 		case <-stopCh:
 			return
 		case <-ticker.C:
+			ticks++
+			if ticks%10 == 0 {
+				// Catch up with seeks and pauses done in mpv
+				p.syncPosition(track)
+				p.refreshQueueTitle()
+				p.mu.Lock()
+				elapsed := p.position()
+				p.mu.Unlock()
+				p.tickNowPlaying(track, elapsed)
+				p.showChapter(track, elapsed)
+			}
 			p.mu.Lock()
-			if p.currentCmd == nil || p.currentTrk == nil {
+			if p.currentTrk == nil {
 				p.mu.Unlock()
 				return
 			}
-			elapsed := time.Since(p.playbackStart).Seconds()
+			elapsed := p.position()
 			total := float64(track.Duration)
+			showRemaining := p.showRemaining
+			speed := p.speed
+			var marks []float64
+			for _, m := range []float64{p.loopA, p.loopB} {
+				if m >= 0 && total > 0 {
+					marks = append(marks, m/total)
+				}
+			}
+			chapters := p.chapterMarks(track)
 			p.mu.Unlock()
 
 			// Clamp elapsed to 0-total
@@ -868,56 +1890,114 @@ func (p *player) updateProgress(track provider.Track, stopCh chan struct{}) {
 			if elapsed > total {
 				elapsed = total
 			}
-			// Calculate progress bar - use full width of box
-			_, _, width, _ := p.progressView.GetRect()
-			barWidth := width - 4 // Account for borders and padding
-			if barWidth < 10 {
-				barWidth = 10
+			position := fmt.Sprintf("%d:%02d", int(elapsed)/60, int(elapsed)%60)
+			if showRemaining {
+				left := int(total - elapsed)
+				position = fmt.Sprintf("-%d:%02d", left/60, left%60)
 			}
-
-			progress := int((elapsed / total) * float64(barWidth))
-			if progress > barWidth {
-				progress = barWidth
-			}
-
-			// Build progress bar with colored sections
-			filledBar := ""
-			for i := 0; i < progress; i++ {
-				filledBar += "█" // Solid blocks for filled portion
-			}
-
-			remainingBar := ""
-			for i := progress; i < barWidth; i++ {
-				remainingBar += "·" // Dots for unfilled portion
-			}
-
-			elapsedMin := int(elapsed) / 60
-			elapsedSec := int(elapsed) % 60
 			totalMin := track.Duration / 60
 			totalSec := track.Duration % 60
 			percentage := int((elapsed / total) * 100)
+			suffix := fmt.Sprintf(" %d%% %s / %d:%02d", percentage, position, totalMin, totalSec)
+			if speed != 1 {
+				suffix += fmt.Sprintf(" %gx", speed)
+			}
 
-			progressText := fmt.Sprintf("[aqua:black:b]%s[-:black] %s %d%% %d:%02d / %d:%02d (%d%%)",
-				filledBar, remainingBar, percentage, elapsedMin, elapsedSec, totalMin, totalSec, percentage)
-
+			// Size the bar on the UI goroutine so it follows terminal resizes
 			p.app.QueueUpdateDraw(func() {
-				p.progressView.SetText(progressText)
+				_, _, width, _ := p.progressView.GetInnerRect()
+				p.barWidth = max(width-tview.TaggedStringWidth(suffix), 10)
+				p.progressView.SetText(progressBar(width, elapsed/total, marks, chapters, suffix))
 			})
 		}
 	}
 }
 
-func (p *player) forceQuit() {
-	// Force quit everything within 1 second
-	go func() {
-		p.mu.Lock()
-		if p.currentCmd != nil && p.currentCmd.Process != nil {
-			// Kill the mpv process immediately
-			_ = p.currentCmd.Process.Kill()
+// progressBar renders a bar filled to frac (0-1) with markers at marks
+// (0-1) and lighter ones where chapters (0-1) start, followed by suffix,
+// sized to fit exactly into width cells.
+func progressBar(width int, frac float64, marks, chapters []float64, suffix string) string {
+	barWidth := width - tview.TaggedStringWidth(suffix)
+	if barWidth < 10 {
+		barWidth = 10
+	}
+
+	progress := int(frac * float64(barWidth))
+	if progress > barWidth {
+		progress = barWidth
+	}
+	if progress < 0 {
+		progress = 0
+	}
+
+	// Solid blocks for the filled portion, dots for the rest
+	filledBar := strings.Repeat("█", progress)
+	remainingBar := strings.Repeat("·", barWidth-progress)
+	if len(marks) == 0 && len(chapters) == 0 {
+		return fmt.Sprintf("[aqua::b]%s[-::-]%s%s", filledBar, remainingBar, suffix)
+	}
+
+	cells := func(at []float64) []bool {
+		is := make([]bool, barWidth)
+		for _, m := range at {
+			i := int(m * float64(barWidth))
+			if i >= barWidth {
+				i = barWidth - 1
+			}
+			if i >= 0 {
+				is[i] = true
+			}
 		}
-		p.mu.Unlock()
+		return is
+	}
+	isMark, isChapter := cells(marks), cells(chapters)
+	var b strings.Builder
+	style := ""
+	for i := 0; i < barWidth; i++ {
+		cell, cellStyle := "·", "[-::-]"
+		switch {
+		case isMark[i]:
+			cell, cellStyle = "┃", "[yellow::b]"
+		case isChapter[i]:
+			cell, cellStyle = "│", "[white::-]"
+		case i < progress:
+			cell, cellStyle = "█", "[aqua::b]"
+		}
+		if cellStyle != style {
+			b.WriteString(cellStyle)
+			style = cellStyle
+		}
+		b.WriteString(cell)
+	}
+	if style != "[-::-]" {
+		b.WriteString("[-::-]")
+	}
+	return b.String() + suffix
+}
+
+// suspend hands the terminal back to the shell like Ctrl-Z in any other
+// program. mpv runs in its own process group, so playback keeps going, and
+// the screen is restored once the shell resumes us with fg.
+func (p *player) suspend() {
+	if !proc.CanSuspend {
+		p.showNotice("[yellow]Suspending is not supported on this system[-]")
+		return
+	}
+	p.app.Suspend(func() {
+		_ = proc.SuspendSelf()
+	})
+}
 
-		// Stop the app
+// forceQuit quits as Ctrl+C does, logging the playing track and stopping
+// mpv and the other helpers before the UI. Should any of that hang, the
+// process exits anyway after a second.
+func (p *player) forceQuit() {
+	// Keys pressed meanwhile would act on a player being torn down
+	p.app.QueueUpdate(func() {
+		p.app.SetInputCapture(func(*tcell.EventKey) *tcell.EventKey { return nil })
+	})
+	go func() {
+		p.cleanup()
 		p.app.Stop()
 	}()
 
@@ -927,7 +2007,171 @@ func (p *player) forceQuit() {
 	})
 }
 
+func (p *player) toggleMute() {
+	p.mu.Lock()
+	muted := !p.muted
+	p.mu.Unlock()
+	// Set rather than cycled, so the player can't end up the other way
+	// round from p.muted
+	b := p.out()
+	if b == nil || b.SetMute(muted) != nil {
+		p.notify("[yellow]Nothing is playing[-]")
+		return
+	}
+	p.mu.Lock()
+	p.muted = muted
+	p.mu.Unlock()
+	if muted {
+		p.notify("[yellow]🔇 Muted[-]")
+	} else {
+		p.notify("[green]🔊 Unmuted[-]")
+	}
+}
+
+// toggleNormalize turns loudness normalization on or off for this session.
+func (p *player) toggleNormalize() {
+	if msg := p.mpvMissing("Normalization"); msg != "" {
+		p.notify(msg)
+		return
+	}
+	if p.config().BitPerfect {
+		p.notify("[yellow]Normalization is disabled in bit-perfect mode[-]")
+		return
+	}
+	p.mu.Lock()
+	p.normalize = !p.normalize
+	on := p.normalize
+	p.mu.Unlock()
+
+	p.applyNormalize()
+	if on {
+		mode, _ := p.config().Normalization()
+		p.notify(fmt.Sprintf("[green]Normalization on[-] [gray](%s)[-]", mode))
+	} else {
+		p.notify("[yellow]Normalization off[-]")
+	}
+}
+
+// applyNormalize pushes the current normalization setting to mpv.
+func (p *player) applyNormalize() {
+	p.mu.Lock()
+	on := p.normalize
+	m := p.mpvCtl()
+	p.mu.Unlock()
+	if m == nil {
+		// ensurePlayer applies it once mpv is up
+		return
+	}
+	mode, target := p.config().Normalization()
+	if !on || p.config().BitPerfect {
+		mode = ""
+	}
+	_ = m.Normalize(mode, target)
+}
+
+// toggleVideo switches between audio-only playback and an mpv video window.
+// Turning video on reloads the current track at the same position since the
+// audio-only stream has no video to show.
+func (p *player) toggleVideo() {
+	if msg := p.mpvMissing("The video window"); msg != "" {
+		p.notify(msg)
+		return
+	}
+	p.mu.Lock()
+	p.video = !p.video
+	video := p.video
+	var track provider.Track
+	playing := p.currentTrk != nil
+	if playing {
+		track = *p.currentTrk
+	}
+	m := p.mpvCtl()
+	p.mu.Unlock()
+
+	if m != nil {
+		_ = m.SetVideo(video)
+	}
+	if !playing || !video {
+		if video {
+			p.notify("[green]Video window on[-] for the next track")
+		} else {
+			p.notify("[yellow]Video window off[-]")
+		}
+		return
+	}
+
+	p.playTrackFrom(track, p.currentPosition())
+}
+
+// startDucking lowers the volume whenever a matching D-Bus event (a desktop
+// notification by default) fires, and restores it a few seconds later.
+func (p *player) startDucking(match string) {
+	stop, err := duck.Watch(match, p.duck)
+	if err != nil {
+		debuglog.Printf("app", "duck: %v", err)
+		return
+	}
+	p.mu.Lock()
+	p.stopDuck = stop
+	p.mu.Unlock()
+}
+
+// restartDucking stops ducking, then starts it again when cfg turns it on.
+func (p *player) restartDucking(cfg *config.Config) {
+	p.mu.Lock()
+	stop := p.stopDuck
+	p.stopDuck = nil
+	p.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+	if cfg.Duck {
+		p.startDucking(cfg.DuckMatch)
+	}
+}
+
+func (p *player) duck() {
+	const restoreAfter = 3 * time.Second
+
+	p.mu.Lock()
+	if p.duckTimer != nil {
+		// Another notification while ducked only extends the window
+		p.duckTimer.Reset(restoreAfter)
+		p.mu.Unlock()
+		return
+	}
+	m := p.mpvCtl()
+	if p.currentTrk == nil || p.cfg.BitPerfect || m == nil {
+		p.mu.Unlock()
+		return
+	}
+	// Claim the window before letting go of the lock, so a notification
+	// arriving during the IPC call doesn't duck again
+	p.duckTimer = time.AfterFunc(restoreAfter, func() {
+		p.mu.Lock()
+		p.duckTimer = nil
+		p.mu.Unlock()
+		_ = m.Duck(1)
+	})
+	p.mu.Unlock()
+
+	// mpv IPC blocks, so it runs outside the lock
+	_ = m.Duck(0.3)
+}
+
+// cleanup runs once on the way out: it logs the playing track, stops
+// mpv, ducking and the action processor, and waits for the history to be
+// written. The visualizer is UI state; main stops it once the UI is gone.
 func (p *player) cleanup() {
-	p.stop()
-	close(p.actionChan)
+	p.cleanupOnce.Do(func() {
+		p.mu.Lock()
+		if p.stopDuck != nil {
+			p.stopDuck()
+		}
+		p.mu.Unlock()
+		p.stop()
+		p.shutdownPlayer()
+		close(p.done)
+		p.writes.Wait()
+	})
 }
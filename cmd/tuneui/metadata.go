@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"audictl/internal/mpv"
+	"audictl/internal/provider"
+)
+
+// metadataInterval is how often the Now Playing panel asks mpv about the
+// playing file. Internet radio changes its ICY title with every song.
+const metadataInterval = 2 * time.Second
+
+// followMetadata adds what mpv knows about the playing track to the Now
+// Playing panel: codec, bitrate and sample rate, the sample formats when
+// bit-perfect output is on, and the song on air for live streams. It
+// redraws the panel only when something changes, until stop is closed.
+func (p *player) followMetadata(m *mpv.Instance, track provider.Track, stop chan struct{}) {
+	bitPerfect := p.config().BitPerfect
+	ticker := time.NewTicker(metadataInterval)
+	defer ticker.Stop()
+
+	var shown mpv.Metadata
+	formats := ""
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		md, err := m.Metadata()
+		if err != nil {
+			continue
+		}
+		var paused bool
+		if v, err := m.GetProperty("pause"); err == nil {
+			paused, _ = v.(bool)
+		}
+		p.mu.Lock()
+		if p.currentTrk != nil && p.currentTrk.ID == track.ID {
+			if md.Bitrate > 0 {
+				p.streamKbps = md.Bitrate / 1000
+			}
+			if !paused {
+				p.countData(track, metadataInterval)
+			}
+		}
+		p.mu.Unlock()
+		changed := md.Codec != shown.Codec || md.SampleRate != shown.SampleRate ||
+			md.Channels != shown.Channels || md.StreamTitle != shown.StreamTitle ||
+			md.Station != shown.Station || (shown.Bitrate == 0 && md.Bitrate > 0)
+		if bitPerfect && formats == "" {
+			if formats = audioFormatLine(m); formats != "" {
+				changed = true
+			}
+		}
+		if !changed {
+			// VBR bitrates drift all the time; don't redraw for that alone
+			continue
+		}
+
+		p.mu.Lock()
+		current := p.currentTrk != nil && p.currentTrk.ID == track.ID
+		p.mu.Unlock()
+		if !current {
+			return
+		}
+		if md.StreamTitle != shown.StreamTitle && md.StreamTitle != "" {
+			p.writeNowPlaying(onAir(track, md))
+		}
+		shown = md
+
+		details := ""
+		if md.StreamTitle != "" {
+			details += fmt.Sprintf("\n[aqua]♫ %s[-]", md.StreamTitle)
+		}
+		if line := metadataLine(md); line != "" {
+			details += "\n[gray]" + line + "[-]"
+		}
+		if formats != "" {
+			details += "\n" + formats
+		}
+		p.mu.Lock()
+		p.nowDetails = details
+		p.mu.Unlock()
+		p.updateNowPlaying(nowPlayingText(track) + details + p.chapterLine(track))
+	}
+}
+
+// metadataLine describes the audio of the playing file, e.g.
+// "opus · 128 kbps · 48 kHz · stereo".
+func metadataLine(md mpv.Metadata) string {
+	var parts []string
+	if md.Station != "" {
+		parts = append(parts, md.Station)
+	}
+	if md.Codec != "" {
+		parts = append(parts, md.Codec)
+	}
+	if md.Bitrate > 0 {
+		parts = append(parts, fmt.Sprintf("%d kbps", (md.Bitrate+500)/1000))
+	}
+	if md.SampleRate > 0 {
+		parts = append(parts, fmt.Sprintf("%g kHz", float64(md.SampleRate)/1000))
+	}
+	switch md.Channels {
+	case 0:
+	case 1:
+		parts = append(parts, "mono")
+	case 2:
+		parts = append(parts, "stereo")
+	default:
+		parts = append(parts, fmt.Sprintf("%dch", md.Channels))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// audioFormatLine compares the source and output sample formats once mpv
+// has opened the device, flagging any conversion. It returns "" until
+// then.
+func audioFormatLine(m *mpv.Instance) string {
+	in, out, err := m.AudioFormats()
+	if err != nil || out.SampleRate == 0 {
+		return ""
+	}
+	status := "[green]bit-perfect[-]"
+	if in.SampleRate != out.SampleRate || in.Format != out.Format {
+		status = "[red]converted[-]"
+	}
+	return fmt.Sprintf("[gray]%s → %s[-] %s", in, out, status)
+}
+
+// onAir returns track as the now-playing overlay should show it while a
+// live stream plays the song named in its ICY title.
+func onAir(track provider.Track, md mpv.Metadata) *provider.Track {
+	t := track
+	t.Title = md.StreamTitle
+	if artist, title, ok := strings.Cut(md.StreamTitle, " - "); ok {
+		t.Artist, t.Title = strings.TrimSpace(artist), strings.TrimSpace(title)
+	}
+	return &t
+}
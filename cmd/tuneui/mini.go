@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// miniState is the content of the mini mode line.
+type miniState struct {
+	status  playerStatus
+	title   string // "" when nothing is playing
+	elapsed float64
+	total   float64 // 0 for live streams
+}
+
+// miniMode reports whether the TUI is shrunk to the mini mode line.
+func (p *player) miniMode() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.miniOn
+}
+
+// toggleMini switches between the full TUI and mini mode: a single line
+// with the playing track, its progress and the volume, for a tmux pane or
+// a tiny terminal. Every key keeps working; the focus moves to the queue
+// so that typing doesn't go to the search box. Called from the UI
+// goroutine.
+func (p *player) toggleMini() {
+	p.mu.Lock()
+	p.miniOn = !p.miniOn
+	on := p.miniOn
+	p.mu.Unlock()
+
+	p.fillRoot()
+	if on {
+		p.focusIdx = len(p.focusables) - 1
+		p.app.SetFocus(p.queueView)
+	}
+	p.refreshStatus()
+}
+
+// fillRoot lays out the screen: the mini mode line, or the panels with
+// the debug console (when shown) and the status bar below them.
+func (p *player) fillRoot() {
+	p.root.Clear()
+	if p.miniMode() {
+		p.root.AddItem(p.miniView, 0, 1, false)
+		return
+	}
+	p.root.AddItem(p.mainFlex, 0, 1, true)
+	if p.consoleOn {
+		p.root.AddItem(p.consoleView, consoleHeight, 0, false)
+	}
+	p.root.AddItem(p.statusBar, 1, 0, false)
+}
+
+// miniLine reads what the mini mode line shows besides st.
+func (p *player) miniLine(st playerStatus) miniState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	line := miniState{status: st}
+	if t := p.currentTrk; t != nil {
+		line.title = t.Title
+		if t.Artist != "" {
+			line.title = t.Artist + " - " + t.Title
+		}
+		line.total = float64(t.Duration)
+		line.elapsed = max(p.position(), 0)
+		if line.total > 0 {
+			line.elapsed = min(line.elapsed, line.total)
+		}
+	}
+	return line
+}
+
+// drawMini renders the mini mode line, or the current notice in its place.
+// Called from the UI goroutine.
+func (p *player) drawMini() {
+	if !p.miniMode() {
+		return
+	}
+	if notice := strings.TrimSpace(p.noticeView.GetText(false)); notice != "" {
+		p.miniView.SetText(" " + notice)
+		return
+	}
+	line := p.mini
+	_, _, width, _ := p.miniView.GetInnerRect()
+
+	suffix := ""
+	if line.title != "" {
+		suffix = " " + clock(line.elapsed)
+		if line.total > 0 {
+			suffix += " / " + clock(line.total)
+		}
+	}
+	if line.status.volume != "" {
+		suffix += " │ " + line.status.volume
+	}
+	left := " " + line.status.icon + " "
+	if line.title == "" {
+		p.miniView.SetText(left + "[gray]Nothing playing[-]" + suffix)
+		return
+	}
+
+	// Leave room for at least a short progress bar
+	room := width - tview.TaggedStringWidth(left+suffix) - 12
+	left += tview.Escape(truncate(line.title, max(room, 10))) + " "
+	if line.total <= 0 {
+		p.miniView.SetText(left + suffix)
+		return
+	}
+	barWidth := width - tview.TaggedStringWidth(left)
+	p.miniView.SetText(left + progressBar(barWidth, line.elapsed/line.total, nil, nil, suffix))
+}
+
+// truncate shortens s to at most n cells, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	if tview.TaggedStringWidth(tview.Escape(s)) <= n {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && tview.TaggedStringWidth(tview.Escape(string(runes))) > n-1 {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// centered wraps p in a flex layout that keeps it centered at the given size.
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+}
+
+// showModal displays content centered above the main layout and focuses it,
+// or over the whole screen when width is 0. Must be called from the UI
+// goroutine.
+func (p *player) showModal(name string, content tview.Primitive, width, height int) {
+	if !p.modalOpen() {
+		p.modalPrev = p.app.GetFocus()
+	}
+	page := content
+	if width > 0 {
+		page = centered(content, width, height)
+	}
+	p.pages.AddPage(name, page, true, true)
+	p.app.SetFocus(content)
+}
+
+// hideModal removes the named modal and restores the previous focus.
+// Must be called from the UI goroutine.
+func (p *player) hideModal(name string) {
+	p.pages.RemovePage(name)
+	if !p.modalOpen() && p.modalPrev != nil {
+		p.app.SetFocus(p.modalPrev)
+		p.modalPrev = nil
+	}
+}
+
+// modalOpen reports whether any modal is shown above the main layout.
+func (p *player) modalOpen() bool {
+	return p.pages.GetPageCount() > 1
+}
+
+// prompt shows a single-line input modal and calls done with the entered
+// text when Enter is pressed. Esc cancels.
+func (p *player) prompt(title, label, initial string, done func(text string)) {
+	const name = "prompt"
+	input := tview.NewInputField()
+	input.SetLabel(label)
+	input.SetText(initial)
+	input.SetFieldWidth(0)
+	input.SetFieldBackgroundColor(p.theme.Field)
+	input.SetBorder(true).SetTitle(" " + title + " ")
+	input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			text := input.GetText()
+			p.hideModal(name)
+			done(text)
+		case tcell.KeyEsc:
+			p.hideModal(name)
+		}
+	})
+	p.showModal(name, input, 60, 3)
+}
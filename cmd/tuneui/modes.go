@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math/rand/v2"
+
+	"audictl/internal/provider"
+)
+
+// repeatMode is what happens when a track ends.
+type repeatMode int
+
+const (
+	repeatAll repeatMode = iota // go on with the next track, wrapping around
+	repeatOne                   // play the same track again
+	repeatOff                   // stop after the last track
+)
+
+func (r repeatMode) String() string {
+	switch r {
+	case repeatOne:
+		return "one"
+	case repeatOff:
+		return "off"
+	}
+	return "all"
+}
+
+// cycleRepeat switches to the next repeat mode: all, one, off.
+func (p *player) cycleRepeat() {
+	p.mu.Lock()
+	p.repeat = (p.repeat + 1) % 3
+	p.mu.Unlock()
+	p.syncAppended()
+}
+
+// toggleShuffle turns playing the queue in random order on or off.
+func (p *player) toggleShuffle() {
+	p.mu.Lock()
+	p.shuffle = !p.shuffle
+	p.shuffleNext = ""
+	p.shufflePlayed = map[string]bool{}
+	if p.shuffle && p.currentTrk != nil {
+		p.shufflePlayed[p.currentTrk.ID] = true
+	}
+	p.mu.Unlock()
+	p.syncAppended()
+}
+
+// toggleRadio turns radio mode on or off. Turned on at the end of the
+// queue, it adds related tracks right away.
+func (p *player) toggleRadio() {
+	p.mu.Lock()
+	p.radio = !p.radio
+	on := p.radio
+	var seed *provider.Track
+	if on && p.currentTrk != nil {
+		t := *p.currentTrk
+		seed = &t
+	}
+	p.mu.Unlock()
+	p.syncAppended()
+	if !on {
+		p.notify("[yellow]📻 Radio off[-]")
+		return
+	}
+	p.notify("[green]📻 Radio on:[-] the queue goes on with related tracks")
+	if seed != nil {
+		go p.fillRadio(*seed)
+	}
+}
+
+// nextIndex returns the queue position of the track to play after the
+// current one, or -1 when there is none. auto is set when the current
+// track ended by itself, which repeats it in repeatOne mode; skipping
+// with "next" moves on regardless. Must be called with p.mu held.
+func (p *player) nextIndex(auto bool) int {
+	n := len(p.queue)
+	if n == 0 {
+		return -1
+	}
+	if auto && p.repeat == repeatOne && p.queueIdx >= 0 && p.queueIdx < n {
+		return p.queueIdx
+	}
+	if p.shuffle {
+		return p.shuffleIndex()
+	}
+	if p.queueIdx+1 < n {
+		return p.queueIdx + 1
+	}
+	if p.repeat == repeatOff || p.radio {
+		// Radio mode adds more instead of starting over
+		return -1
+	}
+	return 0
+}
+
+// shuffleIndex picks a random queued track that hasn't played since
+// shuffle was turned on, starting over once all have when repeating. The
+// pick is kept until it starts, so prefetching and "next" agree on it.
+// Must be called with p.mu held.
+func (p *player) shuffleIndex() int {
+	var left []int
+	for i, t := range p.queue {
+		if t.ID == p.shuffleNext {
+			return i
+		}
+		if !p.shufflePlayed[t.ID] {
+			left = append(left, i)
+		}
+	}
+	if len(left) == 0 {
+		if p.repeat == repeatOff || p.radio {
+			return -1
+		}
+		// Start another round, avoiding the track that just played
+		p.shufflePlayed = map[string]bool{}
+		for i, t := range p.queue {
+			if p.currentTrk == nil || t.ID != p.currentTrk.ID || len(p.queue) == 1 {
+				left = append(left, i)
+			}
+		}
+	}
+	idx := left[rand.IntN(len(left))]
+	p.shuffleNext = p.queue[idx].ID
+	return idx
+}
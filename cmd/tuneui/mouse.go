@@ -0,0 +1,86 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// setupMouse adds the mouse actions beyond what tview's lists do on their
+// own: clicking the progress bar seeks, the scroll wheel over Now Playing
+// changes the volume, and dragging a queue row moves the track.
+func (p *player) setupMouse() {
+	p.dragFrom = -1
+
+	p.progressView.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action != tview.MouseLeftClick {
+			return action, event
+		}
+		x, _ := event.Position()
+		rx, _, _, _ := p.progressView.GetInnerRect()
+		if x-rx < 0 || x-rx >= p.barWidth {
+			return action, event
+		}
+		p.mu.Lock()
+		total := 0
+		if p.currentTrk != nil {
+			total = p.currentTrk.Duration
+		}
+		p.mu.Unlock()
+		if total > 0 {
+			// Aim at the middle of the clicked cell
+			frac := (float64(x-rx) + 0.5) / float64(p.barWidth)
+			go p.seekTo(frac * float64(total))
+		}
+		return action, nil
+	})
+
+	p.nowPanel.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		switch action {
+		case tview.MouseScrollUp:
+			p.act(actionVolumeUp)
+			return action, nil
+		case tview.MouseScrollDown:
+			p.act(actionVolumeDown)
+			return action, nil
+		}
+		return action, event
+	})
+
+	p.queueView.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		switch action {
+		case tview.MouseLeftDown:
+			p.dragFrom = p.queueRowAt(event)
+		case tview.MouseMove:
+			// Follow the drag with the selection
+			if p.dragFrom >= 0 && event.Buttons()&tcell.ButtonPrimary != 0 {
+				if row := p.queueRowAt(event); row >= 0 {
+					p.queueView.SetCurrentItem(row)
+				}
+			}
+		case tview.MouseLeftUp:
+			from, to := p.dragFrom, p.queueRowAt(event)
+			p.dragFrom = -1
+			if from >= 0 && to >= 0 && from != to {
+				go p.moveInQueue(from, to)
+				return action, nil
+			}
+		}
+		return action, event
+	})
+}
+
+// queueRowAt returns the queue position of the row under the mouse, or -1
+// when it isn't over a track.
+func (p *player) queueRowAt(event *tcell.EventMouse) int {
+	x, y := event.Position()
+	rx, ry, width, height := p.queueView.GetInnerRect()
+	if x < rx || x >= rx+width || y < ry || y >= ry+height {
+		return -1
+	}
+	offset, _ := p.queueView.GetOffset()
+	row := y - ry + offset
+	if row >= p.queueView.GetItemCount() {
+		return -1
+	}
+	return row
+}
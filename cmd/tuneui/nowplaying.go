@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"audictl/internal/artwork"
+	"audictl/internal/config"
+	"audictl/internal/format"
+	"audictl/internal/provider"
+)
+
+// writeNowPlaying updates the now-playing files configured for overlays
+// to track, or clears them when track is nil. It returns immediately; a
+// slow art download is dropped if another track starts meanwhile.
+func (p *player) writeNowPlaying(track *provider.Track) {
+	out := p.config().NowPlaying
+	if out.File == "" && out.Art == "" {
+		return
+	}
+	p.mu.Lock()
+	p.nowPlayingSeq++
+	seq := p.nowPlayingSeq
+	lowData := p.lowData
+	p.mu.Unlock()
+
+	go func() {
+		var art []byte
+		if track != nil && out.Art != "" && !lowData {
+			art, _ = coverArt(*track)
+		}
+
+		p.nowPlayingMu.Lock()
+		defer p.nowPlayingMu.Unlock()
+		p.mu.Lock()
+		stale := seq != p.nowPlayingSeq
+		p.mu.Unlock()
+		if stale {
+			return
+		}
+
+		if out.File != "" {
+			text := ""
+			if track != nil {
+				text = nowPlayingLine(out, *track, 0)
+			}
+			_ = writeFileAtomic(expandHome(out.File), []byte(text))
+		}
+		if out.Art != "" {
+			if art != nil {
+				_ = writeFileAtomic(expandHome(out.Art), art)
+			} else {
+				_ = os.Remove(expandHome(out.Art))
+			}
+		}
+	}()
+}
+
+// tickNowPlaying rewrites the now-playing file at elapsed seconds into
+// track, when its format shows the position.
+func (p *player) tickNowPlaying(track provider.Track, elapsed float64) {
+	out := p.config().NowPlaying
+	if out.File == "" || !strings.Contains(out.Format, "{elapsed}") {
+		return
+	}
+	p.nowPlayingMu.Lock()
+	defer p.nowPlayingMu.Unlock()
+	p.mu.Lock()
+	current := p.currentTrk != nil && p.currentTrk.ID == track.ID
+	p.mu.Unlock()
+	if current {
+		_ = writeFileAtomic(expandHome(out.File), []byte(nowPlayingLine(out, track, elapsed)))
+	}
+}
+
+// nowPlayingLine is the now-playing file's line for track at elapsed
+// seconds into it.
+func nowPlayingLine(out config.NowPlayingOutput, track provider.Track, elapsed float64) string {
+	tmpl := out.Format
+	if tmpl == "" {
+		tmpl = config.DefaultNowPlayingFormat
+	}
+	secs := int(elapsed)
+	tmpl = strings.ReplaceAll(tmpl, "{elapsed}", fmt.Sprintf("%d:%02d", secs/60, secs%60))
+	text := format.Row(tmpl, 0, track)
+	if out.MaxWidth > 0 {
+		text = truncate(text, out.MaxWidth)
+	}
+	return text + "\n"
+}
+
+// coverArt fetches the cover art of track and re-encodes it as PNG.
+func coverArt(track provider.Track) ([]byte, error) {
+	img, err := artwork.Fetch(track)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFileAtomic replaces path with data through a temporary file, so
+// overlays polling the file never read it half written.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/provider"
+)
+
+func TestNextTrackFollowsGaplessly(t *testing.T) {
+	h := newHarness(t)
+	h.p.repeat = repeatOff // else a is lined up again after c
+	h.enqueue(h.tracks("a", "b", "c")...)
+	h.playQueue()
+
+	// The next track is resolved ahead and handed to the player
+	h.waitFor("b appended", func() bool { return len(h.fake().Loads()) == 2 })
+	h.fake().Finish()
+	h.waitPlaying("mock:b")
+	if n := h.mock.Count("resolve mock:b"); n != 1 {
+		t.Errorf("b resolved %d times, want once", n)
+	}
+	h.waitFor("c appended", func() bool { return len(h.fake().Loads()) == 3 })
+	h.fake().Finish()
+	h.waitPlaying("mock:c")
+
+	want := []string{"mock://mock:a", "mock://mock:b", "mock://mock:c"}
+	if got := h.fake().Loads(); !slices.Equal(got, want) {
+		t.Errorf("loads = %q, want %q", got, want)
+	}
+	h.p.mu.Lock()
+	idx := h.p.queueIdx
+	h.p.mu.Unlock()
+	if idx != 2 {
+		t.Errorf("queue index = %d, want 2", idx)
+	}
+}
+
+func TestLowDataLoadsNextTrackWhenDue(t *testing.T) {
+	h := newHarness(t)
+	h.p.lowData = true
+	h.enqueue(h.tracks("a", "b")...)
+	h.playQueue()
+
+	time.Sleep(100 * time.Millisecond)
+	if n := h.mock.Count("resolve mock:b"); n != 0 {
+		t.Fatalf("b resolved %d times ahead in low-data mode", n)
+	}
+	h.fake().Finish()
+	h.waitPlaying("mock:b")
+	if got := h.fake().Loads(); len(got) != 2 {
+		t.Errorf("loads = %q, want a then b", got)
+	}
+}
+
+func TestToggleLowDataDropsAppendedTrack(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a", "b")...)
+	h.playQueue()
+	h.waitFor("b appended", func() bool { return len(h.fake().Loads()) == 2 })
+
+	h.p.act(actionToggleLowData)
+	h.waitFor("low-data mode", func() bool { return strings.Contains(h.p.status().modes, "Low data") })
+	h.p.mu.Lock()
+	appended, prefetch := h.p.appended, h.p.prefetch
+	h.p.mu.Unlock()
+	if appended != nil || prefetch != nil {
+		t.Fatalf("appended = %v, prefetch = %v after turning low-data mode on", appended, prefetch)
+	}
+
+	// b was taken back from the player, so it is resolved again when due
+	h.fake().Finish()
+	h.waitPlaying("mock:b")
+	if n := h.mock.Count("resolve mock:b"); n != 2 {
+		t.Errorf("b resolved %d times, want twice", n)
+	}
+}
+
+func TestEndOfQueue(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		repeat int // presses of r
+		want   string
+	}{
+		{"repeat all", 0, "mock:a"},
+		{"repeat one", 1, "mock:b"},
+		{"repeat off", 2, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newHarness(t)
+			h.p.lowData = true // loads each track when due, at a known time
+			h.enqueue(h.tracks("a", "b")...)
+			h.playQueue()
+			for range tc.repeat {
+				h.press(tcell.KeyRune, 'r')
+			}
+			h.press(tcell.KeyRune, 'n')
+			h.waitPlaying("mock:b")
+
+			h.fake().Finish()
+			if tc.want == "" {
+				h.waitText("notice", h.notice, "End of queue")
+				if id := h.playing(); id != "" {
+					t.Errorf("playing %s after the end of the queue", id)
+				}
+				return
+			}
+			h.waitFor("track finished", func() bool { return h.playing() == "" })
+			h.waitPlaying(tc.want)
+		})
+	}
+}
+
+func TestBrokenStreamResumesWhereItStopped(t *testing.T) {
+	h := newHarness(t)
+	h.p.repeat = repeatOff // else a is resolved ahead to play again
+	a := h.tracks("a")[0]
+	h.enqueue(a)
+	go h.p.playTrackFrom(a, 30)
+	h.waitPlaying("mock:a")
+
+	h.fake().Fail("stream broke")
+	h.waitText("now playing", h.nowPlaying, "Retrying in 1s (1/3), resuming at 0:30")
+	h.waitFor("retry", func() bool { return len(h.fake().Loads()) == 2 })
+	h.waitPlaying("mock:a")
+	if pos := h.fake().Options().StartPos; pos < 30 || pos > 31 {
+		t.Errorf("resumed at %.1fs, want 30s", pos)
+	}
+	if n := h.mock.Count("resolve mock:a"); n != 2 {
+		t.Errorf("a resolved %d times, want twice", n)
+	}
+}
+
+func TestResolveErrorIsShown(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a", "b")...)
+	h.mock.Fail("mock:a", provider.ErrGeoBlocked)
+	h.focus(h.p.queueView)
+	h.press(tcell.KeyEnter, 0)
+
+	h.waitText("now playing", h.nowPlaying, "Resolve error")
+	if n := h.started(); n != 0 {
+		t.Errorf("%d players started for a track that didn't resolve", n)
+	}
+}
+
+func TestReconnectsWhenNetworkDrops(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a")...)
+	h.playQueue()
+
+	h.goOffline()
+	h.fake().Fail("connection reset")
+	h.waitFor("reconnecting", func() bool { return strings.Contains(h.p.status().state, "Reconnecting") })
+	h.waitText("now playing", h.nowPlaying, "Connection lost")
+
+	// The fake plays without a network, so the reconnect succeeds
+	h.waitPlaying("mock:a")
+	if state := h.p.status().state; !strings.Contains(state, "Playing") {
+		t.Errorf("status = %q after reconnecting", state)
+	}
+	h.p.mu.Lock()
+	r := h.p.retry
+	h.p.mu.Unlock()
+	if r == nil || r.reconnects != 1 || r.attempts != 0 {
+		t.Errorf("retry = %+v, want one reconnect and no attempts", r)
+	}
+}
+
+func TestPlayerCrashStartsNewPlayer(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a", "b")...)
+	h.playQueue()
+	first := h.fake()
+
+	first.Crash()
+	h.waitFor("a new player", func() bool { return h.started() == 2 })
+	h.waitPlaying("mock:a")
+	if h.p.out() == first {
+		t.Error("still using the player that crashed")
+	}
+}
+
+func TestTransportKeys(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a", "b", "c")...)
+	h.playQueue()
+
+	h.press(tcell.KeyRune, 'n')
+	h.waitPlaying("mock:b")
+	h.press(tcell.KeyRune, 'p')
+	h.waitPlaying("mock:a")
+
+	h.press(tcell.KeyRune, ' ')
+	h.waitFor("paused", func() bool { return strings.Contains(h.p.status().state, "Paused") })
+	h.press(tcell.KeyRune, ' ')
+	h.waitFor("playing", func() bool { return strings.Contains(h.p.status().state, "Playing") })
+
+	h.press(tcell.KeyRune, '-')
+	h.waitFor("volume down", func() bool { return h.p.status().volume == "Vol 95%" })
+
+	h.press(tcell.KeyRune, 's')
+	h.waitFor("stopped", func() bool { return strings.Contains(h.p.status().state, "Stopped") })
+	if url := h.fake().Playing(); url != "" {
+		t.Errorf("player still playing %s after stop", url)
+	}
+}
+
+func TestModeKeys(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a", "b")...)
+	h.focus(h.p.queueView)
+
+	h.press(tcell.KeyRune, 'r')
+	h.press(tcell.KeyRune, 'z')
+	h.waitFor("modes", func() bool {
+		modes := h.p.status().modes
+		return strings.Contains(modes, "Repeat one") && strings.Contains(modes, "Shuffle")
+	})
+	h.press(tcell.KeyRune, 'z')
+	h.waitFor("shuffle off", func() bool { return !strings.Contains(h.p.status().modes, "Shuffle") })
+}
+
+func TestQueueEditKeys(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a", "b", "c")...)
+	h.focus(h.p.queueView)
+
+	h.press(tcell.KeyRune, 'J')
+	h.waitFor("a moved down", func() bool { return h.queued(1) == "mock:a" })
+	h.press(tcell.KeyDelete, 0)
+	h.waitFor("a removed", func() bool {
+		h.p.mu.Lock()
+		defer h.p.mu.Unlock()
+		return len(h.p.queue) == 2
+	})
+	if got := []string{h.queued(0), h.queued(1)}; !slices.Equal(got, []string{"mock:b", "mock:c"}) {
+		t.Errorf("queue = %q, want b, c", got)
+	}
+	h.press(tcell.KeyRune, 'c')
+	h.waitText("notice", h.notice, "Queue cleared")
+}
+
+func TestSearchAddAndPlay(t *testing.T) {
+	h := newHarness(t)
+	tracks := h.tracks("x", "y")
+	h.mock.SetResults("lofi", tracks...)
+
+	h.typeKeys("lofi")
+	h.press(tcell.KeyEnter, 0)
+	h.waitFor("results", func() bool {
+		h.p.mu.Lock()
+		defer h.p.mu.Unlock()
+		return len(h.p.searchRes) == 2
+	})
+	if calls := h.mock.Calls(); !slices.Contains(calls, "search lofi") {
+		t.Fatalf("calls = %q, want a search for lofi", calls)
+	}
+
+	h.waitFor("results focused", func() bool {
+		var focused tview.Primitive
+		h.onUI(func() { focused = h.p.app.GetFocus() })
+		return focused == h.p.resultsView
+	})
+	h.press(tcell.KeyRune, 'a')
+	h.waitFor("x queued", func() bool {
+		h.p.mu.Lock()
+		defer h.p.mu.Unlock()
+		return len(h.p.queue) == 1 && h.p.queue[0].ID == "mock:x"
+	})
+
+	h.press(tcell.KeyEnter, 0)
+	h.waitPlaying("mock:x")
+}
+
+func TestSearchError(t *testing.T) {
+	h := newHarness(t)
+	h.mock.Fail("lofi", provider.ErrNetwork)
+
+	h.typeKeys("lofi")
+	h.press(tcell.KeyEnter, 0)
+	h.waitText("notice", h.notice, "Search error: could not connect")
+}
+
+func TestQueueBatchKeepsLineOrder(t *testing.T) {
+	h := newHarness(t)
+	// Lines are resolved a few at a time
+	h.mock.SetLatency(20 * time.Millisecond)
+	var lines []string
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		lines = append(lines, "song "+id)
+		h.mock.SetResults("song "+id, h.tracks(id)...)
+	}
+	lines = append(lines, "nothing")
+
+	h.p.queueBatch(lines)
+
+	var got []string
+	h.p.mu.Lock()
+	for _, track := range h.p.queue {
+		got = append(got, track.ID)
+	}
+	h.p.mu.Unlock()
+	if want := []string{"mock:1", "mock:2", "mock:3", "mock:4", "mock:5"}; !slices.Equal(got, want) {
+		t.Errorf("queue = %q, want %q", got, want)
+	}
+	h.waitText("notice", h.notice, "1 of 6 lines failed, first: nothing")
+}
+
+func TestKeysWhileQuittingAreDropped(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a", "b")...)
+	h.playQueue()
+
+	h.p.cleanup()
+	for range 20 {
+		// More than the action queue holds, should anything still read it
+		h.press(tcell.KeyRune, 'n')
+	}
+	if id := h.playing(); id != "" {
+		t.Errorf("playing %s after quitting", id)
+	}
+}
+
+func TestRemovePlayingFirstTrack(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a", "b", "c")...)
+	h.playQueue()
+
+	h.press(tcell.KeyRune, 'd')
+	h.waitText("queue title", func() string {
+		var title string
+		h.onUI(func() { title = h.p.queueView.GetTitle() })
+		return title
+	}, "Queue (2, 6:40 left)")
+
+	// The track after the removed one comes next
+	h.press(tcell.KeyRune, 'n')
+	h.waitPlaying("mock:b")
+}
+
+func TestNextAfterClearPlaysFirstAdded(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a", "b")...)
+	h.playQueue()
+	h.press(tcell.KeyRune, 'n')
+	h.waitPlaying("mock:b")
+
+	h.press(tcell.KeyRune, 'c')
+	h.waitText("notice", h.notice, "Queue cleared")
+	h.p.mu.Lock()
+	h.p.queue = append(h.p.queue, h.tracks("x", "y")...)
+	h.p.mu.Unlock()
+	h.p.updateQueueView()
+
+	h.press(tcell.KeyRune, 'n')
+	h.waitPlaying("mock:x")
+}
+
+func TestReloadConfigRestyles(t *testing.T) {
+	h := newHarness(t)
+	dir := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "audictl")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"theme": "light", "radio": true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h.p.reloadConfig()
+	h.waitText("notice", h.notice, "Config reloaded")
+	var name string
+	var bg tcell.Color
+	h.onUI(func() {
+		name = h.p.theme.Name
+		bg = h.p.queueView.GetBackgroundColor()
+	})
+	if name != "light" || bg != h.p.theme.Background {
+		t.Errorf("theme %s with queue background %v after reloading a light theme", name, bg)
+	}
+	if !strings.Contains(h.p.status().modes, "Radio") {
+		t.Errorf("modes = %q, want radio on from the file", h.p.status().modes)
+	}
+}
+
+func TestMuteSurvivesPlayerCrash(t *testing.T) {
+	h := newHarness(t)
+	h.enqueue(h.tracks("a")...)
+	h.playQueue()
+
+	h.press(tcell.KeyRune, 'm')
+	h.waitFor("muted", func() bool { return h.fake().Muted() })
+	h.fake().Crash()
+	h.waitFor("a new player", func() bool { return h.started() == 2 })
+	h.waitPlaying("mock:a")
+	if !h.fake().Muted() {
+		t.Error("new player unmuted while the status says muted")
+	}
+}
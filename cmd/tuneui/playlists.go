@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"audictl/internal/format"
+	"audictl/internal/playlist"
+	"audictl/internal/provider"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// promptSavePlaylist asks for a name and saves the current queue under it.
+// Must be called from the UI goroutine.
+func (p *player) promptSavePlaylist() {
+	p.mu.Lock()
+	queueCopy := make([]provider.Track, len(p.queue))
+	copy(queueCopy, p.queue)
+	p.mu.Unlock()
+
+	if len(queueCopy) == 0 {
+		p.showNotice("[yellow]Queue is empty - nothing to save[-]")
+		return
+	}
+
+	p.prompt("Save queue as playlist", " Name: ", "", func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		go func() {
+			if _, err := playlist.Create(name, queueCopy); err != nil {
+				p.notify(fmt.Sprintf("[red]Playlist error:[-] %v", err))
+				return
+			}
+			p.notify(fmt.Sprintf("[green]✓ Saved playlist:[-] %s (%d tracks)", name, len(queueCopy)))
+		}()
+	})
+}
+
+// promptAddToPlaylist asks for a playlist name and adds the selected or
+// playing track to it, creating the playlist when there is none by that
+// name. Must be called from the UI goroutine.
+func (p *player) promptAddToPlaylist() {
+	track, ok := p.selectedTrack()
+	if !ok {
+		p.showNotice("[yellow]No track selected[-]")
+		return
+	}
+	p.prompt("Add to playlist", " Name: ", "", func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		go func() {
+			if err := playlist.Add(name, track); err != nil {
+				p.notify(fmt.Sprintf("[red]Playlist error:[-] %v", err))
+				return
+			}
+			p.notify(fmt.Sprintf("[green]+ Added to playlist %s:[-] %s", tview.Escape(name), tview.Escape(track.Title)))
+		}()
+	})
+}
+
+// plEntry is what a row of the playlist browser stands for: a saved or a
+// provider playlist, or one of their tracks.
+type plEntry struct {
+	saved  *playlist.Playlist
+	remote *provider.Playlist
+	track  *provider.Track
+	loaded bool // the tracks of remote are listed below it
+
+	// For a track of a saved playlist: the playlist, its row and where
+	// the track is in it
+	in    *playlist.Playlist
+	inRow *tview.TreeNode
+	idx   int
+}
+
+// showPlaylists opens the playlist browser: the saved playlists, then the
+// user's playlists of each provider that keeps some. Enter replaces the
+// queue with the selected playlist (or plays the selected track), 'a'
+// appends it, Space or → expands it to show its tracks, 'd' deletes a
+// saved playlist or removes a track from it and 'e' exports it.
+// Must be called from the UI goroutine.
+func (p *player) showPlaylists() {
+	const name = "playlists"
+	lists, err := playlist.List()
+	if err != nil {
+		p.showNotice(fmt.Sprintf("[red]Playlist error:[-] %v", err))
+		return
+	}
+
+	root := tview.NewTreeNode("")
+	tree := tview.NewTreeView().SetRoot(root).SetTopLevel(1)
+	tree.SetGraphicsColor(tcell.ColorGray)
+	tree.SetBorder(true).SetTitle(" Playlists [Enter=Load, a=Append, Space=Expand, d=Delete/Remove, e=Export, Esc=Close] ")
+
+	header := func(text string) *tview.TreeNode {
+		node := tview.NewTreeNode(text).SetColor(tcell.ColorYellow).SetSelectable(false)
+		root.AddChild(node)
+		return node
+	}
+	note := func(parent *tview.TreeNode, text string) {
+		parent.AddChild(tview.NewTreeNode(text).SetSelectable(false))
+	}
+	// addTracks lists tracks below parent, the row of the saved playlist
+	// in or of a provider playlist when in is nil
+	addTracks := func(parent *tview.TreeNode, tracks []provider.Track, in *playlist.Playlist) {
+		parent.ClearChildren()
+		for i := range tracks {
+			row := format.Row(p.rowFormat(format.DefaultQueueRow), i+1, escapeTrack(tracks[i]))
+			e := &plEntry{track: &tracks[i]}
+			if in != nil {
+				e.in, e.inRow, e.idx = in, parent, i
+			}
+			parent.AddChild(tview.NewTreeNode(row).SetReference(e))
+		}
+		if len(tracks) == 0 {
+			note(parent, "[gray]Empty[-]")
+		}
+	}
+	savedRow := func(node *tview.TreeNode, pl *playlist.Playlist) {
+		node.SetText(fmt.Sprintf("%s [gray](%d tracks)[-]", tview.Escape(pl.Name), len(pl.Tracks)))
+		addTracks(node, pl.Tracks, pl)
+	}
+
+	saved := header("Saved")
+	for i := range lists {
+		pl := &lists[i]
+		node := tview.NewTreeNode("").SetReference(&plEntry{saved: pl}).SetExpanded(false)
+		savedRow(node, pl)
+		saved.AddChild(node)
+	}
+	if len(lists) == 0 {
+		note(saved, "[gray]No playlists yet - press Ctrl+S to save the queue[-]")
+	}
+	if first := saved.GetChildren(); len(first) > 0 && first[0].GetReference() != nil {
+		tree.SetCurrentNode(first[0])
+	}
+
+	// The provider playlists take a while; list them as they come in
+	loading := header("[gray]Loading provider playlists…[-]")
+	go func() {
+		results := p.providers.Playlists()
+		p.app.QueueUpdateDraw(func() {
+			root.RemoveChild(loading)
+			for _, res := range results {
+				group := header(providerTitle(res.Provider))
+				switch {
+				case res.Err != nil:
+					note(group, "[gray]"+tview.Escape(provider.Explain(res.Err))+"[-]")
+				case len(res.Playlists) == 0:
+					note(group, "[gray]No playlists[-]")
+				}
+				for i := range res.Playlists {
+					pl := &res.Playlists[i]
+					text := tview.Escape(pl.Name)
+					if pl.Count > 0 {
+						text += fmt.Sprintf(" [gray](%d tracks)[-]", pl.Count)
+					}
+					node := tview.NewTreeNode(text).SetReference(&plEntry{remote: pl}).SetExpanded(false)
+					group.AddChild(node)
+				}
+			}
+			if tree.GetCurrentNode() == nil {
+				tree.SetCurrentNode(firstSelectable(root))
+			}
+		})
+	}()
+
+	// fetch lists the tracks of a provider playlist below its node, then
+	// calls done with them
+	fetch := func(node *tview.TreeNode, e *plEntry, done func(tracks []provider.Track)) {
+		if e.loaded {
+			done(remoteTracks(node))
+			return
+		}
+		node.ClearChildren()
+		note(node, "[gray]Loading…[-]")
+		go func() {
+			tracks, err := p.providers.PlaylistTracks(*e.remote)
+			p.app.QueueUpdateDraw(func() {
+				if err != nil {
+					node.ClearChildren()
+					note(node, "[red]"+tview.Escape(provider.Explain(err))+"[-]")
+					return
+				}
+				e.loaded = true
+				addTracks(node, tracks, nil)
+				done(tracks)
+			})
+		}()
+	}
+
+	load := func(replace bool) {
+		node := tree.GetCurrentNode()
+		if node == nil {
+			return
+		}
+		e, ok := node.GetReference().(*plEntry)
+		if !ok {
+			return
+		}
+		switch {
+		case e.saved != nil:
+			p.hideModal(name)
+			go p.loadPlaylist(*e.saved, replace)
+		case e.remote != nil:
+			fetch(node, e, func(tracks []provider.Track) {
+				if !p.pages.HasPage(name) {
+					return
+				}
+				p.hideModal(name)
+				go p.loadPlaylist(playlist.Playlist{Name: e.remote.Name, Tracks: tracks}, replace)
+			})
+		case e.track != nil && replace:
+			p.hideModal(name)
+			go p.playTrack(*e.track)
+		case e.track != nil:
+			go p.enqueue(*e.track)
+		}
+	}
+
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		node := tree.GetCurrentNode()
+		var e *plEntry
+		if node != nil {
+			e, _ = node.GetReference().(*plEntry)
+		}
+		switch event.Key() {
+		case tcell.KeyEsc:
+			p.hideModal(name)
+			return nil
+		case tcell.KeyEnter:
+			load(true)
+			return nil
+		case tcell.KeyRight, tcell.KeyLeft:
+			if e == nil || e.track != nil {
+				return nil
+			}
+			expand := event.Key() == tcell.KeyRight
+			if expand && e.remote != nil {
+				fetch(node, e, func([]provider.Track) {})
+			}
+			node.SetExpanded(expand)
+			return nil
+		}
+		switch event.Rune() {
+		case 'a', 'A':
+			load(false)
+			return nil
+		case ' ':
+			if e == nil || e.track != nil {
+				return nil
+			}
+			if !node.IsExpanded() && e.remote != nil {
+				fetch(node, e, func([]provider.Track) {})
+			}
+			node.SetExpanded(!node.IsExpanded())
+			return nil
+		case 'e', 'E':
+			if e != nil && e.saved != nil {
+				pl := *e.saved
+				p.hideModal(name)
+				p.promptExport("playlist", pl.Tracks, pl.Name)
+			}
+			return nil
+		case 'd', 'D':
+			if e != nil && e.in != nil {
+				if err := playlist.Remove(e.in.Name, e.idx); err != nil {
+					p.showNotice(fmt.Sprintf("[red]Playlist error:[-] %v", err))
+					return nil
+				}
+				p.showNotice(fmt.Sprintf("[yellow]- Removed from %s:[-] %s", tview.Escape(e.in.Name), tview.Escape(e.track.Title)))
+				e.in.Tracks = append(e.in.Tracks[:e.idx:e.idx], e.in.Tracks[e.idx+1:]...)
+				savedRow(e.inRow, e.in)
+				if rows := e.inRow.GetChildren(); len(rows) > 0 && rows[0].GetReference() != nil {
+					tree.SetCurrentNode(rows[min(e.idx, len(rows)-1)])
+				} else {
+					tree.SetCurrentNode(e.inRow)
+				}
+				return nil
+			}
+			if e == nil || e.saved == nil {
+				return nil
+			}
+			if err := playlist.Delete(e.saved.Name); err != nil {
+				p.showNotice(fmt.Sprintf("[red]Playlist error:[-] %v", err))
+				return nil
+			}
+			p.showNotice(fmt.Sprintf("[yellow]- Deleted playlist:[-] %s", e.saved.Name))
+			saved.RemoveChild(node)
+			if len(saved.GetChildren()) == 0 {
+				note(saved, "[gray]No playlists yet - press Ctrl+S to save the queue[-]")
+			}
+			tree.SetCurrentNode(firstSelectable(root))
+			return nil
+		}
+		return event
+	})
+
+	p.showModal(name, tree, 90, 24)
+}
+
+// remoteTracks returns the tracks listed below a playlist node.
+func remoteTracks(node *tview.TreeNode) []provider.Track {
+	var tracks []provider.Track
+	for _, child := range node.GetChildren() {
+		if e, ok := child.GetReference().(*plEntry); ok && e.track != nil {
+			tracks = append(tracks, *e.track)
+		}
+	}
+	return tracks
+}
+
+// firstSelectable returns the first row below node that can be selected,
+// or nil.
+func firstSelectable(node *tview.TreeNode) *tview.TreeNode {
+	for _, child := range node.GetChildren() {
+		if child.GetReference() != nil {
+			return child
+		}
+		if child.IsExpanded() {
+			if found := firstSelectable(child); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// providerTitle names a provider for headings, e.g. "YouTube".
+func providerTitle(name string) string {
+	switch name {
+	case "youtube":
+		return "YouTube"
+	case "":
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// loadPlaylist puts the playlist's tracks into the queue, either replacing
+// its contents or appending to it.
+func (p *player) loadPlaylist(pl playlist.Playlist, replace bool) {
+	p.loadTracks("playlist", pl.Name, pl.Tracks, replace)
+}
+
+// loadTracks puts tracks into the queue, either replacing its contents or
+// appending to it, and says so naming them as what (e.g. "album") called
+// name.
+func (p *player) loadTracks(what, name string, tracks []provider.Track, replace bool) {
+	p.mu.Lock()
+	if replace {
+		p.queue = append([]provider.Track{}, tracks...)
+		// Start from the top: "next" plays the first entry
+		p.queueIdx = -1
+	} else {
+		p.queue = append(p.queue, tracks...)
+	}
+	p.mu.Unlock()
+
+	p.updateQueueView()
+	if replace {
+		p.notify(fmt.Sprintf("[green]Loaded %s:[-] %s (%d tracks) - press [yellow]n[-] to start", what, name, len(tracks)))
+	} else {
+		p.notify(fmt.Sprintf("[green]+ Added %s:[-] %s (%d tracks)", what, name, len(tracks)))
+	}
+}
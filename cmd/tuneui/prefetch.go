@@ -0,0 +1,88 @@
+package main
+
+import (
+	"time"
+
+	"audictl/internal/provider"
+)
+
+// prefetched is a stream resolved ahead of time for the next queued track.
+type prefetched struct {
+	trackID    string
+	stream     provider.Stream
+	resolvedAt time.Time
+}
+
+// fresh reports whether the stream can still be played.
+func (pf *prefetched) fresh() bool {
+	return pf.stream.Usable(pf.resolvedAt)
+}
+
+// upNext returns the track that plays when the current one ends, without
+// advancing.
+func (p *player) upNext() (provider.Track, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	idx := p.nextIndex(true)
+	if idx < 0 {
+		return provider.Track{}, false
+	}
+	return p.queue[idx], true
+}
+
+// prefetchNext resolves the stream of the next queued track while the
+// current one plays, so the transition doesn't wait on yt-dlp, and appends
+// it to mpv for a gapless transition. Low-data mode skips it: the next
+// track may never be played.
+func (p *player) prefetchNext() {
+	track, ok := p.upNext()
+	if !ok {
+		return
+	}
+	p.mu.Lock()
+	if p.lowData {
+		p.mu.Unlock()
+		return
+	}
+	if p.prefetch != nil && p.prefetch.trackID == track.ID && p.prefetch.fresh() {
+		p.mu.Unlock()
+		p.appendNext()
+		return
+	}
+	if p.prefetching == track.ID {
+		p.mu.Unlock()
+		return
+	}
+	p.prefetching = track.ID
+	p.mu.Unlock()
+
+	stream, err := p.providers.ResolveStream(track, p.streamQuality())
+
+	p.mu.Lock()
+	p.prefetching = ""
+	if err == nil {
+		p.prefetch = &prefetched{trackID: track.ID, stream: stream, resolvedAt: time.Now()}
+	}
+	p.mu.Unlock()
+	if err != nil {
+		// Not fatal: the track is resolved again when it's played
+		return
+	}
+	p.appendNext()
+}
+
+// takePrefetched returns the prefetched stream for track if there is a
+// fresh one, consuming it.
+func (p *player) takePrefetched(track provider.Track) (provider.Stream, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pf := p.prefetch
+	if pf == nil || pf.trackID != track.ID {
+		return provider.Stream{}, false
+	}
+	p.prefetch = nil
+	if !pf.fresh() {
+		return provider.Stream{}, false
+	}
+	return pf.stream, true
+}
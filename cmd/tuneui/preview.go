@@ -0,0 +1,110 @@
+package main
+
+import (
+	"image"
+	"sync"
+	"time"
+
+	"audictl/internal/artwork"
+	"audictl/internal/provider"
+	yprov "audictl/providers/youtube"
+)
+
+const (
+	// seekStep is how far the seek keys jump, in seconds.
+	seekStep = 10
+	// previewFrames is how many storyboard frames a seek preview shows:
+	// the one at the target in the middle, with those a seek step before
+	// and after it either side.
+	previewFrames = 3
+	// previewCols is the width of the seek preview in the Now Playing
+	// panel.
+	previewCols = 48
+	// previewTime is how long the seek preview stays after a seek.
+	previewTime = 2 * time.Second
+)
+
+// trackBoard is the storyboard of a track, or why it has none.
+type trackBoard struct {
+	trackID string
+	sb      yprov.Storyboard
+	err     error
+}
+
+// boardCache keeps the storyboard of the track seeked in last, so seeking
+// again doesn't ask YouTube for it each time.
+type boardCache struct {
+	mu    sync.Mutex // held while fetching, one fetch at a time
+	board *trackBoard
+}
+
+// previewSeek shows the storyboard frames around target seconds of the
+// playing track in the Now Playing panel, where the album art goes, for a
+// moment. Only YouTube videos have them; low-data mode leaves them out.
+func (p *player) previewSeek(target float64) {
+	p.mu.Lock()
+	track := p.currentTrk
+	off := p.artProto == "" || p.lowData
+	p.previewSeq++
+	seq := p.previewSeq
+	p.mu.Unlock()
+	if off || track == nil || youtubeID(*track) == "" {
+		return
+	}
+	go func() {
+		defer p.recoverPanic()
+		sb, err := p.boards.storyboard(*track)
+		if err != nil {
+			return
+		}
+		frames := make([]image.Image, previewFrames)
+		for i := range frames {
+			pos := target + float64((i-previewFrames/2)*seekStep)
+			if track.Duration > 0 && pos > float64(track.Duration) {
+				continue
+			}
+			frames[i], _ = artwork.StoryboardFrame(sb, pos)
+		}
+		if frames[previewFrames/2] == nil {
+			return
+		}
+		img := artwork.Strip(frames, previewFrames/2, 4)
+		p.app.QueueUpdateDraw(func() {
+			p.mu.Lock()
+			current := seq == p.previewSeq && p.currentTrk != nil && p.currentTrk.ID == track.ID
+			p.mu.Unlock()
+			if current {
+				p.showPreview(img)
+			}
+		})
+		time.AfterFunc(previewTime, func() {
+			p.app.QueueUpdateDraw(func() {
+				if p.previewImg == img {
+					p.showPreview(nil)
+				}
+			})
+		})
+	}()
+}
+
+// storyboard returns the storyboard of track, fetching it unless it was
+// the last one asked for.
+func (c *boardCache) storyboard(track provider.Track) (yprov.Storyboard, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.board == nil || c.board.trackID != track.ID {
+		sb, err := yprov.Storyboards(youtubeID(track))
+		c.board = &trackBoard{trackID: track.ID, sb: sb, err: err}
+	}
+	return c.board.sb, c.board.err
+}
+
+// showPreview shows img in place of the album art, or the album art again
+// when img is nil. Called from the UI goroutine.
+func (p *player) showPreview(img image.Image) {
+	if p.artProto == "" {
+		return
+	}
+	p.previewImg = img
+	p.fitArt()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+
+	"audictl/internal/format"
+)
+
+// queueHelp lists the queue keys in its title.
+const queueHelp = "[Enter=Play, d=Del, J/K=Move, f=Current]"
+
+// playingIndex returns the queue position of the playing track, or -1
+// when nothing is playing or it didn't come from the queue. Must be called
+// with p.mu held.
+func (p *player) playingIndex() int {
+	if p.currentTrk == nil {
+		return -1
+	}
+	if p.queueIdx >= 0 && p.queueIdx < len(p.queue) && p.queue[p.queueIdx].ID == p.currentTrk.ID {
+		return p.queueIdx
+	}
+	for i, t := range p.queue {
+		if t.ID == p.currentTrk.ID {
+			return i
+		}
+	}
+	return -1
+}
+
+// queueLeft returns the seconds of the queue still to play: the rest of
+// the playing track and everything after it, or the whole queue when it
+// isn't playing. unknown is set when some tracks have no duration (live
+// streams). Must be called with p.mu held.
+func (p *player) queueLeft() (secs int, unknown bool) {
+	start := 0
+	if i := p.playingIndex(); i >= 0 {
+		if d := p.queue[i].Duration; d > 0 {
+			secs += max(d-int(p.position()), 0)
+		} else {
+			unknown = true
+		}
+		start = i + 1
+	}
+	for _, t := range p.queue[start:] {
+		if t.Duration > 0 {
+			secs += t.Duration
+		} else {
+			unknown = true
+		}
+	}
+	return secs, unknown
+}
+
+// queueTitle returns the title of the queue panel: the number of tracks
+// and the time left. Must be called with p.mu held.
+func (p *player) queueTitle() string {
+	if len(p.queue) == 0 {
+		return " Queue " + queueHelp + " "
+	}
+	secs, unknown := p.queueLeft()
+	left := format.Duration(secs)
+	if left == "" {
+		left = "0:00"
+	}
+	if unknown {
+		left += "+"
+	}
+	return fmt.Sprintf(" Queue (%d, %s left) %s ", len(p.queue), left, queueHelp)
+}
+
+// refreshQueueTitle updates the time left in the queue panel title.
+func (p *player) refreshQueueTitle() {
+	p.mu.Lock()
+	title := p.queueTitle()
+	p.mu.Unlock()
+	p.app.QueueUpdateDraw(func() {
+		p.queueView.SetTitle(title)
+	})
+}
+
+// jumpToCurrent selects the playing track in the queue panel. Called from
+// the UI goroutine.
+func (p *player) jumpToCurrent() {
+	p.mu.Lock()
+	idx := p.playingIndex()
+	p.mu.Unlock()
+	if idx < 0 {
+		p.showNotice("[yellow]The playing track isn't in the queue[-]")
+		return
+	}
+	p.queueView.SetCurrentItem(idx)
+}
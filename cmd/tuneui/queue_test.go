@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+
+	"audictl/internal/provider"
+)
+
+func TestPlayingIndex(t *testing.T) {
+	a := provider.Track{ID: "a", Duration: 100}
+	b := provider.Track{ID: "b", Duration: 200}
+	c := provider.Track{ID: "c"}
+	for _, tc := range []struct {
+		name     string
+		queue    []provider.Track
+		queueIdx int
+		playing  *provider.Track
+		want     int
+	}{
+		{"nothing playing", []provider.Track{a, b}, 0, nil, -1},
+		{"at the queue position", []provider.Track{a, b}, 1, &b, 1},
+		{"elsewhere in the queue", []provider.Track{a, b}, 0, &b, 1},
+		{"not queued", []provider.Track{a, b}, 0, &c, -1},
+		{"before the start", []provider.Track{b}, -1, &a, -1},
+		{"past the end", []provider.Track{a}, 3, &a, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &player{queue: tc.queue, queueIdx: tc.queueIdx, currentTrk: tc.playing}
+			if got := p.playingIndex(); got != tc.want {
+				t.Errorf("playingIndex() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQueueLeft(t *testing.T) {
+	a := provider.Track{ID: "a", Duration: 100}
+	b := provider.Track{ID: "b", Duration: 200}
+	live := provider.Track{ID: "live", IsStream: true}
+	for _, tc := range []struct {
+		name        string
+		queue       []provider.Track
+		queueIdx    int
+		playing     *provider.Track
+		pos         float64
+		want        int
+		wantUnknown bool
+	}{
+		{"not playing", []provider.Track{a, b}, 0, nil, 0, 300, false},
+		{"first playing", []provider.Track{a, b}, 0, &a, 40, 260, false},
+		{"last playing", []provider.Track{a, b}, 1, &b, 50, 150, false},
+		{"played past its duration", []provider.Track{a, b}, 0, &a, 150, 200, false},
+		{"live stream queued", []provider.Track{a, live}, 0, &a, 0, 100, true},
+		{"live stream playing", []provider.Track{live, a}, 0, &live, 30, 100, true},
+		{"playing track removed from the front", []provider.Track{b}, -1, &a, 10, 200, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &player{queue: tc.queue, queueIdx: tc.queueIdx, currentTrk: tc.playing, posBase: tc.pos, paused: true}
+			got, unknown := p.queueLeft()
+			if got != tc.want || unknown != tc.wantUnknown {
+				t.Errorf("queueLeft() = %d, %v, want %d, %v", got, unknown, tc.want, tc.wantUnknown)
+			}
+		})
+	}
+}
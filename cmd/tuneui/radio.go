@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+
+	"audictl/internal/provider"
+)
+
+const (
+	// radioBatch is how many related tracks radio mode adds at a time.
+	radioBatch = 10
+	// radioSeedTag is the tag holding the title of the track radio mode
+	// added a track for, shown in its queue row.
+	radioSeedTag = "radio_seed"
+)
+
+// fillRadio appends tracks related to seed when radio mode is on and seed
+// is the last track in the queue, leaving out tracks already queued. It
+// runs as a track starts, so the next one is ready in time, and again if
+// the queue still ran out.
+func (p *player) fillRadio(seed provider.Track) {
+	p.radioMu.Lock()
+	defer p.radioMu.Unlock()
+
+	p.mu.Lock()
+	last := len(p.queue) > 0 && p.queue[len(p.queue)-1].ID == seed.ID
+	on := p.radio
+	p.mu.Unlock()
+	if !on || !last {
+		return
+	}
+
+	tracks, err := p.providers.Related(seed, radioBatch)
+	if err != nil {
+		p.notify(fmt.Sprintf("[red]Radio error:[-] %s", provider.Explain(err)))
+		return
+	}
+
+	p.mu.Lock()
+	queued := map[string]bool{}
+	for _, t := range p.queue {
+		queued[t.ID] = true
+	}
+	added := 0
+	for _, t := range tracks {
+		if !queued[t.ID] {
+			queued[t.ID] = true
+			p.queue = append(p.queue, withRadioSeed(t, seed))
+			added++
+		}
+	}
+	p.mu.Unlock()
+	if added == 0 {
+		return
+	}
+	p.updateQueueView()
+	p.notify(fmt.Sprintf("[green]📻 Radio:[-] added %d tracks like %s", added, seed.Title))
+}
+
+// withRadioSeed returns track tagged as added by radio mode for seed. The
+// tags are copied, as the provider may hand the same map out again.
+func withRadioSeed(track, seed provider.Track) provider.Track {
+	tags := make(map[string]string, len(track.Tags)+1)
+	for k, v := range track.Tags {
+		tags[k] = v
+	}
+	tags[radioSeedTag] = seed.Title
+	track.Tags = tags
+	return track
+}
+
+// radioNote returns the note on a queue row saying which track radio mode
+// added track for, or "".
+func radioNote(track provider.Track) string {
+	seed := track.Tags[radioSeedTag]
+	if seed == "" {
+		return ""
+	}
+	return " [gray]· because you played " + tview.Escape(seed) + "[-]"
+}
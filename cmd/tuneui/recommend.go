@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/format"
+	"audictl/internal/history"
+	"audictl/internal/provider"
+	"audictl/internal/recommend"
+)
+
+const (
+	// recommendArtists is how many of the top artists suggestions come from.
+	recommendArtists = 6
+	// recommendPerArtist is how many tracks each of them adds.
+	recommendPerArtist = 4
+	// recommendFresh is how long a played track stays out of suggestions.
+	recommendFresh = 3 * 24 * time.Hour
+)
+
+// recommendation is a suggested queue and what it was based on.
+type recommendation struct {
+	basis  string
+	tracks []provider.Track
+}
+
+// recommendTracks builds a queue from the history: tracks of the artists
+// played most, lately and at this time of day. With a seed it builds one
+// around the seed's artist and tracks like it instead.
+func (p *player) recommendTracks(seed *provider.Track) (recommendation, error) {
+	entries, err := history.All()
+	if err != nil {
+		return recommendation{}, err
+	}
+	now := time.Now()
+	skip := recommend.PlayedSince(entries, now.Add(-recommendFresh))
+	search := func(query string) ([]provider.Track, error) {
+		var tracks []provider.Track
+		var err error
+		_, kind := p.searchKind("")
+		for _, res := range p.providers.SearchAll(query, kind, 10) {
+			if res.Err != nil {
+				err = res.Err
+				continue
+			}
+			tracks = append(tracks, res.Tracks...)
+		}
+		if len(tracks) > 0 {
+			err = nil
+		}
+		return tracks, err
+	}
+
+	if seed != nil {
+		skip[seed.ID] = true
+		artist := recommend.Artist{Name: seed.Artist}
+		tracks, err := recommend.Queue(search, []recommend.Artist{artist}, skip, recommendPerArtist)
+		if err != nil {
+			return recommendation{}, err
+		}
+		for _, t := range tracks {
+			skip[t.ID] = true
+		}
+		// Related tracks are a bonus: the artist's own may be all there is
+		if related, err := p.providers.Related(*seed, recommendArtists*recommendPerArtist); err == nil {
+			for _, t := range related {
+				if !skip[t.ID] {
+					skip[t.ID] = true
+					tracks = append(tracks, t)
+				}
+			}
+		}
+		return recommendation{basis: tview.Escape(seed.Artist + " - " + seed.Title), tracks: tracks}, nil
+	}
+
+	artists := recommend.Artists(entries, now, recommendArtists)
+	if len(artists) == 0 {
+		return recommendation{}, recommend.ErrNoHistory
+	}
+	tracks, err := recommend.Queue(search, artists, skip, recommendPerArtist)
+	if err != nil {
+		return recommendation{}, err
+	}
+	var basis []string
+	for _, a := range artists {
+		basis = append(basis, fmt.Sprintf("%s [gray](%s)[-]", tview.Escape(a.Name), a.Reason))
+	}
+	return recommendation{basis: strings.Join(basis, ", "), tracks: tracks}, nil
+}
+
+// showRecommendations opens a queue suggested from the history. Enter
+// replaces the queue with it and starts playing, 'a' appends it and 's'
+// suggests around the playing track instead. Must be called from the UI
+// goroutine.
+func (p *player) showRecommendations() {
+	const name = "recommend"
+	header := tview.NewTextView()
+	header.SetDynamicColors(true).SetWordWrap(true)
+	header.SetBorderPadding(0, 0, 1, 1)
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetHighlightFullLine(true)
+	list.SetSelectedBackgroundColor(p.theme.Selection)
+	list.SetSelectedTextColor(p.theme.SelectionText)
+
+	var rec recommendation
+	build := func(seed *provider.Track) {
+		rec = recommendation{}
+		header.SetText("[gray]Looking through your history…[-]")
+		list.Clear()
+		go func() {
+			r, err := p.recommendTracks(seed)
+			p.app.QueueUpdateDraw(func() {
+				if err != nil {
+					header.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(provider.Explain(err))))
+					return
+				}
+				rec = r
+				what := "Based on"
+				if seed != nil {
+					what = "Like"
+				}
+				header.SetText(fmt.Sprintf("[yellow]%s:[-] %s", what, r.basis))
+				for i, t := range r.tracks {
+					list.AddItem(format.Row(p.rowFormat(format.DefaultQueueRow), i+1, escapeTrack(t)), "", 0, nil)
+				}
+				if len(r.tracks) == 0 {
+					list.AddItem("[gray]Nothing new found to suggest[-]", "", 0, nil)
+				}
+			})
+		}()
+	}
+	build(nil)
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			p.hideModal(name)
+			return nil
+		case event.Key() == tcell.KeyEnter:
+			if len(rec.tracks) > 0 {
+				p.hideModal(name)
+				go p.playQueue(rec.tracks)
+			}
+			return nil
+		case event.Rune() == 'a' || event.Rune() == 'A':
+			if len(rec.tracks) > 0 {
+				p.hideModal(name)
+				go p.loadTracks("suggestions", "from your history", rec.tracks, false)
+			}
+			return nil
+		case event.Rune() == 's' || event.Rune() == 'S':
+			p.mu.Lock()
+			var seed *provider.Track
+			if p.currentTrk != nil {
+				t := *p.currentTrk
+				seed = &t
+			}
+			p.mu.Unlock()
+			if seed == nil {
+				p.showNotice("[yellow]Nothing playing to suggest around[-]")
+				return nil
+			}
+			build(seed)
+			return nil
+		}
+		return event
+	})
+
+	box := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(header, 3, 0, false).
+		AddItem(list, 0, 1, true)
+	box.SetBorder(true).SetTitle(" Suggestions [Enter=Play all, a=Append, s=Like playing track, Esc=Close] ")
+	p.showModal(name, box, 90, 28)
+	p.app.SetFocus(list)
+}
+
+// playQueue replaces the queue with tracks and plays the first.
+func (p *player) playQueue(tracks []provider.Track) {
+	p.mu.Lock()
+	p.queue = append([]provider.Track{}, tracks...)
+	p.queueIdx = -1
+	p.mu.Unlock()
+	p.updateQueueView()
+	p.next()
+}
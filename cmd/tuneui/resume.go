@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"audictl/internal/format"
+	"audictl/internal/provider"
+)
+
+// resumeMargin is how close to either end of a track a position is not
+// worth resuming from: it starts over from the beginning instead.
+const resumeMargin = 30
+
+// resumable reports whether track is long enough to resume, by threshold.
+func resumable(track provider.Track, threshold time.Duration) bool {
+	return threshold > 0 && time.Duration(track.Duration)*time.Second > threshold
+}
+
+// rememberPosition saves where playback of track was left, pos seconds in,
+// when it is long enough to resume. Must be called with p.mu held.
+func (p *player) rememberPosition(track provider.Track, pos float64) {
+	if p.positions == nil || !resumable(track, p.cfg.ResumeThreshold()) {
+		return
+	}
+	if pos < resumeMargin || pos > float64(track.Duration-resumeMargin) {
+		pos = 0
+	}
+	p.writes.Add(1)
+	go func() {
+		defer p.writes.Done()
+		if err := p.positions.Set(track.ID, pos); err != nil {
+			p.notify(fmt.Sprintf("[red]Position error:[-] %v", err))
+		}
+	}()
+}
+
+// resumePosition returns where to resume track from, or 0 to play it from
+// the start.
+func (p *player) resumePosition(track provider.Track) float64 {
+	if p.positions == nil || p.fromStart || !resumable(track, p.config().ResumeThreshold()) {
+		return 0
+	}
+	pos, _ := p.positions.Get(track.ID)
+	return pos
+}
+
+// noteResumed tells that track started where it was left, if it did.
+func (p *player) noteResumed(track provider.Track, startPos float64) {
+	if startPos > 0 && startPos == p.resumePosition(track) {
+		p.notify(fmt.Sprintf("[green]Resumed at %s[-] [gray](: then 0 starts over)[-]", format.Duration(int(startPos))))
+	}
+}
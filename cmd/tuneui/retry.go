@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"audictl/internal/provider"
+)
+
+const (
+	// maxRetries is how often a track whose playback failed is retried
+	// before it is skipped.
+	maxRetries = 3
+	// maxFailStreak is how many tracks in a row may be skipped after
+	// failing before playback stops instead, e.g. when the network is down.
+	maxFailStreak = 3
+	// maxReconnects is how often a track is retried while the network is
+	// down, about two minutes with the delays capped at maxRetryDelay,
+	// before playback stops.
+	maxReconnects = 8
+	// maxRetryDelay caps the growing wait between retries.
+	maxRetryDelay = 30 * time.Second
+	// cutShortMargin is how far from its end a track that stopped counts
+	// as cut off rather than finished.
+	cutShortMargin = 15
+	// probeTimeout is how long to try reaching a stream's host after it
+	// failed, to tell a network failure from a broken stream.
+	probeTimeout = 3 * time.Second
+)
+
+// playbackRetry is a track being retried after its playback failed.
+type playbackRetry struct {
+	track      provider.Track
+	attempts   int
+	reconnects int  // attempts while the network was down, counted apart
+	offline    bool // the last failure was the network's
+}
+
+// retryDelay is the wait before retry attempt n (1-based): 1s, 2s, 4s, ...
+// up to maxRetryDelay.
+func retryDelay(n int) time.Duration {
+	return min(time.Second<<(n-1), maxRetryDelay)
+}
+
+// playbackFailed handles track ending with err at pos seconds. It is
+// resolved again and resumed there with growing delays; after maxRetries it
+// is skipped, and after maxFailStreak skipped tracks playback stops. While
+// the network is down the retries don't count: playback reconnects for up
+// to maxReconnects attempts, then stops, as the next track would fail too.
+func (p *player) playbackFailed(track provider.Track, pos float64, err error) {
+	why := provider.Explain(err)
+	if why == "" {
+		why = "stream ended unexpectedly"
+	}
+	offline := errors.Is(err, provider.ErrNetwork) || (!onDisk(track) && p.unreachable(track))
+	// The stream URL may have expired; don't play it again
+	p.providers.ForgetStream(track)
+
+	p.mu.Lock()
+	if p.prefetch != nil && p.prefetch.trackID == track.ID {
+		p.prefetch = nil
+	}
+	r := p.retry
+	if r == nil || r.track.ID != track.ID {
+		r = &playbackRetry{track: track}
+		p.retry = r
+	}
+	r.offline = offline
+	attempt, limit := 0, maxRetries
+	if offline {
+		r.reconnects++
+		attempt, limit = r.reconnects, maxReconnects
+	} else {
+		r.attempts++
+		attempt = r.attempts
+	}
+	streak := 0
+	if attempt > limit {
+		p.retry = nil
+		p.failStreak++
+		streak = p.failStreak
+		if streak >= maxFailStreak || offline {
+			// The next track the user starts gets a fresh count
+			p.failStreak = 0
+		}
+	}
+	b := p.backend
+	p.mu.Unlock()
+
+	if b != nil {
+		// mpv moves on to an appended track after an error; hold it
+		_ = b.Stop()
+	}
+
+	if attempt <= limit {
+		delay := retryDelay(attempt)
+		resume := ""
+		if pos >= 1 {
+			resume = ", resuming at " + clock(pos)
+		}
+		status := fmt.Sprintf("[red]Playback error:[-] %s\n[white]%s[-]\n[gray]Retrying in %s (%d/%d)%s[-]",
+			why, track.Title, delay, attempt, limit, resume)
+		if offline {
+			status = fmt.Sprintf("[yellow]Connection lost:[-] %s\n[white]%s[-]\n[gray]Reconnecting in %s (%d/%d)%s[-]",
+				why, track.Title, delay, attempt, limit, resume)
+		}
+		p.updateNowPlaying(status)
+		p.refreshStatus()
+		time.AfterFunc(delay, func() {
+			p.mu.Lock()
+			pending := p.retry == r && p.currentTrk == nil
+			p.mu.Unlock()
+			if pending {
+				p.playTrackFrom(track, pos)
+			}
+		})
+		return
+	}
+
+	p.writeNowPlaying(nil)
+	if offline {
+		p.stop()
+		p.updateNowPlaying(fmt.Sprintf("[red]Could not reconnect[-] - %s\n[white]%s[-]\n[gray]Stopped; press Enter on it to try again[-]", why, track.Title))
+		return
+	}
+	if streak >= maxFailStreak {
+		p.stop()
+		p.updateNowPlaying(fmt.Sprintf("[red]Playback failed for %d tracks in a row[-] - %s\n[gray]Stopped; check your connection[-]", streak, why))
+		return
+	}
+	p.updateNowPlaying(fmt.Sprintf("[red]Skipping[-] %s [gray](%s)[-]", track.Title, why))
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		p.next()
+	}()
+}
+
+// cutShort reports whether track, stopped at pos seconds without an error,
+// was cut off, as happens to streams when the connection drops: live
+// streams never end, and YouTube videos stop near their end. Durations of
+// other tracks may not be those of what was played, e.g. the YouTube match
+// of a Spotify track, and aren't trusted.
+func cutShort(track provider.Track, pos float64) bool {
+	if onDisk(track) {
+		return false
+	}
+	if track.IsStream {
+		return true
+	}
+	video := strings.HasPrefix(track.ID, "youtube:")
+	return video && track.Duration > 0 && pos < float64(track.Duration-cutShortMargin)
+}
+
+// unreachable reports whether the host track streams from, or the proxy
+// configured, can't be connected to.
+func (p *player) unreachable(track provider.Track) bool {
+	addr := "www.youtube.com:443"
+	if u, err := url.Parse(track.Links["url"]); err == nil && u.Host != "" {
+		addr = hostPort(u)
+	}
+	if u, err := url.Parse(p.config().ProxyFor("player")); err == nil && u.Host != "" {
+		addr = hostPort(u)
+	}
+	conn, err := net.DialTimeout("tcp", addr, probeTimeout)
+	if err != nil {
+		return true
+	}
+	conn.Close()
+	return false
+}
+
+// hostPort returns the host and port to connect to for u.
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "80"
+	switch u.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h", "socks4", "socks4a":
+		port = "1080"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// retrying reports whether track is being retried after failing.
+func (p *player) retrying(track provider.Track) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.retry != nil && p.retry.track.ID == track.ID
+}
+
+// cancelRetry drops a pending retry, e.g. when playback is stopped.
+func (p *player) cancelRetry() {
+	p.mu.Lock()
+	p.retry = nil
+	p.mu.Unlock()
+}
+
+// reconnecting reports whether playback is waiting on the network to come
+// back. Must be called with p.mu held.
+func (p *player) reconnecting() bool {
+	return p.retry != nil && p.retry.offline && p.currentTrk == nil
+}
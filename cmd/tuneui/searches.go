@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// rememberSearch adds query to the search history.
+func (p *player) rememberSearch(query string) {
+	if p.searches == nil {
+		return
+	}
+	p.searchRecall = -1
+	go func() {
+		if err := p.searches.Add(query); err != nil {
+			p.notify(fmt.Sprintf("[red]Search history error:[-] %v", err))
+		}
+	}()
+}
+
+// recallSearch steps through the search history in the search box like a
+// shell: older with step 1 (Up), newer with -1 (Down), back to the text
+// being typed past the newest. Called from the UI goroutine.
+func (p *player) recallSearch(step int) {
+	if p.searches == nil {
+		return
+	}
+	recent := p.searches.Recent()
+	if p.searchRecall < 0 {
+		p.searchDraft = p.searchView.GetText()
+	}
+	idx := p.searchRecall + step
+	switch {
+	case idx >= len(recent):
+		return
+	case idx < 0:
+		p.searchRecall = -1
+		p.searchView.SetText(p.searchDraft)
+		return
+	}
+	p.searchRecall = idx
+	p.searchView.SetText(recent[idx])
+}
+
+// showSearchHistory opens a fuzzy finder over the search history: typing
+// narrows it down, Enter searches the selected query again and Tab only
+// copies it into the search box. Called from the UI goroutine.
+func (p *player) showSearchHistory() {
+	const name = "searchhistory"
+	if p.searches == nil {
+		return
+	}
+
+	input := tview.NewInputField()
+	input.SetLabel(" Find: ")
+	input.SetFieldWidth(0)
+	input.SetFieldBackgroundColor(p.theme.Field)
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetHighlightFullLine(true)
+	list.SetSelectedBackgroundColor(p.theme.Selection)
+	list.SetSelectedTextColor(p.theme.SelectionText)
+
+	var matches []string
+	refresh := func(pattern string) {
+		matches = p.searches.Match(pattern)
+		list.Clear()
+		for _, q := range matches {
+			list.AddItem(tview.Escape(q), "", 0, nil)
+		}
+		if len(matches) == 0 {
+			list.AddItem("[gray]No matching searches[-]", "", 0, nil)
+		}
+	}
+	refresh("")
+	input.SetChangedFunc(refresh)
+
+	pick := func(search bool) {
+		idx := list.GetCurrentItem()
+		if idx < 0 || idx >= len(matches) {
+			return
+		}
+		query := matches[idx]
+		p.hideModal(name)
+		p.searchView.SetText(query)
+		p.focusIdx = 0
+		p.app.SetFocus(p.searchView)
+		if search {
+			p.performSearch(query)
+		}
+	}
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			p.hideModal(name)
+			return nil
+		case tcell.KeyEnter:
+			pick(true)
+			return nil
+		case tcell.KeyTab:
+			pick(false)
+			return nil
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyCtrlR:
+			// Move through the matches while typing
+			if event.Key() == tcell.KeyCtrlR {
+				event = tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			}
+			if handler := list.InputHandler(); handler != nil {
+				handler(event, func(tview.Primitive) {})
+			}
+			return nil
+		}
+		return event
+	})
+
+	box := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	box.SetBorder(true).SetTitle(" Search history [Enter=Search, Tab=Edit, Esc=Close] ")
+	p.showModal(name, box, 70, 20)
+}
+
+// clearSearchCache forgets the cached search results, so searching again
+// asks the providers for fresh ones. Called from the UI goroutine.
+func (p *player) clearSearchCache() {
+	p.providers.ClearCache()
+	p.showNotice("[green]✓ Search cache cleared[-]")
+}
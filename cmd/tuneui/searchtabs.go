@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"audictl/internal/provider"
+	"audictl/internal/store"
+)
+
+// resultsHelp lists the result keys in the results title.
+const resultsHelp = "[Enter=Play, a=Queue, *=Pin]"
+
+// searchTab is the results one provider returned for the last search.
+type searchTab struct {
+	provider string
+	tracks   []provider.Track
+}
+
+// mergeTabs returns the results of all tabs as one list, provider by
+// provider.
+func mergeTabs(tabs []searchTab) []provider.Track {
+	var all []provider.Track
+	for _, t := range tabs {
+		all = append(all, t.tracks...)
+	}
+	return all
+}
+
+// selectSearchTab shows the results of one provider, or of all of them
+// for tab 0. Must be called with p.mu held.
+func (p *player) selectSearchTab(tab int) bool {
+	if len(p.searchTabs) < 2 || tab < 0 || tab > len(p.searchTabs) || tab == p.searchTab {
+		return false
+	}
+	p.searchTab = tab
+	if tab == 0 {
+		p.searchRes = p.searchAll
+	} else {
+		p.searchRes = p.searchTabs[tab-1].tracks
+	}
+	return true
+}
+
+// switchSearchTab switches the results panel to tab (see selectSearchTab).
+func (p *player) switchSearchTab(tab int) {
+	p.mu.Lock()
+	changed := p.selectSearchTab(tab)
+	p.mu.Unlock()
+	if changed {
+		p.updateResultsView()
+	}
+}
+
+// resultsTitle returns the title of the results panel, with a tab strip
+// when more than one provider returned results. Must be called with p.mu
+// held.
+func (p *player) resultsTitle() string {
+	if len(p.searchTabs) < 2 {
+		return " Results " + resultsHelp + " "
+	}
+	tab := func(i int, label string) string {
+		if i == p.searchTab {
+			return "[yellow::b]" + label + "[-::-]"
+		}
+		return label
+	}
+	parts := []string{tab(0, fmt.Sprintf("0 All (%d)", len(p.searchAll)))}
+	for i, t := range p.searchTabs {
+		parts = append(parts, tab(i+1, fmt.Sprintf("%d %s (%d)", i+1, tview.Escape(t.provider), len(t.tracks))))
+	}
+	return " Results: " + strings.Join(parts, " │ ") + " " + resultsHelp + " "
+}
+
+// resultBadge marks which provider a result came from in the merged list.
+// Must be called with p.mu held.
+func (p *player) resultBadge(track provider.Track) string {
+	if len(p.searchTabs) < 2 || p.searchTab != 0 {
+		return ""
+	}
+	return "[gray]" + tview.Escape("["+track.Provider+"]") + "[-] "
+}
+
+// rankTab puts the tracks pinned for query first in the results of one
+// provider, leaving out the pins of other providers.
+func rankTab(pins *store.Pins, query string, tab searchTab) []provider.Track {
+	var ranked []provider.Track
+	for _, t := range pins.Rank(query, tab.tracks) {
+		if t.Provider == tab.provider {
+			ranked = append(ranked, t)
+		}
+	}
+	return ranked
+}
+
+// searchKind splits a "yt:music" or "yt:videos" token off query, returning
+// the remaining query and what to search for: songs from YouTube Music, or
+// videos, as youtube_search in the config says when there is no token.
+func (p *player) searchKind(query string) (string, provider.SearchKind) {
+	kind := provider.SearchKindTrack
+	if p.config().YouTubeSearch == "music" {
+		kind = provider.SearchKindSong
+	}
+	var rest []string
+	for _, field := range strings.Fields(query) {
+		switch strings.ToLower(field) {
+		case "yt:music":
+			kind = provider.SearchKindSong
+		case "yt:videos":
+			kind = provider.SearchKindTrack
+		default:
+			rest = append(rest, field)
+		}
+	}
+	return strings.Join(rest, " "), kind
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"audictl/internal/provider"
+	"audictl/internal/session"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showSessions opens the session browser. Enter restores the selected
+// session, 'n' saves the current state as a new one (or over an existing
+// name) and 'd' deletes the selection.
+// Must be called from the UI goroutine.
+func (p *player) showSessions() {
+	const name = "sessions"
+	sessions, err := session.List()
+	if err != nil {
+		p.showNotice(fmt.Sprintf("[red]Session error:[-] %v", err))
+		return
+	}
+
+	view := tview.NewList().ShowSecondaryText(false)
+	view.SetBorder(true).SetTitle(" Sessions [Enter=Restore, n=Save current, d=Delete, Esc=Close] ")
+	view.SetHighlightFullLine(true)
+	view.SetSelectedBackgroundColor(p.theme.Selection)
+	view.SetSelectedTextColor(p.theme.SelectionText)
+
+	render := func() {
+		view.Clear()
+		for _, s := range sessions {
+			when := s.Saved.Local().Format("Jan 02 15:04")
+			view.AddItem(fmt.Sprintf("%s [gray](%d tracks, saved %s)[-]", tview.Escape(s.Name), len(s.Queue), when), "", 0, nil)
+		}
+		if len(sessions) == 0 {
+			view.AddItem("[gray]No sessions yet - press n to save the current one[-]", "", 0, nil)
+		}
+	}
+	render()
+
+	view.SetSelectedFunc(func(idx int, _ string, _ string, _ rune) {
+		if idx < 0 || idx >= len(sessions) {
+			return
+		}
+		p.hideModal(name)
+		go p.restoreSession(sessions[idx])
+	})
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			p.hideModal(name)
+			return nil
+		}
+		switch event.Rune() {
+		case 'n', 'N':
+			p.hideModal(name)
+			p.promptSaveSession()
+			return nil
+		case 'd', 'D':
+			idx := view.GetCurrentItem()
+			if idx < 0 || idx >= len(sessions) {
+				return nil
+			}
+			if err := session.Delete(sessions[idx].Name); err != nil {
+				p.showNotice(fmt.Sprintf("[red]Session error:[-] %v", err))
+				return nil
+			}
+			p.showNotice(fmt.Sprintf("[yellow]- Deleted session:[-] %s", sessions[idx].Name))
+			sessions = append(sessions[:idx], sessions[idx+1:]...)
+			render()
+			return nil
+		}
+		return event
+	})
+
+	p.showModal(name, view, 80, 20)
+}
+
+// promptSaveSession asks for a name and snapshots the player under it.
+// Must be called from the UI goroutine.
+func (p *player) promptSaveSession() {
+	p.prompt("Save session", " Name: ", "", func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		go func() {
+			s := p.snapshot()
+			s.Name = name
+			if err := session.Save(s); err != nil {
+				p.notify(fmt.Sprintf("[red]Session error:[-] %v", err))
+				return
+			}
+			p.notify(fmt.Sprintf("[green]✓ Saved session:[-] %s (%d tracks)", name, len(s.Queue)))
+		}()
+	})
+}
+
+// snapshot captures the current player state. The player is asked for the
+// position and volume when it is running.
+func (p *player) snapshot() *session.Session {
+	p.mu.Lock()
+	s := &session.Session{
+		Queue:         append([]provider.Track(nil), p.queue...),
+		QueueIdx:      p.queueIdx,
+		Playing:       p.currentTrk != nil,
+		Muted:         p.muted,
+		Video:         p.video,
+		Normalize:     p.normalize,
+		ShowRemaining: p.showRemaining,
+		Device:        p.device,
+	}
+	b := p.backend
+	p.mu.Unlock()
+
+	if b != nil {
+		if v, err := b.Volume(); err == nil {
+			s.Volume = v
+		}
+		if s.Playing {
+			if pos, err := b.Position(); err == nil {
+				s.Position = pos
+			}
+		}
+	}
+	return s
+}
+
+// restoreSession replaces the player state with s and resumes the track
+// that was playing at the saved position.
+func (p *player) restoreSession(s session.Session) {
+	p.stop()
+
+	p.mu.Lock()
+	p.queue = append([]provider.Track{}, s.Queue...)
+	p.queueIdx = s.QueueIdx
+	if p.queueIdx >= len(p.queue) {
+		p.queueIdx = -1
+	}
+	p.muted = s.Muted
+	p.video = s.Video
+	p.normalize = s.Normalize
+	p.showRemaining = s.ShowRemaining
+	deviceChanged := p.device != s.Device
+	p.device = s.Device
+	m := p.mpvCtl()
+	p.mu.Unlock()
+
+	if m != nil && deviceChanged {
+		_ = m.SetDevice(s.Device)
+	}
+	if err := p.ensurePlayer(); err != nil {
+		p.notify(fmt.Sprintf("[red]Player error:[-] %v", err))
+		return
+	}
+	p.mu.Lock()
+	b := p.backend
+	m = p.mpvCtl()
+	p.mu.Unlock()
+	if b == nil {
+		return
+	}
+	if m != nil {
+		_ = m.SetVideo(s.Video)
+	}
+	_ = b.SetMute(s.Muted)
+	if s.Volume > 0 && !p.config().BitPerfect {
+		_ = b.SetVolume(s.Volume)
+	}
+	p.applyNormalize()
+	p.updateQueueView()
+
+	if s.Playing && p.queueIdx >= 0 {
+		p.playTrackFrom(s.Queue[p.queueIdx], s.Position)
+		return
+	}
+	p.notify(fmt.Sprintf("[green]Restored session:[-] %s (%d tracks)", s.Name, len(s.Queue)))
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"audictl/internal/desktop"
+	"audictl/internal/provider"
+)
+
+// selectedTrack returns the track commands such as copying its link act
+// on: the one under the cursor in the focused list, otherwise the playing
+// track.
+func (p *player) selectedTrack() (provider.Track, bool) {
+	focused := p.app.GetFocus()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch focused {
+	case p.resultsView:
+		if idx := p.resultsView.GetCurrentItem(); idx >= 0 && idx < len(p.searchRes) {
+			return p.searchRes[idx], true
+		}
+	case p.queueView:
+		if idx := p.queueView.GetCurrentItem(); idx >= 0 && idx < len(p.queue) {
+			return p.queue[idx], true
+		}
+	}
+	if p.currentTrk != nil {
+		return *p.currentTrk, true
+	}
+	return provider.Track{}, false
+}
+
+// sourceURL returns the page track came from, preferring the service it
+// was found on over the YouTube video it plays through.
+func sourceURL(t provider.Track) string {
+	for _, key := range []string{"spotify", "youtube", "url"} {
+		if u := t.Links[key]; u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// copyLink copies the source URL of the selected or playing track.
+func (p *player) copyLink() {
+	track, ok := p.selectedTrack()
+	if !ok || sourceURL(track) == "" {
+		p.notify("[yellow]No link to copy[-]")
+		return
+	}
+	link := sourceURL(track)
+	if err := desktop.Copy(link); err != nil {
+		p.notify(fmt.Sprintf("[red]Copy failed:[-] %v", err))
+		return
+	}
+	p.notify(fmt.Sprintf("[green]Copied:[-] %s", link))
+}
+
+// openLink opens the source URL of the selected or playing track in the
+// default browser.
+func (p *player) openLink() {
+	track, ok := p.selectedTrack()
+	if !ok || sourceURL(track) == "" {
+		p.notify("[yellow]No link to open[-]")
+		return
+	}
+	link := sourceURL(track)
+	if err := desktop.Open(link); err != nil {
+		p.notify(fmt.Sprintf("[red]Open failed:[-] %v", err))
+		return
+	}
+	p.notify(fmt.Sprintf("[green]Opened:[-] %s", link))
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/snapcast"
+)
+
+// snapVolumeStep is the client volume change per key press, in percent.
+const snapVolumeStep = 5
+
+// showSnapcast lists the clients of the snapserver: ←/→ change the
+// selected client's volume, m mutes it, r reloads the list.
+// Must be called from the UI goroutine.
+func (p *player) showSnapcast() {
+	const name = "snapcast"
+	cfg := p.config().Snapcast
+	if cfg.Pipe == "" && cfg.Server == "" {
+		p.showNotice("[yellow]Set snapcast.pipe in the config to play through a snapserver[-]")
+		return
+	}
+	server := snapcast.New(cfg.Server)
+
+	view := tview.NewList().ShowSecondaryText(false)
+	view.SetBorder(true).SetTitle(" Snapcast clients [←→=Volume, m=Mute, r=Reload, Esc=Close] ")
+	view.SetHighlightFullLine(true)
+	view.SetSelectedBackgroundColor(p.theme.Selection)
+	view.SetSelectedTextColor(p.theme.SelectionText)
+
+	var clients []snapcast.Client
+	render := func() {
+		current := view.GetCurrentItem()
+		view.Clear()
+		for _, c := range clients {
+			view.AddItem(snapClientLine(c), "", 0, nil)
+		}
+		if len(clients) == 0 {
+			view.AddItem("[gray]No clients[-]", "", 0, nil)
+		}
+		view.SetCurrentItem(current)
+	}
+	reload := func() {
+		go func() {
+			list, err := server.Clients()
+			p.app.QueueUpdateDraw(func() {
+				if err != nil {
+					clients = nil
+					view.Clear()
+					view.AddItem("[red]"+tview.Escape(err.Error())+"[-]", "", 0, nil)
+					return
+				}
+				clients = list
+				render()
+			})
+		}()
+	}
+	// change applies fn to the selected client on the server, then shows
+	// the result
+	change := func(fn func(c *snapcast.Client)) {
+		idx := view.GetCurrentItem()
+		if idx < 0 || idx >= len(clients) {
+			return
+		}
+		fn(&clients[idx])
+		c := clients[idx]
+		render()
+		go func() {
+			if err := server.SetVolume(c.ID, c.Volume, c.Muted); err != nil {
+				p.notify(fmt.Sprintf("[red]Snapcast error:[-] %v", err))
+				reload()
+			}
+		}()
+	}
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			p.hideModal(name)
+			return nil
+		case tcell.KeyLeft, tcell.KeyRight:
+			step := snapVolumeStep
+			if event.Key() == tcell.KeyLeft {
+				step = -step
+			}
+			change(func(c *snapcast.Client) { c.Volume = max(0, min(100, c.Volume+step)) })
+			return nil
+		}
+		switch event.Rune() {
+		case 'm', 'M':
+			change(func(c *snapcast.Client) { c.Muted = !c.Muted })
+			return nil
+		case 'r', 'R':
+			reload()
+			return nil
+		}
+		return event
+	})
+
+	view.AddItem("[gray]Asking the snapserver...[-]", "", 0, nil)
+	reload()
+	p.showModal(name, view, 70, 16)
+}
+
+// snapClientLine shows a client with its volume as a bar.
+func snapClientLine(c snapcast.Client) string {
+	const width = 20
+	filled := c.Volume * width / 100
+	bar := "[aqua]" + strings.Repeat("━", filled) + "[-]" + strings.Repeat("─", width-filled)
+	level := fmt.Sprintf("%3d%%", c.Volume)
+	if c.Muted {
+		level = "[yellow]Muted[-]"
+	}
+	line := fmt.Sprintf("%s %s %s", tview.Escape(pad(c.Name, 24)), bar, level)
+	if !c.Connected {
+		line += " [gray](offline)[-]"
+	}
+	return line
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"audictl/internal/provider"
+)
+
+const (
+	speedStep = 0.1
+	minSpeed  = 0.5
+	maxSpeed  = 3.0
+)
+
+// changeSpeed speeds playback up or down by delta; 0 resets to normal.
+func (p *player) changeSpeed(delta float64) {
+	if msg := p.mpvMissing("Speed control"); msg != "" {
+		p.notify(msg)
+		return
+	}
+	p.mu.Lock()
+	// Rebase the position so the progress bar doesn't jump
+	p.posBase = p.position()
+	p.posAt = time.Now()
+	speed := 1.0
+	if delta != 0 {
+		speed = math.Round((p.speed+delta)*100) / 100
+		speed = math.Max(minSpeed, math.Min(maxSpeed, speed))
+	}
+	p.speed = speed
+	p.mu.Unlock()
+
+	p.applySpeed()
+	if speed == 1 {
+		p.notify("[green]▶ Normal speed[-]")
+	} else {
+		p.notify(fmt.Sprintf("[green]⏩ Speed %gx[-]", speed))
+	}
+}
+
+// applySpeed pushes the speed and pitch correction setting to mpv.
+func (p *player) applySpeed() {
+	p.mu.Lock()
+	speed := p.speed
+	m := p.mpvCtl()
+	p.mu.Unlock()
+	if m == nil {
+		// ensurePlayer applies it once mpv is up
+		return
+	}
+	_ = m.SetPitchCorrection(p.config().KeepPitch())
+	_ = m.SetSpeed(speed)
+}
+
+// position estimates the playback position of the current track in seconds
+// from the last known one. Must be called with p.mu held.
+func (p *player) position() float64 {
+	if p.paused {
+		return p.posBase
+	}
+	return p.posBase + time.Since(p.posAt).Seconds()*p.speed
+}
+
+// syncPosition replaces the estimated position with the player's own,
+// which also reflects seeks and pauses.
+func (p *player) syncPosition(track provider.Track) {
+	b := p.out()
+	if b == nil {
+		return
+	}
+	pos, err := b.Position()
+	if err != nil {
+		return
+	}
+	paused, _ := b.Paused()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.currentTrk == nil || p.currentTrk.ID != track.ID {
+		return
+	}
+	p.posBase = pos
+	p.posAt = time.Now()
+	p.paused = paused
+}
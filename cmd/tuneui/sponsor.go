@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"audictl/internal/provider"
+	"audictl/internal/sponsorblock"
+	yprov "audictl/providers/youtube"
+)
+
+// trackSegments are the SponsorBlock segments of the playing track.
+type trackSegments struct {
+	trackID  string
+	segments []sponsorblock.Segment
+	skipped  []bool // skipped[i] once segments[i] was skipped, so seeking back into it plays it
+}
+
+// youtubeID returns the YouTube video ID of track, or "".
+func youtubeID(track provider.Track) string {
+	if id, ok := strings.CutPrefix(track.ID, "youtube:"); ok {
+		return id
+	}
+	return yprov.VideoID(track.Links["youtube"])
+}
+
+// loadSegments fetches the SponsorBlock segments of track when it's a
+// YouTube video and SponsorBlock is enabled, then skips them as they come
+// up while track plays.
+func (p *player) loadSegments(track provider.Track) {
+	sb := p.config().SponsorBlock
+	id := youtubeID(track)
+	if !sb.Enabled || id == "" {
+		return
+	}
+	segments, err := sponsorblock.Segments(id, sb.Categories)
+	if err != nil || len(segments) == 0 {
+		// Not worth interrupting playback over
+		return
+	}
+	s := &trackSegments{trackID: track.ID, segments: segments, skipped: make([]bool, len(segments))}
+	p.mu.Lock()
+	if p.currentTrk == nil || p.currentTrk.ID != track.ID {
+		p.mu.Unlock()
+		return
+	}
+	p.segments = s
+	p.mu.Unlock()
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	for range ticker.C {
+		p.mu.Lock()
+		if p.segments != s || p.currentTrk == nil {
+			// Stopped, or another track started
+			p.mu.Unlock()
+			return
+		}
+		seg, skip := p.segmentAt(p.position())
+		p.mu.Unlock()
+		if skip {
+			p.skipSegment(track, seg)
+		}
+	}
+}
+
+// segmentAt returns the segment of the playing track that pos falls into
+// if it hasn't been skipped yet, marking it skipped. Must be called with
+// p.mu held.
+func (p *player) segmentAt(pos float64) (sponsorblock.Segment, bool) {
+	s := p.segments
+	if s == nil {
+		return sponsorblock.Segment{}, false
+	}
+	for i, seg := range s.segments {
+		// Too close to the end to be worth a seek
+		if !s.skipped[i] && pos >= seg.Start && pos < seg.End-1 {
+			s.skipped[i] = true
+			return seg, true
+		}
+	}
+	return sponsorblock.Segment{}, false
+}
+
+// skipSegment seeks past seg of the playing track.
+func (p *player) skipSegment(track provider.Track, seg sponsorblock.Segment) {
+	b := p.out()
+	if b == nil {
+		return
+	}
+	if err := b.SeekTo(seg.End); err != nil {
+		return
+	}
+	p.mu.Lock()
+	p.posBase = seg.End
+	p.posAt = time.Now()
+	p.mu.Unlock()
+	p.updateNowPlaying(fmt.Sprintf("%s\n[gray]Skipped %s (%s)[-]", nowPlayingText(track),
+		sponsorblock.Label(seg.Category), clock(seg.End-seg.Start)))
+}
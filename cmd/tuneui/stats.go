@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/history"
+)
+
+// statsTop is how many artists and tracks the stats screen ranks.
+const statsTop = 10
+
+// statsPeriod is a span the stats screen can sum up.
+type statsPeriod struct {
+	key   rune
+	label string
+	days  int // 0 for all time
+}
+
+var statsPeriods = []statsPeriod{
+	{'w', "This week", 7},
+	{'m', "This month", 30},
+	{'a', "All time", 0},
+}
+
+// showStats opens the listening statistics: plays, skips and time listened
+// over the last week, month or all time, with the top artists and tracks.
+// w, m and a switch the period. Must be called from the UI goroutine.
+func (p *player) showStats() {
+	const name = "stats"
+	entries, err := history.All()
+	if err != nil {
+		p.showNotice(fmt.Sprintf("[red]History error:[-] %v", err))
+		return
+	}
+
+	view := tview.NewTextView()
+	view.SetDynamicColors(true)
+	view.SetBorder(true).SetTitle(" Stats [w=Week, m=Month, a=All time, Esc=Close] ")
+	view.SetBorderPadding(0, 0, 1, 1)
+
+	show := func(period statsPeriod) {
+		var since time.Time
+		if period.days > 0 {
+			since = time.Now().AddDate(0, 0, -period.days)
+		}
+		view.SetText(p.dataUsedText() + statsText(period.label, history.Summarize(entries, since)))
+		view.ScrollToBeginning()
+	}
+	show(statsPeriods[0])
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			p.hideModal(name)
+			return nil
+		}
+		for _, period := range statsPeriods {
+			if event.Rune() == period.key {
+				show(period)
+				return nil
+			}
+		}
+		return event
+	})
+	p.showModal(name, view, 90, 30)
+}
+
+// statsText renders s for the stats screen.
+func statsText(label string, s history.Stats) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow]%s[-]\n\n", label)
+	if s.Plays == 0 {
+		b.WriteString("[gray]Nothing played in this period[-]")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "  [green]%s[-] listened   [green]%d[-] plays   [green]%d[-] skipped   [green]%d[-] artists\n",
+		hoursMinutes(s.Listened), s.Plays, s.Skips, len(s.Artists))
+
+	section := func(title string, counts []history.Count) {
+		fmt.Fprintf(&b, "\n[yellow]%s[-]\n", title)
+		for i, c := range counts {
+			if i == statsTop {
+				break
+			}
+			skips := ""
+			if c.Skips > 0 {
+				skips = fmt.Sprintf(", %d skipped", c.Skips)
+			}
+			fmt.Fprintf(&b, "  %2d. %s [gray]%d plays, %s%s[-]\n",
+				i+1, tview.Escape(pad(truncate(c.Name, 48), 48)), c.Plays, hoursMinutes(c.Listened), skips)
+		}
+	}
+	section("Top artists", s.Artists)
+	section("Top tracks", s.Tracks)
+	return b.String()
+}
+
+// hoursMinutes formats d as "3h 05m", or "5m" under an hour.
+func hoursMinutes(d time.Duration) string {
+	mins := int(d.Round(time.Minute).Minutes())
+	if mins == 0 && d > 0 {
+		return "<1m"
+	}
+	if mins < 60 {
+		return fmt.Sprintf("%dm", mins)
+	}
+	return fmt.Sprintf("%dh %02dm", mins/60, mins%60)
+}
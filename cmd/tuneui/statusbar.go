@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+const (
+	// statusInterval is how often the status bar polls the player.
+	statusInterval = 500 * time.Millisecond
+	// noticeTime is how long a notice stays in the status bar.
+	noticeTime = 5 * time.Second
+	// volumeStep is the volume change per key press, in percent.
+	volumeStep = 5
+	maxVolume  = 130
+)
+
+// followStatus keeps the status bar up to date with the player state.
+func (p *player) followStatus() {
+	defer p.recoverPanic()
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+	shown := ""
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.statusKick:
+		}
+		st := p.status()
+		if p.miniMode() {
+			line := p.miniLine(st)
+			p.app.QueueUpdateDraw(func() {
+				p.mini = line
+				p.drawMini()
+			})
+			continue
+		}
+		text := st.String()
+		if text == shown {
+			continue
+		}
+		shown = text
+		p.app.QueueUpdateDraw(func() {
+			p.statusView.SetText(text)
+		})
+	}
+}
+
+// refreshStatus updates the status bar now rather than at the next poll.
+func (p *player) refreshStatus() {
+	select {
+	case p.statusKick <- struct{}{}:
+	default:
+	}
+}
+
+// playerStatus is what the status bar shows, each part with color tags.
+type playerStatus struct {
+	icon   string // "▶", "⏸", ...
+	state  string // icon and word, "▶ Playing"
+	volume string // "Vol 80%", "Muted" or "" without a player
+	zone   string // the zone playing, "" when the output isn't one
+	modes  string // the playback modes that are on
+}
+
+func (s playerStatus) String() string {
+	parts := []string{s.state}
+	if s.volume != "" {
+		parts = append(parts, s.volume)
+	}
+	if s.zone != "" {
+		parts = append(parts, s.zone)
+	}
+	parts = append(parts, s.modes)
+	return " " + strings.Join(parts, " │ ")
+}
+
+// status reads the player state for the status bar: playing, paused or
+// buffering, the volume, the zone, and the playback modes that are on.
+func (p *player) status() playerStatus {
+	p.mu.Lock()
+	b := p.backend
+	m := p.mpvCtl()
+	playing := p.currentTrk != nil
+	loading := p.stopSpinner != nil && !p.searching
+	reconnecting := p.reconnecting()
+	paused := p.paused
+	muted := p.muted
+	speed := p.speed
+	repeat := p.repeat
+	shuffle := p.shuffle
+	radio := p.radio
+	looping := p.loopA >= 0 || p.loopB >= 0
+	normalize := p.normalize
+	video := p.video
+	lowData := p.lowData
+	device := p.device
+	p.mu.Unlock()
+
+	var st playerStatus
+	if zone := zoneOf(p.config(), device); zone != "" {
+		st.zone = "🔈 " + tview.Escape(zone)
+	}
+	setState := func(color, icon, word string) {
+		st.icon = "[" + color + "]" + icon + "[-]"
+		st.state = "[" + color + "]" + icon + " " + word + "[-]"
+	}
+	setState("gray", "■", "Stopped")
+	switch {
+	case reconnecting:
+		setState("yellow", "⟳", "Reconnecting")
+	case loading:
+		setState("yellow", "…", "Loading")
+	case playing:
+		if b != nil {
+			if pz, err := b.Paused(); err == nil {
+				paused = pz
+			}
+		}
+		buffering := false
+		if m != nil {
+			if v, err := m.GetProperty("paused-for-cache"); err == nil {
+				buffering, _ = v.(bool)
+			}
+		}
+		switch {
+		case paused:
+			setState("yellow", "⏸", "Paused")
+		case buffering:
+			setState("yellow", "…", "Buffering")
+		default:
+			setState("green", "▶", "Playing")
+		}
+	}
+
+	if muted {
+		st.volume = "[yellow]Muted[-]"
+	} else if b != nil {
+		if v, err := b.Volume(); err == nil {
+			st.volume = fmt.Sprintf("Vol %d%%", int(math.Round(v)))
+		}
+	}
+
+	modes := []string{"Repeat " + repeat.String()}
+	if shuffle {
+		modes = append(modes, "Shuffle")
+	}
+	if radio {
+		modes = append(modes, "Radio")
+	}
+	if speed != 1 {
+		modes = append(modes, fmt.Sprintf("%gx", speed))
+	}
+	if looping {
+		modes = append(modes, "A-B")
+	}
+	if normalize {
+		modes = append(modes, "Normalize")
+	}
+	if video {
+		modes = append(modes, "Video")
+	}
+	if lowData {
+		modes = append(modes, "Low data")
+	}
+	if p.config().Snapcast.Pipe != "" {
+		modes = append(modes, "Snapcast")
+	}
+	st.modes = "[gray]" + strings.Join(modes, " · ") + "[-]"
+	return st
+}
+
+// notify shows a notice, such as an error or a confirmation, in the status
+// bar for a few seconds. Messages over several lines are joined into one.
+func (p *player) notify(text string) {
+	p.app.QueueUpdateDraw(func() {
+		p.showNotice(text)
+	})
+}
+
+// showNotice is notify for the UI goroutine.
+func (p *player) showNotice(text string) {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	p.noticeSeq++
+	seq := p.noticeSeq
+	p.noticeView.SetText(strings.Join(lines, " ") + " ")
+	p.drawMini()
+	time.AfterFunc(noticeTime, func() {
+		p.app.QueueUpdateDraw(func() {
+			if p.noticeSeq == seq {
+				p.noticeView.SetText("")
+				p.drawMini()
+			}
+		})
+	})
+}
+
+// changeVolume raises or lowers the volume by delta percent; the status bar
+// shows the result.
+func (p *player) changeVolume(delta float64) {
+	b := p.out()
+	if b == nil {
+		p.notify("[yellow]Nothing is playing[-]")
+		return
+	}
+	v, err := b.Volume()
+	if err != nil {
+		p.notify(fmt.Sprintf("[red]Volume error:[-] %v", err))
+		return
+	}
+	v = math.Max(0, math.Min(maxVolume, math.Round(v+delta)))
+	if err := b.SetVolume(v); err != nil {
+		p.notify(fmt.Sprintf("[red]Volume error:[-] %v", err))
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/config"
+	"audictl/internal/theme"
+)
+
+// styledBox is what the primitives share of tview.Box's colors.
+type styledBox interface {
+	SetBackgroundColor(tcell.Color) *tview.Box
+	SetBorderColor(tcell.Color) *tview.Box
+	SetTitleColor(tcell.Color) *tview.Box
+}
+
+// reloadTheme resolves the theme cfg names. "auto" keeps the one picked
+// at startup: asking the terminal again would race the UI for its input.
+func (p *player) reloadTheme(cfg *config.Config) (theme.Theme, error) {
+	name := cfg.Theme
+	if name == "" || name == "auto" {
+		name = p.autoTheme
+	}
+	if name == "" {
+		name = "dark"
+	}
+	return theme.Resolve(name, cfg.Themes)
+}
+
+// setTheme makes t the theme, recoloring the panels already built. Called
+// from the UI goroutine, as it changes the colors draws read.
+func (p *player) setTheme(t theme.Theme) {
+	p.theme = t
+	t.Apply()
+	// Panels the layout leaves out for now are restyled too
+	for _, prim := range []tview.Primitive{p.pages, p.miniView, p.consoleView, p.vizView} {
+		restyle(prim, t)
+	}
+}
+
+// restyle recolors prim and the primitives inside it with the colors
+// theme.Apply gives new ones. Text views read their color tags again
+// once their text color is set.
+func restyle(prim tview.Primitive, t theme.Theme) {
+	if b, ok := prim.(styledBox); ok {
+		b.SetBackgroundColor(t.Background)
+		b.SetBorderColor(t.Border)
+		b.SetTitleColor(t.Title)
+	}
+	switch v := prim.(type) {
+	case *tview.Flex:
+		for i := range v.GetItemCount() {
+			restyle(v.GetItem(i), t)
+		}
+	case *tview.Pages:
+		for _, name := range v.GetPageNames(false) {
+			restyle(v.GetPage(name), t)
+		}
+	case *tview.List:
+		v.SetMainTextColor(t.Text)
+		v.SetSecondaryTextColor(t.Warning)
+		v.SetShortcutColor(t.Accent)
+		v.SetSelectedBackgroundColor(t.Selection)
+		v.SetSelectedTextColor(t.SelectionText)
+	case *tview.TextView:
+		v.SetTextColor(t.Text)
+	case *tview.InputField:
+		v.SetLabelColor(t.Warning)
+		v.SetFieldTextColor(t.Text)
+		v.SetFieldBackgroundColor(t.Field)
+	}
+}
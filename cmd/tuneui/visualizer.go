@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"audictl/internal/visualizer"
+)
+
+// vizMode is what the visualizer panel shows.
+type vizMode int
+
+const (
+	vizOff vizMode = iota
+	vizBars
+	vizScope
+)
+
+const (
+	// vizHeight is the height of the visualizer panel, borders included.
+	vizHeight = 9
+	// vizFrame is how often the visualizer is redrawn.
+	vizFrame = 50 * time.Millisecond
+)
+
+// barBlocks are the eighths of a cell a bar's top can fill.
+var barBlocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// toggleViz cycles the visualizer between bars, a waveform and off,
+// starting to record the sound when it is turned on. Without a way to
+// record it the panel says what to install. Called from the UI goroutine.
+func (p *player) toggleViz() {
+	switch p.vizMode {
+	case vizOff:
+		p.vizMode = vizBars
+		a, err := visualizer.Start()
+		if err != nil {
+			p.vizErr = err.Error()
+		} else {
+			p.viz, p.vizErr = a, ""
+			p.vizStop = make(chan struct{})
+			go p.animateViz(p.vizStop)
+		}
+	case vizBars:
+		p.vizMode = vizScope
+	default:
+		p.vizMode = vizOff
+		if p.viz != nil {
+			close(p.vizStop)
+			p.viz.Stop()
+			p.viz = nil
+		}
+	}
+	p.vizView.SetTitle(" Visualizer ")
+	if p.vizMode == vizScope {
+		p.vizView.SetTitle(" Waveform ")
+	}
+	p.applyLayout()
+}
+
+// animateViz redraws the visualizer until stop is closed.
+func (p *player) animateViz(stop chan struct{}) {
+	defer p.recoverPanic()
+	ticker := time.NewTicker(vizFrame)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !p.miniMode() {
+				p.app.QueueUpdateDraw(func() {})
+			}
+		}
+	}
+}
+
+// drawViz draws the bars or waveform into the visualizer panel.
+func (p *player) drawViz(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	bx, by, bw, bh := p.vizView.GetInnerRect()
+	msg := p.vizErr
+	if msg == "" && p.viz != nil {
+		if err := p.viz.Err(); err != nil {
+			msg = err.Error()
+		}
+	}
+	if msg != "" {
+		// "what: how to fix it" on two lines
+		lines := strings.SplitN(msg, ": ", 2)
+		for i, line := range lines {
+			tview.Print(screen, "[gray]"+tview.Escape(line)+"[-]", bx, by+(bh-len(lines))/2+i, bw, tview.AlignCenter, tcell.ColorDefault)
+		}
+		return x, y, width, height
+	}
+	if p.viz == nil || bw <= 0 || bh <= 0 {
+		return x, y, width, height
+	}
+	style := tcell.StyleDefault.Foreground(p.theme.Bar).Background(p.theme.Background)
+
+	if p.vizMode == vizScope {
+		mid := float64(bh-1) / 2
+		for i, s := range p.viz.Wave(bw) {
+			row := by + int(mid-s*mid+0.5)
+			screen.SetContent(bx+i, min(max(row, by), by+bh-1), '•', nil, style)
+		}
+		return x, y, width, height
+	}
+
+	// Bars two cells wide with a gap, or one wide when space is short
+	barWidth := 2
+	if bw < 48 {
+		barWidth = 1
+	}
+	n := bw / (barWidth + 1)
+	for i, v := range p.viz.Bars(n) {
+		eighths := int(v*float64(bh*8) + 0.5)
+		for row := 0; row < bh; row++ {
+			fill := min(max(eighths-row*8, 0), 8)
+			if fill == 0 {
+				break
+			}
+			for c := 0; c < barWidth; c++ {
+				screen.SetContent(bx+i*(barWidth+1)+c, by+bh-1-row, barBlocks[fill], nil, style)
+			}
+		}
+	}
+	return x, y, width, height
+}
@@ -2,15 +2,17 @@ module audictl
 
 go 1.24.0
 
-require github.com/google/uuid v1.4.0
+require (
+	github.com/gdamore/tcell/v2 v2.13.8
+	github.com/google/uuid v1.4.0
+	github.com/rivo/tview v0.42.0
+	golang.org/x/term v0.37.0
+)
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
-	github.com/gdamore/tcell/v2 v2.13.8 // indirect
 	github.com/lucasb-eyer/go-colorful v1.3.0 // indirect
-	github.com/rivo/tview v0.42.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/sys v0.38.0 // indirect
-	golang.org/x/term v0.37.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
 )
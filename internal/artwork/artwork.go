@@ -0,0 +1,126 @@
+// Package artwork finds the cover art of tracks — YouTube thumbnails, art
+// embedded in local files or a cover image next to them — and renders it
+// for terminals.
+package artwork
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // thumbnails and most embedded art
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"audictl/internal/netproxy"
+	"audictl/internal/provider"
+	yprov "audictl/providers/youtube"
+)
+
+// ErrNoArt is returned for tracks without cover art.
+var ErrNoArt = errors.New("no cover art")
+
+// maxSize caps downloaded and embedded images.
+const maxSize = 10 << 20
+
+// timeout is how long a cover art download may take.
+const timeout = 10 * time.Second
+
+var client = &http.Client{Timeout: timeout}
+
+// SetProxy makes cover art downloads connect through proxy, "" for the
+// environment's. Call it at startup.
+func SetProxy(proxy string) error {
+	c, err := netproxy.Client(proxy, timeout)
+	if err != nil {
+		return err
+	}
+	client = c
+	return nil
+}
+
+// Fetch returns the cover art of track: the thumbnail of a YouTube video,
+// or for a local file its embedded picture or a cover image in its folder.
+func Fetch(track provider.Track) (image.Image, error) {
+	if src := thumbnail(track); src != "" {
+		return download(src)
+	}
+	if path := localFile(track); path != "" {
+		return local(path)
+	}
+	return nil, ErrNoArt
+}
+
+// thumbnail returns the thumbnail URL of a YouTube track, or "".
+func thumbnail(track provider.Track) string {
+	if id, ok := strings.CutPrefix(track.ID, "youtube:"); ok {
+		return yprov.Thumbnail("https://youtu.be/" + id)
+	}
+	return yprov.Thumbnail(track.Links["youtube"])
+}
+
+// localFile returns the path of a track playing a local file, or "".
+func localFile(track provider.Track) string {
+	location := track.Links["url"]
+	if location == "" {
+		location, _ = strings.CutPrefix(track.ID, "direct:")
+	}
+	if location == "" || strings.Contains(location, "://") {
+		return ""
+	}
+	return location
+}
+
+func download(src string) (image.Image, error) {
+	resp, err := client.Get(src)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cover art: %s", resp.Status)
+	}
+	img, _, err := image.Decode(io.LimitReader(resp.Body, maxSize))
+	return img, err
+}
+
+// coverNames are the folder images used for files without embedded art.
+var coverNames = []string{"cover", "folder", "front", "album"}
+
+func local(path string) (image.Image, error) {
+	if data, err := Embedded(path); err == nil {
+		if img, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+			return img, nil
+		}
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, ErrNoArt
+	}
+	for _, name := range coverNames {
+		for _, e := range entries {
+			ext := strings.ToLower(filepath.Ext(e.Name()))
+			if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+				continue
+			}
+			if !strings.EqualFold(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())), name) {
+				continue
+			}
+			f, err := os.Open(filepath.Join(filepath.Dir(path), e.Name()))
+			if err != nil {
+				continue
+			}
+			img, _, err := image.Decode(io.LimitReader(f, maxSize))
+			f.Close()
+			if err == nil {
+				return img, nil
+			}
+		}
+	}
+	return nil, ErrNoArt
+}
@@ -0,0 +1,220 @@
+package artwork
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// Embedded returns the picture embedded in the audio file at path: the
+// APIC frame of an ID3v2 tag (MP3) or the PICTURE block of a FLAC file.
+// The front cover is preferred when there are several.
+func Embedded(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	magic, err := r.Peek(4)
+	if err != nil {
+		return nil, ErrNoArt
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte("ID3")):
+		return id3Picture(r)
+	case bytes.Equal(magic, []byte("fLaC")):
+		return flacPicture(r)
+	}
+	return nil, ErrNoArt
+}
+
+// frontCover is the picture type of front covers in ID3 and FLAC.
+const frontCover = 3
+
+// synchsafe decodes an ID3v2 synchsafe integer (7 bits per byte).
+func synchsafe(b []byte) int {
+	return int(b[0])<<21 | int(b[1])<<14 | int(b[2])<<7 | int(b[3])
+}
+
+func id3Picture(r io.Reader) ([]byte, error) {
+	var header [10]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, ErrNoArt
+	}
+	version := header[3]
+	size := synchsafe(header[6:])
+	if version < 3 || size > maxSize {
+		// ID3v2.2 uses three-letter frames; rare enough to skip
+		return nil, ErrNoArt
+	}
+	tag := make([]byte, size)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return nil, ErrNoArt
+	}
+	if version == 3 && header[5]&0x80 != 0 {
+		tag = resync(tag)
+	}
+	if header[5]&0x40 != 0 && len(tag) >= 4 {
+		// Extended header
+		n := int(binary.BigEndian.Uint32(tag))
+		if version == 4 {
+			n = synchsafe(tag)
+		} else {
+			n += 4
+		}
+		if n > len(tag) {
+			return nil, ErrNoArt
+		}
+		tag = tag[n:]
+	}
+
+	var found []byte
+	for len(tag) >= 10 && tag[0] != 0 {
+		id := string(tag[:4])
+		n := int(binary.BigEndian.Uint32(tag[4:8]))
+		if version == 4 {
+			n = synchsafe(tag[4:8])
+		}
+		if n > len(tag)-10 {
+			break
+		}
+		flags := tag[9]
+		body := tag[10 : 10+n]
+		tag = tag[10+n:]
+		if id != "APIC" {
+			continue
+		}
+		if version == 4 && flags&0x01 != 0 && len(body) >= 4 {
+			// Data length indicator
+			body = body[4:]
+		}
+		if version == 4 && flags&0x02 != 0 {
+			body = resync(body)
+		}
+		kind, data, ok := apic(body)
+		if !ok {
+			continue
+		}
+		if kind == frontCover {
+			return data, nil
+		}
+		if found == nil {
+			found = data
+		}
+	}
+	if found == nil {
+		return nil, ErrNoArt
+	}
+	return found, nil
+}
+
+// resync undoes ID3 unsynchronisation, which inserts a zero byte after
+// every 0xFF.
+func resync(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xff, 0x00}, []byte{0xff})
+}
+
+// apic splits an APIC frame body into its picture type and data.
+func apic(body []byte) (byte, []byte, bool) {
+	if len(body) < 2 {
+		return 0, nil, false
+	}
+	enc := body[0]
+	body = body[1:]
+	// MIME type, always Latin-1
+	i := bytes.IndexByte(body, 0)
+	if i < 0 || i+2 > len(body) {
+		return 0, nil, false
+	}
+	kind := body[i+1]
+	body = body[i+2:]
+	// Description, terminated according to the text encoding
+	if enc == 1 || enc == 2 {
+		for j := 0; j+1 < len(body); j += 2 {
+			if body[j] == 0 && body[j+1] == 0 {
+				return kind, body[j+2:], true
+			}
+		}
+		return 0, nil, false
+	}
+	i = bytes.IndexByte(body, 0)
+	if i < 0 {
+		return 0, nil, false
+	}
+	return kind, body[i+1:], true
+}
+
+func flacPicture(r io.Reader) ([]byte, error) {
+	if _, err := io.ReadFull(r, make([]byte, 4)); err != nil {
+		return nil, ErrNoArt
+	}
+	var found []byte
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			break
+		}
+		last := header[0]&0x80 != 0
+		kind := header[0] & 0x7f
+		n := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+		if kind != 6 {
+			if _, err := io.CopyN(io.Discard, r, int64(n)); err != nil {
+				break
+			}
+		} else {
+			block := make([]byte, n)
+			if _, err := io.ReadFull(r, block); err != nil {
+				break
+			}
+			if typ, data, ok := flacBlock(block); ok {
+				if typ == frontCover {
+					return data, nil
+				}
+				if found == nil {
+					found = data
+				}
+			}
+		}
+		if last {
+			break
+		}
+	}
+	if found == nil {
+		return nil, ErrNoArt
+	}
+	return found, nil
+}
+
+// flacBlock splits a FLAC PICTURE block into its picture type and data.
+func flacBlock(b []byte) (uint32, []byte, bool) {
+	field := func() (uint32, bool) {
+		if len(b) < 4 {
+			return 0, false
+		}
+		v := binary.BigEndian.Uint32(b)
+		b = b[4:]
+		return v, true
+	}
+	skip := func() bool {
+		n, ok := field()
+		if !ok || int(n) > len(b) {
+			return false
+		}
+		b = b[n:]
+		return true
+	}
+	typ, ok := field()
+	// MIME type and description, then width, height, depth and colors
+	if !ok || !skip() || !skip() || len(b) < 16 {
+		return 0, nil, false
+	}
+	b = b[16:]
+	n, ok := field()
+	if !ok || int(n) > len(b) {
+		return 0, nil, false
+	}
+	return typ, b[:n], true
+}
@@ -0,0 +1,242 @@
+package artwork
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"os"
+	"strings"
+)
+
+// Protocol is a way of drawing images in a terminal.
+type Protocol string
+
+const (
+	// HalfBlock draws two pixels per cell with "▀" and colors; it works in
+	// any truecolor terminal.
+	HalfBlock Protocol = "halfblock"
+	Kitty     Protocol = "kitty"
+	ITerm2    Protocol = "iterm2"
+	Sixel     Protocol = "sixel"
+)
+
+// Graphics reports whether p draws real pixels rather than cells.
+func (p Protocol) Graphics() bool {
+	return p == Kitty || p == ITerm2 || p == Sixel
+}
+
+// Detect guesses the best protocol of the terminal from the environment.
+// Terminals are not queried: the TUI owns the terminal by the time art is
+// drawn.
+func Detect() Protocol {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("TMUX") != "" || strings.HasPrefix(term, "screen"):
+		// Multiplexers swallow or misplace image escapes
+		return HalfBlock
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || program == "ghostty":
+		return Kitty
+	case program == "iTerm.app" || program == "WezTerm" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return ITerm2
+	case strings.Contains(term, "foot") || strings.Contains(term, "mlterm") || strings.Contains(term, "yaft") ||
+		program == "contour":
+		return Sixel
+	}
+	return HalfBlock
+}
+
+// Cell size in pixels assumed for sixel output, which is drawn in pixels
+// rather than cells. Most terminal fonts are about this shape.
+const (
+	cellWidth  = 10
+	cellHeight = 20
+)
+
+// fit scales img to fit w×h pixels, keeping its aspect ratio, by averaging
+// the source pixels under each target pixel.
+func fit(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 || w <= 0 || h <= 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+	if b.Dx()*h > b.Dy()*w {
+		h = max(b.Dy()*w/b.Dx(), 1)
+	} else {
+		w = max(b.Dx()*h/b.Dy(), 1)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(b.Min.X+(x+1)*b.Dx()/w, x0+1)
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, _ := img.At(sx, sy).RGBA()
+					r, g, bl, n = r+cr, g+cg, bl+cb, n+1
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n >> 8), uint8(g / n >> 8), uint8(bl / n >> 8), 0xff})
+		}
+	}
+	return dst
+}
+
+// HalfBlocks renders img in at most cols×rows cells as tview color-tagged
+// text, each cell showing two pixels stacked with "▀".
+func HalfBlocks(img image.Image, cols, rows int) string {
+	px := fit(img, cols, rows*2)
+	w, h := px.Bounds().Dx(), px.Bounds().Dy()
+	var b strings.Builder
+	for y := 0; y < h; y += 2 {
+		for x := 0; x < w; x++ {
+			top := px.RGBAAt(x, y)
+			if y+1 < h {
+				bottom := px.RGBAAt(x, y+1)
+				fmt.Fprintf(&b, "[#%02x%02x%02x:#%02x%02x%02x]▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			} else {
+				fmt.Fprintf(&b, "[#%02x%02x%02x:-]▀", top.R, top.G, top.B)
+			}
+		}
+		b.WriteString("[-:-]\n")
+	}
+	return b.String()
+}
+
+// Size returns the cells img takes when fitted into cols×rows cells.
+func Size(img image.Image, cols, rows int) (int, int) {
+	px := fit(img, cols*cellWidth, rows*cellHeight).Bounds()
+	return (px.Dx() + cellWidth - 1) / cellWidth, (px.Dy() + cellHeight - 1) / cellHeight
+}
+
+// Encode returns the escape sequence drawing img into cols×rows cells at
+// the cursor with protocol p. id names the image for kitty, which keeps
+// images until they are deleted (see Clear).
+func Encode(p Protocol, img image.Image, cols, rows, id int) string {
+	switch p {
+	case Kitty:
+		return encodeKitty(img, cols, rows, id)
+	case ITerm2:
+		return encodeITerm2(img, cols, rows)
+	case Sixel:
+		return encodeSixel(fit(img, cols*cellWidth, rows*cellHeight))
+	}
+	return ""
+}
+
+// Clear returns the escape sequence removing the image id drawn with p, or
+// "" when redrawing the cells is enough.
+func Clear(p Protocol, id int) string {
+	if p == Kitty {
+		return fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", id)
+	}
+	return ""
+}
+
+func pngBase64(img image.Image) string {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func encodeKitty(img image.Image, cols, rows, id int) string {
+	// Send a reasonably small PNG; the terminal scales it to the cells
+	data := pngBase64(fit(img, cols*cellWidth, rows*cellHeight))
+	var b strings.Builder
+	for i := 0; i < len(data); i += 4096 {
+		chunk := data[i:min(i+4096, len(data))]
+		more := 0
+		if i+4096 < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=T,f=100,i=%d,c=%d,r=%d,C=1,q=2,m=%d;%s\x1b\\", id, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String()
+}
+
+func encodeITerm2(img image.Image, cols, rows int) string {
+	data := pngBase64(fit(img, cols*cellWidth, rows*cellHeight))
+	return fmt.Sprintf("\x1b]1337;File=inline=1;width=%d;height=%d;preserveAspectRatio=1;doNotMoveCursor=1:%s\a",
+		cols, rows, data)
+}
+
+// encodeSixel draws img with a 216-color palette, six pixel rows per band.
+func encodeSixel(img *image.RGBA) string {
+	bounds := img.Bounds()
+	pal := palette.WebSafe
+	px := image.NewPaletted(bounds, pal)
+	draw.FloydSteinberg.Draw(px, bounds, img, image.Point{})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1bPq\"1;1;%d;%d", bounds.Dx(), bounds.Dy())
+	for i, c := range pal {
+		r, g, bl, _ := c.RGBA()
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+	}
+	w, h := bounds.Dx(), bounds.Dy()
+	for band := 0; band < h; band += 6 {
+		used := map[uint8]bool{}
+		for y := band; y < min(band+6, h); y++ {
+			for x := 0; x < w; x++ {
+				used[px.ColorIndexAt(x, y)] = true
+			}
+		}
+		first := true
+		for idx := range pal {
+			if !used[uint8(idx)] {
+				continue
+			}
+			if !first {
+				b.WriteByte('$') // back to the start of the band
+			}
+			first = false
+			fmt.Fprintf(&b, "#%d", idx)
+			writeSixelRow(&b, px, band, w, h, uint8(idx))
+		}
+		b.WriteByte('-')
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeSixelRow writes the pixels of color idx in the band starting at row
+// band, run-length encoded.
+func writeSixelRow(b *strings.Builder, px *image.Paletted, band, w, h int, idx uint8) {
+	run, last := 0, byte(0)
+	flush := func() {
+		switch {
+		case run > 3:
+			fmt.Fprintf(b, "!%d%c", run, last)
+		default:
+			b.WriteString(strings.Repeat(string(last), run))
+		}
+	}
+	for x := 0; x < w; x++ {
+		bits := byte(0)
+		for i := 0; i < 6 && band+i < h; i++ {
+			if px.ColorIndexAt(x, band+i) == idx {
+				bits |= 1 << i
+			}
+		}
+		c := 63 + bits
+		if run > 0 && c != last {
+			flush()
+			run = 0
+		}
+		last = c
+		run++
+	}
+	flush()
+}
@@ -0,0 +1,100 @@
+package artwork
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	yprov "audictl/providers/youtube"
+)
+
+// maxSheets is how many storyboard sheets are kept downloaded. Scrubbing
+// back and forth keeps landing on the same few.
+const maxSheets = 4
+
+// errNoFrame is returned for positions a storyboard has no frame for.
+var errNoFrame = errors.New("no storyboard frame")
+
+var (
+	sheetsMu  sync.Mutex
+	sheets    = map[string]image.Image{} // by URL
+	sheetURLs []string                   // in sheets, oldest first
+)
+
+// StoryboardFrame returns the frame of sb shown at pos seconds.
+func StoryboardFrame(sb yprov.Storyboard, pos float64) (image.Image, error) {
+	url, rect, ok := sb.Frame(pos)
+	if !ok {
+		return nil, errNoFrame
+	}
+	sheet, err := storyboardSheet(url)
+	if err != nil {
+		return nil, err
+	}
+	rect = rect.Add(sheet.Bounds().Min).Intersect(sheet.Bounds())
+	if rect.Empty() {
+		return nil, errNoFrame
+	}
+	frame := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(frame, frame.Bounds(), sheet, rect.Min, draw.Src)
+	return frame, nil
+}
+
+// storyboardSheet downloads the sheet at url, or returns it from the ones
+// kept.
+func storyboardSheet(url string) (image.Image, error) {
+	sheetsMu.Lock()
+	sheet, ok := sheets[url]
+	sheetsMu.Unlock()
+	if ok {
+		return sheet, nil
+	}
+	sheet, err := download(url)
+	if err != nil {
+		return nil, err
+	}
+	sheetsMu.Lock()
+	defer sheetsMu.Unlock()
+	if _, ok := sheets[url]; !ok {
+		sheets[url] = sheet
+		sheetURLs = append(sheetURLs, url)
+		if len(sheetURLs) > maxSheets {
+			delete(sheets, sheetURLs[0])
+			sheetURLs = sheetURLs[1:]
+		}
+	}
+	return sheet, nil
+}
+
+// Strip lays frames out side by side, gap pixels apart, on black, with a
+// border around the one at mark. Nil frames leave their slot empty, so the
+// others stay where they would be. All slots are the size of the largest
+// frame.
+func Strip(frames []image.Image, mark, gap int) image.Image {
+	var w, h int
+	for _, f := range frames {
+		if f != nil {
+			w, h = max(w, f.Bounds().Dx()), max(h, f.Bounds().Dy())
+		}
+	}
+	if w == 0 || h == 0 {
+		return nil
+	}
+	border := max(gap/2, 1)
+	strip := image.NewRGBA(image.Rect(0, 0, len(frames)*(w+gap)+gap, h+2*gap))
+	draw.Draw(strip, strip.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	for i, f := range frames {
+		slot := image.Rect(gap+i*(w+gap), gap, gap+i*(w+gap)+w, gap+h)
+		if i == mark {
+			highlight := image.NewUniform(color.RGBA{0xff, 0xd7, 0x00, 0xff})
+			draw.Draw(strip, slot.Inset(-border), highlight, image.Point{}, draw.Src)
+			draw.Draw(strip, slot, image.NewUniform(color.Black), image.Point{}, draw.Src)
+		}
+		if f != nil {
+			draw.Draw(strip, slot, f, f.Bounds().Min, draw.Src)
+		}
+	}
+	return strip
+}
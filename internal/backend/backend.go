@@ -0,0 +1,102 @@
+// Package backend abstracts the program that plays the audio. mpv is the
+// default and the only backend with audio filters, device selection, speed,
+// A-B loops, gapless playback and video, which stay in package mpv; VLC and
+// ffplay cover the basic transport on systems without mpv. Builds with the
+// libmpv tag can also run mpv in-process through its client API.
+package backend
+
+import (
+	"errors"
+	"fmt"
+
+	"audictl/internal/mpv"
+)
+
+// Options, FileOptions and Event are shared with package mpv, whose
+// vocabulary every backend follows. Backends ignore options they have no
+// equivalent for.
+type (
+	Options     = mpv.Options
+	FileOptions = mpv.FileOptions
+	Event       = mpv.Event
+)
+
+// ErrUnsupported is returned for controls a backend doesn't have.
+var ErrUnsupported = errors.New("not supported by this player backend")
+
+// Default is the backend used when none is configured.
+const Default = "mpv"
+
+// Player controls one running player.
+type Player interface {
+	// Name identifies the backend, e.g. "mpv".
+	Name() string
+
+	// Events reports "start-file" and "end-file" (Reason "eof", "stop",
+	// "error") with the playlist entry ID of the file, and
+	// "playback-restart" once audio starts. It is closed when the player
+	// exits.
+	Events() <-chan Event
+
+	// Load replaces whatever is playing with url.
+	Load(url string, opts FileOptions) error
+	// Append queues url to play gaplessly after the current file.
+	Append(url string, opts FileOptions) error
+	// ClearAppended drops files queued with Append.
+	ClearAppended() error
+	// Stop stops playback; the player stays up for the next Load.
+	Stop() error
+
+	TogglePause() error
+	Resume() error
+	Paused() (bool, error)
+
+	// Seek moves by delta seconds, SeekTo to an absolute position.
+	Seek(delta float64) error
+	SeekTo(pos float64) error
+	// Position returns the playback position in seconds.
+	Position() (float64, error)
+
+	// SetVolume sets the volume in percent (100 is unchanged).
+	SetVolume(v float64) error
+	Volume() (float64, error)
+	ToggleMute() error
+	SetMute(on bool) error
+
+	// OpensPages reports whether the player can open web pages such as
+	// YouTube watch URLs itself (mpv through yt-dlp), rather than only
+	// direct media URLs.
+	OpensPages() bool
+
+	// Close stops the player process.
+	Close() error
+}
+
+// MpvPlayer is a Player built on mpv. Its IPC socket takes the controls
+// beyond Player: filters, devices, speed, A-B loops and video.
+type MpvPlayer interface {
+	Player
+	Mpv() *mpv.Instance
+}
+
+// IsMpv reports whether the named backend is mpv, as a process or through
+// libmpv, and so answers the IPC controls of package mpv.
+func IsMpv(name string) bool {
+	return name == "" || name == "mpv" || name == "libmpv"
+}
+
+// Start launches the named backend: "mpv" (or ""), "libmpv", "vlc" or
+// "ffplay". libmpv is only available in builds with the libmpv tag.
+func Start(name string, opts Options) (Player, error) {
+	switch name {
+	case "", "mpv":
+		return startMpv(opts)
+	case "libmpv":
+		return startLibmpv(opts)
+	case "vlc":
+		return startVLC(opts)
+	case "ffplay":
+		return startFFplay(opts)
+	}
+	return nil, fmt.Errorf("unknown player backend %q (want mpv, libmpv, vlc or ffplay)", name)
+}
@@ -0,0 +1,335 @@
+// Package backendtest provides a fake backend.Player for tests of the code
+// driving a player. It plays nothing but keeps time like one: files play for
+// a set length, follow each other gaplessly when appended, and report the
+// same events as mpv.
+package backendtest
+
+import (
+	"sync"
+	"time"
+
+	"audictl/internal/backend"
+)
+
+// Player is a fake backend.Player. Each file plays for the length given to
+// New, or until the test ends it with Finish or Fail.
+type Player struct {
+	length time.Duration
+
+	mu       sync.Mutex
+	events   chan backend.Event
+	closed   bool
+	entry    int // last playlist entry ID handed out
+	current  *file
+	appended []*file
+	timer    *time.Timer
+	paused   bool
+	volume   float64
+	muted    bool
+	loads    []string
+}
+
+// file is a playlist entry of the fake player.
+type file struct {
+	id     int
+	url    string
+	opts   backend.FileOptions
+	pos    float64   // seconds played before since
+	since  time.Time // when it last resumed, zero while paused
+	length time.Duration
+}
+
+// New returns a player whose files play for length each; 0 plays them
+// until they are finished by hand.
+func New(length time.Duration) *Player {
+	return &Player{
+		length: length,
+		events: make(chan backend.Event, 64),
+		volume: 100,
+	}
+}
+
+// Loads returns the URLs loaded or appended so far, in order.
+func (p *Player) Loads() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.loads...)
+}
+
+// Playing returns the URL of the file playing, or "".
+func (p *Player) Playing() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current == nil {
+		return ""
+	}
+	return p.current.url
+}
+
+// Options returns the options the file playing was loaded with.
+func (p *Player) Options() backend.FileOptions {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current == nil {
+		return backend.FileOptions{}
+	}
+	return p.current.opts
+}
+
+// Finish ends the playing file as if it reached its end.
+func (p *Player) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.end("eof", "")
+}
+
+// Fail ends the playing file with fileError, as mpv does when a stream
+// breaks.
+func (p *Player) Fail(fileError string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.end("error", fileError)
+}
+
+// Crash closes the player as if its process died.
+func (p *Player) Crash() {
+	_ = p.Close()
+}
+
+// emit sends ev unless the player is closed. Must be called with p.mu held.
+func (p *Player) emit(ev backend.Event) {
+	if !p.closed {
+		p.events <- ev
+	}
+}
+
+// start plays f. Must be called with p.mu held.
+func (p *Player) start(f *file) {
+	p.current = f
+	f.pos = f.opts.StartPos
+	f.since = time.Now()
+	if p.paused {
+		f.since = time.Time{}
+	}
+	p.emit(backend.Event{Event: "start-file", PlaylistEntryID: f.id})
+	p.emit(backend.Event{Event: "playback-restart"})
+	p.schedule()
+}
+
+// schedule arms the timer ending the playing file. Must be called with
+// p.mu held.
+func (p *Player) schedule() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	f := p.current
+	if f == nil || f.length <= 0 || p.paused {
+		return
+	}
+	left := f.length - time.Duration(f.position()*float64(time.Second))
+	p.timer = time.AfterFunc(max(left, 0), func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.current == f {
+			p.end("eof", "")
+		}
+	})
+}
+
+// end ends the playing file for reason and moves on to the next appended
+// one, if any. Must be called with p.mu held.
+func (p *Player) end(reason, fileError string) {
+	f := p.current
+	if f == nil {
+		return
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	p.current = nil
+	p.emit(backend.Event{Event: "end-file", Reason: reason, FileError: fileError, PlaylistEntryID: f.id})
+	if reason == "stop" || len(p.appended) == 0 {
+		return
+	}
+	next := p.appended[0]
+	p.appended = p.appended[1:]
+	p.start(next)
+}
+
+// position returns the seconds played of f.
+func (f *file) position() float64 {
+	pos := f.pos
+	if !f.since.IsZero() {
+		pos += time.Since(f.since).Seconds()
+	}
+	return pos
+}
+
+// newFile makes a playlist entry for url. Must be called with p.mu held.
+func (p *Player) newFile(url string, opts backend.FileOptions) *file {
+	p.entry++
+	p.loads = append(p.loads, url)
+	return &file{id: p.entry, url: url, opts: opts, length: p.length}
+}
+
+func (p *Player) Name() string { return "fake" }
+
+func (p *Player) Events() <-chan backend.Event { return p.events }
+
+func (p *Player) Load(url string, opts backend.FileOptions) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.appended = nil
+	p.end("stop", "")
+	p.start(p.newFile(url, opts))
+	return nil
+}
+
+func (p *Player) Append(url string, opts backend.FileOptions) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f := p.newFile(url, opts)
+	if p.current == nil {
+		p.start(f)
+		return nil
+	}
+	p.appended = append(p.appended, f)
+	return nil
+}
+
+func (p *Player) ClearAppended() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.appended = nil
+	return nil
+}
+
+func (p *Player) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.appended = nil
+	p.end("stop", "")
+	return nil
+}
+
+func (p *Player) TogglePause() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setPaused(!p.paused)
+	return nil
+}
+
+func (p *Player) Resume() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setPaused(false)
+	return nil
+}
+
+// setPaused pauses or resumes the clock of the playing file. Must be
+// called with p.mu held.
+func (p *Player) setPaused(paused bool) {
+	if paused == p.paused {
+		return
+	}
+	p.paused = paused
+	if f := p.current; f != nil {
+		if paused {
+			f.pos = f.position()
+			f.since = time.Time{}
+		} else {
+			f.since = time.Now()
+		}
+	}
+	p.schedule()
+}
+
+func (p *Player) Paused() (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused, nil
+}
+
+func (p *Player) Seek(delta float64) error {
+	pos, _ := p.Position()
+	return p.SeekTo(pos + delta)
+}
+
+func (p *Player) SeekTo(pos float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f := p.current
+	if f == nil {
+		return nil
+	}
+	f.pos = max(pos, 0)
+	if !f.since.IsZero() {
+		f.since = time.Now()
+	}
+	p.schedule()
+	return nil
+}
+
+func (p *Player) Position() (float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current == nil {
+		return 0, nil
+	}
+	return p.current.position(), nil
+}
+
+func (p *Player) SetVolume(v float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.volume = v
+	return nil
+}
+
+func (p *Player) Volume() (float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.volume, nil
+}
+
+func (p *Player) ToggleMute() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.muted = !p.muted
+	return nil
+}
+
+func (p *Player) SetMute(on bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.muted = on
+	return nil
+}
+
+// Muted reports whether the player is muted.
+func (p *Player) Muted() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.muted
+}
+
+func (p *Player) OpensPages() bool { return false }
+
+func (p *Player) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	p.current = nil
+	p.appended = nil
+	p.closed = true
+	close(p.events)
+	return nil
+}
@@ -0,0 +1,58 @@
+package backendtest
+
+import (
+	"testing"
+	"time"
+
+	"audictl/internal/backend"
+)
+
+func TestFilesFollowEachOther(t *testing.T) {
+	p := New(50 * time.Millisecond)
+	defer p.Close()
+	if err := p.Load("one", backend.FileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Append("two", backend.FileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	timeout := time.After(time.Second)
+	for len(got) < 4 {
+		select {
+		case ev := <-p.Events():
+			if ev.Event == "start-file" || ev.Event == "end-file" {
+				got = append(got, ev.Event+" "+ev.Reason)
+			}
+		case <-timeout:
+			t.Fatalf("events = %q, want two files played through", got)
+		}
+	}
+	want := []string{"start-file ", "end-file eof", "start-file ", "end-file eof"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %q, want %q", got, want)
+		}
+	}
+	if url := p.Playing(); url != "" {
+		t.Errorf("still playing %q", url)
+	}
+}
+
+func TestPauseHoldsPosition(t *testing.T) {
+	p := New(0)
+	defer p.Close()
+	_ = p.Load("one", backend.FileOptions{StartPos: 30})
+	_ = p.TogglePause()
+	paused, _ := p.Position()
+	time.Sleep(20 * time.Millisecond)
+	if pos, _ := p.Position(); pos != paused || pos < 30 {
+		t.Errorf("position = %.3f while paused at %.3f", pos, paused)
+	}
+	_ = p.Resume()
+	time.Sleep(20 * time.Millisecond)
+	if pos, _ := p.Position(); pos <= paused {
+		t.Errorf("position = %.3f after resuming at %.3f", pos, paused)
+	}
+}
@@ -0,0 +1,278 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"audictl/internal/proc"
+)
+
+// ffplayPlayer plays each file in its own ffplay process. ffplay has no
+// control channel, so pausing stops the process with SIGSTOP (which Windows
+// lacks), and seeking or changing the volume restarts it at the current
+// position.
+type ffplayPlayer struct {
+	opts   Options
+	events chan Event
+
+	mu       sync.Mutex
+	cmd      *exec.Cmd
+	url      string
+	entry    int
+	startPos float64   // position the process started from
+	started  time.Time // when it started, moved forward by time spent paused
+	pausedAt time.Time // zero unless paused
+	volume   float64
+	muted    bool
+	closed   bool
+}
+
+func startFFplay(opts Options) (*ffplayPlayer, error) {
+	if _, err := exec.LookPath("ffplay"); err != nil {
+		return nil, fmt.Errorf("ffplay not found: %w", err)
+	}
+	return &ffplayPlayer{opts: opts, events: make(chan Event, 16), volume: 100}, nil
+}
+
+func (f *ffplayPlayer) Name() string         { return "ffplay" }
+func (f *ffplayPlayer) Events() <-chan Event { return f.events }
+func (f *ffplayPlayer) OpensPages() bool     { return false }
+
+func (f *ffplayPlayer) Load(url string, opts FileOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return fmt.Errorf("ffplay backend closed")
+	}
+	f.kill("stop")
+	f.entry++
+	f.url = url
+	f.events <- Event{Event: "start-file", PlaylistEntryID: f.entry}
+	return f.spawn(opts.StartPos)
+}
+
+func (f *ffplayPlayer) Append(string, FileOptions) error { return ErrUnsupported }
+func (f *ffplayPlayer) ClearAppended() error             { return nil }
+
+func (f *ffplayPlayer) Stop() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.kill("stop")
+	f.url = ""
+	return nil
+}
+
+func (f *ffplayPlayer) TogglePause() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cmd == nil {
+		return fmt.Errorf("nothing is playing")
+	}
+	if f.pausedAt.IsZero() {
+		f.pausedAt = time.Now()
+		return f.signal(proc.Pause)
+	}
+	f.started = f.started.Add(time.Since(f.pausedAt))
+	f.pausedAt = time.Time{}
+	return f.signal(proc.Continue)
+}
+
+func (f *ffplayPlayer) Resume() error {
+	f.mu.Lock()
+	paused := !f.pausedAt.IsZero()
+	f.mu.Unlock()
+	if paused {
+		return f.TogglePause()
+	}
+	return nil
+}
+
+func (f *ffplayPlayer) Paused() (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.pausedAt.IsZero(), nil
+}
+
+func (f *ffplayPlayer) Seek(delta float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.restart(f.position() + delta)
+}
+
+func (f *ffplayPlayer) SeekTo(pos float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.restart(pos)
+}
+
+func (f *ffplayPlayer) Position() (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cmd == nil {
+		return 0, fmt.Errorf("nothing is playing")
+	}
+	return f.position(), nil
+}
+
+func (f *ffplayPlayer) SetVolume(v float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.volume = v
+	if f.cmd == nil {
+		return nil
+	}
+	return f.restart(f.position())
+}
+
+func (f *ffplayPlayer) Volume() (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.volume, nil
+}
+
+func (f *ffplayPlayer) ToggleMute() error {
+	f.mu.Lock()
+	on := !f.muted
+	f.mu.Unlock()
+	return f.SetMute(on)
+}
+
+func (f *ffplayPlayer) SetMute(on bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.muted == on {
+		return nil
+	}
+	f.muted = on
+	if f.cmd == nil {
+		return nil
+	}
+	return f.restart(f.position())
+}
+
+func (f *ffplayPlayer) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.kill("quit")
+	f.closed = true
+	close(f.events)
+	return nil
+}
+
+// spawn starts ffplay on f.url at pos. Must be called with f.mu held.
+func (f *ffplayPlayer) spawn(pos float64) error {
+	args := []string{"-autoexit", "-loglevel", "quiet", "-volume", fmt.Sprint(int(f.volume))}
+	if f.muted {
+		args[len(args)-1] = "0"
+	}
+	if !f.opts.Video {
+		args = append(args, "-nodisp")
+	}
+	if pos > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%.1f", pos))
+	}
+	args = append(args, f.url)
+
+	cmd := exec.Command("ffplay", args...)
+	proc.Detach(cmd)
+	cmd.Env = os.Environ()
+	if f.opts.AudioOutput == "null" {
+		cmd.Env = append(cmd.Env, "SDL_AUDIODRIVER=dummy")
+	}
+	if f.opts.HTTPProxy != "" {
+		// ffmpeg's HTTP client reads it from the environment
+		cmd.Env = append(cmd.Env, "http_proxy="+f.opts.HTTPProxy)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffplay: %w", err)
+	}
+	f.cmd = cmd
+	f.startPos = pos
+	f.started = time.Now()
+	f.pausedAt = time.Time{}
+	f.events <- Event{Event: "playback-restart"}
+
+	entry := f.entry
+	go func() {
+		err := cmd.Wait()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.cmd != cmd {
+			// Killed or restarted by us
+			return
+		}
+		f.cmd = nil
+		ev := Event{Event: "end-file", Reason: "eof", PlaylistEntryID: entry}
+		if err != nil {
+			ev.Reason = "error"
+			ev.FileError = strings.TrimSpace(err.Error())
+		}
+		f.events <- ev
+	}()
+	return nil
+}
+
+// restart replaces the running ffplay with one starting at pos, keeping it
+// paused if it was. Must be called with f.mu held.
+func (f *ffplayPlayer) restart(pos float64) error {
+	if f.cmd == nil {
+		return fmt.Errorf("nothing is playing")
+	}
+	paused := !f.pausedAt.IsZero()
+	f.terminate()
+	if pos < 0 {
+		pos = 0
+	}
+	if err := f.spawn(pos); err != nil {
+		return err
+	}
+	if paused {
+		f.pausedAt = time.Now()
+		return f.signal(proc.Pause)
+	}
+	return nil
+}
+
+// kill stops the running file, reporting it ended for reason. Must be
+// called with f.mu held.
+func (f *ffplayPlayer) kill(reason string) {
+	if f.cmd == nil {
+		return
+	}
+	f.terminate()
+	f.events <- Event{Event: "end-file", Reason: reason, PlaylistEntryID: f.entry}
+}
+
+// terminate kills the ffplay process group. Must be called with f.mu held.
+func (f *ffplayPlayer) terminate() {
+	cmd := f.cmd
+	f.cmd = nil
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	// A stopped process only acts on SIGKILL
+	_ = proc.Kill(cmd)
+}
+
+func (f *ffplayPlayer) position() float64 {
+	end := time.Now()
+	if !f.pausedAt.IsZero() {
+		end = f.pausedAt
+	}
+	return f.startPos + end.Sub(f.started).Seconds()
+}
+
+// signal pauses or continues ffplay with proc.Pause or proc.Continue.
+func (f *ffplayPlayer) signal(send func(*exec.Cmd) error) error {
+	if f.cmd == nil || f.cmd.Process == nil {
+		return fmt.Errorf("nothing is playing")
+	}
+	return send(f.cmd)
+}
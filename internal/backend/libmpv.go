@@ -0,0 +1,358 @@
+//go:build libmpv && cgo
+
+package backend
+
+/*
+#cgo pkg-config: mpv
+#include <stdlib.h>
+#include <mpv/client.h>
+
+// loadfile runs loadfile with named arguments, which keeps working across
+// mpv versions that disagree on the position of its options parameter.
+// start, end and ytdl_format may be NULL.
+static int loadfile(mpv_handle *ctx, const char *url, const char *flags,
+                    const char *start, const char *end, const char *ytdl_format) {
+	char *optKeys[3];
+	mpv_node optValues[3];
+	int n = 0;
+	if (start) {
+		optKeys[n] = "start";
+		optValues[n].format = MPV_FORMAT_STRING;
+		optValues[n].u.string = (char *)start;
+		n++;
+	}
+	if (end) {
+		optKeys[n] = "end";
+		optValues[n].format = MPV_FORMAT_STRING;
+		optValues[n].u.string = (char *)end;
+		n++;
+	}
+	if (ytdl_format) {
+		optKeys[n] = "ytdl-format";
+		optValues[n].format = MPV_FORMAT_STRING;
+		optValues[n].u.string = (char *)ytdl_format;
+		n++;
+	}
+	mpv_node_list opts = {.num = n, .values = optValues, .keys = optKeys};
+
+	char *keys[] = {"name", "url", "flags", "options"};
+	mpv_node values[4];
+	values[0].format = MPV_FORMAT_STRING;
+	values[0].u.string = "loadfile";
+	values[1].format = MPV_FORMAT_STRING;
+	values[1].u.string = (char *)url;
+	values[2].format = MPV_FORMAT_STRING;
+	values[2].u.string = (char *)flags;
+	values[3].format = MPV_FORMAT_NODE_MAP;
+	values[3].u.list = &opts;
+	mpv_node_list args = {.num = 4, .values = values, .keys = keys};
+
+	mpv_node cmd = {.format = MPV_FORMAT_NODE_MAP, .u.list = &args};
+	return mpv_command_node(ctx, &cmd, NULL);
+}
+
+static int64_t start_file_entry(mpv_event *ev) {
+	return ((mpv_event_start_file *)ev->data)->playlist_entry_id;
+}
+
+static mpv_event_end_file *end_file(mpv_event *ev) {
+	return (mpv_event_end_file *)ev->data;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"unsafe"
+
+	"audictl/internal/mpv"
+)
+
+// libmpvPlayer runs mpv inside this process through its client API. The
+// transport goes straight through libmpv; mpv's IPC server is still opened
+// on a socket of its own, reached through Mpv, so the equalizer,
+// normalization, devices, speed, A-B loops and video keep working unchanged.
+type libmpvPlayer struct {
+	ctx    *C.mpv_handle
+	inst   *mpv.Instance
+	events chan Event
+	done   chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func startLibmpv(opts Options) (Player, error) {
+	ctx := C.mpv_create()
+	if ctx == nil {
+		return nil, errors.New("libmpv: cannot create player")
+	}
+
+	socketPath := mpv.NewSocketPath()
+	// A leftover socket from an earlier run would confuse package mpv
+	_ = os.Remove(socketPath)
+
+	options := [][2]string{
+		{"idle", "yes"},
+		{"terminal", "no"},
+		{"gapless-audio", "weak"},
+		{"prefetch-playlist", "yes"},
+		{"ytdl", "yes"},
+		{"input-ipc-server", socketPath},
+	}
+	if opts.Video {
+		options = append(options, [2]string{"force-window", "yes"})
+	} else {
+		options = append(options, [2]string{"vid", "no"})
+	}
+	if opts.Device != "" {
+		options = append(options, [2]string{"audio-device", opts.Device})
+	}
+	if opts.AudioOutput != "" {
+		options = append(options, [2]string{"ao", opts.AudioOutput})
+	}
+	if opts.YtDlpPath != "" {
+		options = append(options, [2]string{"script-opts-append", "ytdl_hook-ytdl_path=" + opts.YtDlpPath})
+	}
+	for _, kv := range mpv.RawOptions(opts.YtDlpOptions) {
+		options = append(options, [2]string{"ytdl-raw-options-append", kv})
+	}
+	if opts.HTTPProxy != "" {
+		options = append(options, [2]string{"http-proxy", opts.HTTPProxy})
+	}
+	if opts.BitPerfect {
+		options = append(options,
+			[2]string{"audio-exclusive", "yes"},
+			[2]string{"volume", "100"},
+			[2]string{"replaygain", "no"})
+	}
+	if opts.PCMFile != "" {
+		options = append(options, mpv.PCMOptions(opts.PCMFile)...)
+	}
+	for _, o := range options {
+		if err := setOption(ctx, o[0], o[1]); err != nil {
+			C.mpv_terminate_destroy(ctx)
+			return nil, err
+		}
+	}
+	if rc := C.mpv_initialize(ctx); rc < 0 {
+		C.mpv_terminate_destroy(ctx)
+		return nil, fmt.Errorf("libmpv: initialize: %w", mpvError(rc))
+	}
+
+	l := &libmpvPlayer{ctx: ctx, inst: mpv.Attach(socketPath), events: make(chan Event, 16), done: make(chan struct{})}
+	go l.loop()
+	return l, nil
+}
+
+// loop forwards mpv's events until it shuts down, then frees the handle.
+// It is the only place the handle is destroyed, so no other call can still
+// be using it.
+func (l *libmpvPlayer) loop() {
+	defer close(l.done)
+	defer close(l.events)
+	for {
+		ev := C.mpv_wait_event(l.ctx, -1)
+		switch ev.event_id {
+		case C.MPV_EVENT_SHUTDOWN:
+			// Under the lock, so no call is still using the handle
+			l.mu.Lock()
+			l.closed = true
+			C.mpv_terminate_destroy(l.ctx)
+			l.mu.Unlock()
+			return
+		case C.MPV_EVENT_START_FILE:
+			l.events <- Event{Event: "start-file", PlaylistEntryID: int(C.start_file_entry(ev))}
+		case C.MPV_EVENT_END_FILE:
+			ef := C.end_file(ev)
+			out := Event{Event: "end-file", Reason: endReason(ef.reason), PlaylistEntryID: int(ef.playlist_entry_id)}
+			if ef.error < 0 {
+				out.FileError = C.GoString(C.mpv_error_string(ef.error))
+			}
+			l.events <- out
+		case C.MPV_EVENT_PLAYBACK_RESTART:
+			l.events <- Event{Event: "playback-restart"}
+		}
+	}
+}
+
+// endReason names an mpv_end_file_reason the way mpv's IPC does.
+func endReason(r C.int) string {
+	switch r {
+	case C.MPV_END_FILE_REASON_EOF:
+		return "eof"
+	case C.MPV_END_FILE_REASON_STOP:
+		return "stop"
+	case C.MPV_END_FILE_REASON_QUIT:
+		return "quit"
+	case C.MPV_END_FILE_REASON_ERROR:
+		return "error"
+	case C.MPV_END_FILE_REASON_REDIRECT:
+		return "redirect"
+	}
+	return "unknown"
+}
+
+func (l *libmpvPlayer) Name() string         { return "libmpv" }
+func (l *libmpvPlayer) Mpv() *mpv.Instance   { return l.inst }
+func (l *libmpvPlayer) Events() <-chan Event { return l.events }
+func (l *libmpvPlayer) OpensPages() bool     { return true }
+
+// Close asks mpv to quit and waits for the event loop to free it.
+func (l *libmpvPlayer) Close() error {
+	if err := l.command("quit"); err != nil && !errors.Is(err, errClosed) {
+		return err
+	}
+	<-l.done
+	return nil
+}
+
+func (l *libmpvPlayer) Load(url string, opts FileOptions) error {
+	return l.loadfile(url, "replace", opts)
+}
+
+func (l *libmpvPlayer) Append(url string, opts FileOptions) error {
+	return l.loadfile(url, "append", opts)
+}
+
+func (l *libmpvPlayer) loadfile(url, flags string, opts FileOptions) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errClosed
+	}
+	curl, cflags := C.CString(url), C.CString(flags)
+	defer C.free(unsafe.Pointer(curl))
+	defer C.free(unsafe.Pointer(cflags))
+	var start, end, ytdlFormat *C.char
+	if opts.StartPos > 0 {
+		start = C.CString(fmt.Sprintf("%.1f", opts.StartPos))
+		defer C.free(unsafe.Pointer(start))
+	}
+	if opts.CutEnd > 0 {
+		// Negative times are relative to the end of the file
+		end = C.CString(fmt.Sprintf("-%.1f", opts.CutEnd))
+		defer C.free(unsafe.Pointer(end))
+	}
+	if opts.YtdlFormat != "" {
+		ytdlFormat = C.CString(opts.YtdlFormat)
+		defer C.free(unsafe.Pointer(ytdlFormat))
+	}
+	if rc := C.loadfile(l.ctx, curl, cflags, start, end, ytdlFormat); rc < 0 {
+		return fmt.Errorf("libmpv: loadfile: %w", mpvError(rc))
+	}
+	return nil
+}
+
+func (l *libmpvPlayer) ClearAppended() error { return l.command("playlist-clear") }
+func (l *libmpvPlayer) Stop() error          { return l.command("stop") }
+
+func (l *libmpvPlayer) TogglePause() error { return l.command("cycle", "pause") }
+func (l *libmpvPlayer) Resume() error      { return l.setString("pause", "no") }
+func (l *libmpvPlayer) Paused() (bool, error) {
+	var flag C.int
+	if err := l.get("pause", C.MPV_FORMAT_FLAG, unsafe.Pointer(&flag)); err != nil {
+		return false, err
+	}
+	return flag != 0, nil
+}
+
+func (l *libmpvPlayer) Seek(delta float64) error {
+	return l.command("seek", formatFloat(delta), "relative")
+}
+func (l *libmpvPlayer) SeekTo(pos float64) error {
+	return l.command("seek", formatFloat(pos), "absolute")
+}
+func (l *libmpvPlayer) Position() (float64, error) {
+	return l.getFloat("time-pos")
+}
+
+func (l *libmpvPlayer) SetVolume(v float64) error { return l.setString("volume", formatFloat(v)) }
+func (l *libmpvPlayer) Volume() (float64, error)  { return l.getFloat("volume") }
+func (l *libmpvPlayer) ToggleMute() error         { return l.command("cycle", "mute") }
+func (l *libmpvPlayer) SetMute(on bool) error {
+	if on {
+		return l.setString("mute", "yes")
+	}
+	return l.setString("mute", "no")
+}
+
+// errClosed is returned for calls made after mpv shut down.
+var errClosed = errors.New("libmpv: player closed")
+
+// command runs an mpv command given as strings.
+func (l *libmpvPlayer) command(args ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errClosed
+	}
+	cargs := make([]*C.char, len(args)+1)
+	for i, a := range args {
+		cargs[i] = C.CString(a)
+		defer C.free(unsafe.Pointer(cargs[i]))
+	}
+	if rc := C.mpv_command(l.ctx, &cargs[0]); rc < 0 {
+		return fmt.Errorf("libmpv: %s: %w", args[0], mpvError(rc))
+	}
+	return nil
+}
+
+func (l *libmpvPlayer) setString(name, value string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errClosed
+	}
+	cname, cvalue := C.CString(name), C.CString(value)
+	defer C.free(unsafe.Pointer(cname))
+	defer C.free(unsafe.Pointer(cvalue))
+	if rc := C.mpv_set_property_string(l.ctx, cname, cvalue); rc < 0 {
+		return fmt.Errorf("libmpv: set %s: %w", name, mpvError(rc))
+	}
+	return nil
+}
+
+func (l *libmpvPlayer) get(name string, format C.mpv_format, out unsafe.Pointer) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errClosed
+	}
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	if rc := C.mpv_get_property(l.ctx, cname, format, out); rc < 0 {
+		return fmt.Errorf("libmpv: get %s: %w", name, mpvError(rc))
+	}
+	return nil
+}
+
+func (l *libmpvPlayer) getFloat(name string) (float64, error) {
+	var v C.double
+	if err := l.get(name, C.MPV_FORMAT_DOUBLE, unsafe.Pointer(&v)); err != nil {
+		return 0, err
+	}
+	return float64(v), nil
+}
+
+func setOption(ctx *C.mpv_handle, name, value string) error {
+	cname, cvalue := C.CString(name), C.CString(value)
+	defer C.free(unsafe.Pointer(cname))
+	defer C.free(unsafe.Pointer(cvalue))
+	if rc := C.mpv_set_option_string(ctx, cname, cvalue); rc < 0 {
+		return fmt.Errorf("libmpv: option %s=%s: %w", name, value, mpvError(rc))
+	}
+	return nil
+}
+
+func mpvError(rc C.int) error {
+	return errors.New(C.GoString(C.mpv_error_string(rc)))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,9 @@
+//go:build !libmpv || !cgo
+
+package backend
+
+import "errors"
+
+func startLibmpv(Options) (Player, error) {
+	return nil, errors.New("libmpv backend not built in (rebuild with -tags libmpv)")
+}
@@ -0,0 +1,86 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"audictl/internal/mpv"
+)
+
+// mpvPlayer is the persistent mpv process driven through package mpv.
+type mpvPlayer struct {
+	inst   *mpv.Instance
+	events chan Event
+}
+
+func startMpv(opts Options) (*mpvPlayer, error) {
+	inst, err := mpv.Start(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := inst.WaitReady(5 * time.Second); err != nil {
+		_ = inst.Kill()
+		return nil, err
+	}
+	events, err := inst.Events()
+	if err != nil {
+		_ = inst.Kill()
+		return nil, fmt.Errorf("mpv events: %w", err)
+	}
+
+	m := &mpvPlayer{inst: inst, events: make(chan Event, 16)}
+	go func() {
+		for ev := range events {
+			m.events <- ev
+		}
+		// Only close once mpv is gone, so callers can start a new one
+		_ = inst.Wait()
+		close(m.events)
+	}()
+	return m, nil
+}
+
+func (m *mpvPlayer) Name() string         { return "mpv" }
+func (m *mpvPlayer) Mpv() *mpv.Instance   { return m.inst }
+func (m *mpvPlayer) Events() <-chan Event { return m.events }
+func (m *mpvPlayer) OpensPages() bool     { return true }
+func (m *mpvPlayer) Close() error         { return m.inst.Kill() }
+
+func (m *mpvPlayer) Load(url string, opts FileOptions) error   { return m.inst.Load(url, opts) }
+func (m *mpvPlayer) Append(url string, opts FileOptions) error { return m.inst.Append(url, opts) }
+func (m *mpvPlayer) ClearAppended() error                      { return m.inst.ClearAppended() }
+func (m *mpvPlayer) Stop() error                               { return m.inst.Stop() }
+
+func (m *mpvPlayer) TogglePause() error { return m.inst.Pause() }
+func (m *mpvPlayer) Resume() error      { return m.inst.Play() }
+func (m *mpvPlayer) Paused() (bool, error) {
+	v, err := m.inst.GetProperty("pause")
+	if err != nil {
+		return false, err
+	}
+	paused, _ := v.(bool)
+	return paused, nil
+}
+
+func (m *mpvPlayer) Seek(delta float64) error { return m.inst.Seek(delta) }
+func (m *mpvPlayer) SeekTo(pos float64) error { return m.inst.SeekTo(pos) }
+func (m *mpvPlayer) Position() (float64, error) {
+	return floatProperty(m.inst, "time-pos")
+}
+
+func (m *mpvPlayer) SetVolume(v float64) error { return m.inst.SetVolume(v) }
+func (m *mpvPlayer) Volume() (float64, error)  { return floatProperty(m.inst, "volume") }
+func (m *mpvPlayer) ToggleMute() error         { return m.inst.Mute() }
+func (m *mpvPlayer) SetMute(on bool) error     { return m.inst.SetMute(on) }
+
+func floatProperty(inst *mpv.Instance, name string) (float64, error) {
+	v, err := inst.GetProperty(name)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("mpv: %s is %T, not a number", name, v)
+	}
+	return f, nil
+}
@@ -0,0 +1,329 @@
+package backend
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"audictl/internal/proc"
+)
+
+// vlcPlayer drives VLC through its remote control (rc) interface on a unix
+// socket, or a loopback TCP port on Windows. The rc interface sends no events, so they are made up by polling
+// VLC's state.
+type vlcPlayer struct {
+	cmd    *exec.Cmd
+	events chan Event
+
+	mu    sync.Mutex // one rc command and its reply at a time
+	conn  net.Conn
+	r     *bufio.Reader
+	entry int  // ID of the file loaded last
+	begun bool // the current file has started playing
+	done  chan struct{}
+	gone  bool // VLC exited and events is closed
+
+	volume float64 // before muting
+	muted  bool
+}
+
+// vlcState matches the state line of the rc status command.
+var vlcState = regexp.MustCompile(`\( state (\w+) \)`)
+
+// vlcVolume matches the volume line of the rc status command; 256 is 100%.
+var vlcVolume = regexp.MustCompile(`\( audio volume: (\d+) \)`)
+
+func startVLC(opts Options) (*vlcPlayer, error) {
+	rcArgs, dial, err := rcControl()
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"-I", "rc"}, rcArgs...)
+	args = append(args, "--quiet", "--no-loop", "--no-repeat")
+	if !opts.Video {
+		args = append(args, "--no-video")
+	}
+	if opts.HTTPProxy != "" {
+		args = append(args, "--http-proxy="+opts.HTTPProxy)
+	}
+	if opts.AudioOutput == "null" {
+		args = append(args, "--aout=dummy")
+	}
+	cmd := exec.Command("vlc", args...)
+	proc.Detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start vlc: %w", err)
+	}
+
+	var conn net.Conn
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var err error
+		conn, err = dial(200 * time.Millisecond)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = proc.Kill(cmd)
+			return nil, fmt.Errorf("vlc rc socket not ready: %w", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	v := &vlcPlayer{
+		cmd:    cmd,
+		events: make(chan Event, 16),
+		conn:   conn,
+		r:      bufio.NewReader(conn),
+		done:   make(chan struct{}),
+		volume: 100,
+	}
+	go v.poll()
+	go func() {
+		_ = cmd.Wait()
+		close(v.done)
+	}()
+	return v, nil
+}
+
+func (v *vlcPlayer) Name() string         { return "vlc" }
+func (v *vlcPlayer) Events() <-chan Event { return v.events }
+
+// OpensPages is false: VLC's own YouTube script breaks too often to rely on.
+func (v *vlcPlayer) OpensPages() bool { return false }
+
+func (v *vlcPlayer) Load(url string, opts FileOptions) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.gone {
+		return fmt.Errorf("vlc exited")
+	}
+	if v.entry > 0 {
+		v.events <- Event{Event: "end-file", Reason: "stop", PlaylistEntryID: v.entry}
+	}
+	item := url
+	if opts.StartPos > 0 {
+		item += fmt.Sprintf(" :start-time=%.1f", opts.StartPos)
+	}
+	if _, err := v.command("clear"); err != nil {
+		return err
+	}
+	if _, err := v.command("add " + item); err != nil {
+		return err
+	}
+	v.entry++
+	v.begun = false
+	v.events <- Event{Event: "start-file", PlaylistEntryID: v.entry}
+	return nil
+}
+
+func (v *vlcPlayer) Append(string, FileOptions) error { return ErrUnsupported }
+func (v *vlcPlayer) ClearAppended() error             { return nil }
+
+func (v *vlcPlayer) Stop() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.gone {
+		return fmt.Errorf("vlc exited")
+	}
+	if v.entry > 0 {
+		v.events <- Event{Event: "end-file", Reason: "stop", PlaylistEntryID: v.entry}
+	}
+	v.entry = 0
+	_, err := v.command("stop")
+	return err
+}
+
+func (v *vlcPlayer) TogglePause() error { return v.run("pause") }
+
+func (v *vlcPlayer) Resume() error {
+	paused, err := v.Paused()
+	if err != nil || !paused {
+		return err
+	}
+	return v.run("pause")
+}
+
+func (v *vlcPlayer) Paused() (bool, error) {
+	state, _, err := v.status()
+	return state == "paused", err
+}
+
+func (v *vlcPlayer) Seek(delta float64) error {
+	pos, err := v.Position()
+	if err != nil {
+		return err
+	}
+	return v.SeekTo(pos + delta)
+}
+
+func (v *vlcPlayer) SeekTo(pos float64) error {
+	if pos < 0 {
+		pos = 0
+	}
+	return v.run(fmt.Sprintf("seek %d", int(pos)))
+}
+
+func (v *vlcPlayer) Position() (float64, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	lines, err := v.command("get_time")
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range lines {
+		if secs, err := strconv.Atoi(line); err == nil {
+			return float64(secs), nil
+		}
+	}
+	return 0, fmt.Errorf("vlc: no position")
+}
+
+func (v *vlcPlayer) SetVolume(vol float64) error {
+	v.mu.Lock()
+	v.volume = vol
+	muted := v.muted
+	v.mu.Unlock()
+	if muted {
+		return nil
+	}
+	return v.setVolume(vol)
+}
+
+func (v *vlcPlayer) Volume() (float64, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.volume, nil
+}
+
+func (v *vlcPlayer) ToggleMute() error {
+	v.mu.Lock()
+	on := !v.muted
+	v.mu.Unlock()
+	return v.SetMute(on)
+}
+
+// SetMute sets VLC's volume to zero, since rc has no mute command.
+func (v *vlcPlayer) SetMute(on bool) error {
+	v.mu.Lock()
+	v.muted = on
+	vol := v.volume
+	v.mu.Unlock()
+	if on {
+		vol = 0
+	}
+	return v.setVolume(vol)
+}
+
+func (v *vlcPlayer) Close() error {
+	v.mu.Lock()
+	_, _ = v.command("quit")
+	v.mu.Unlock()
+	select {
+	case <-v.done:
+	case <-time.After(time.Second):
+		_ = proc.Kill(v.cmd)
+	}
+	return nil
+}
+
+func (v *vlcPlayer) setVolume(vol float64) error {
+	return v.run(fmt.Sprintf("volume %d", int(vol*256/100)))
+}
+
+// run sends an rc command whose reply doesn't matter.
+func (v *vlcPlayer) run(cmd string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, err := v.command(cmd)
+	return err
+}
+
+// status returns VLC's playback state ("playing", "paused", "stopped") and
+// volume in percent.
+func (v *vlcPlayer) status() (string, float64, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	lines, err := v.command("status")
+	if err != nil {
+		return "", 0, err
+	}
+	state, vol := "stopped", 0.0
+	for _, line := range lines {
+		if m := vlcState.FindStringSubmatch(line); m != nil {
+			state = m[1]
+		}
+		if m := vlcVolume.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			vol = float64(n) * 100 / 256
+		}
+	}
+	return state, vol, nil
+}
+
+// command sends one rc command and returns the lines VLC replied with.
+// rc replies have no terminator, so reading stops once VLC has been quiet
+// briefly. Must be called with v.mu held.
+func (v *vlcPlayer) command(cmd string) ([]string, error) {
+	if _, err := fmt.Fprintf(v.conn, "%s\n", cmd); err != nil {
+		return nil, fmt.Errorf("vlc: %w", err)
+	}
+	var lines []string
+	wait := time.Second // for the first line
+	for {
+		_ = v.conn.SetReadDeadline(time.Now().Add(wait))
+		line, err := v.r.ReadString('\n')
+		if line = strings.TrimSpace(strings.TrimLeft(line, "> ")); line != "" {
+			lines = append(lines, line)
+		}
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				return lines, nil
+			}
+			return lines, fmt.Errorf("vlc: %w", err)
+		}
+		wait = 50 * time.Millisecond
+	}
+}
+
+// poll turns VLC's state into events: playback-restart once the loaded file
+// plays and end-file when VLC stops by itself. It closes the events channel
+// when VLC exits.
+func (v *vlcPlayer) poll() {
+	defer func() {
+		v.mu.Lock()
+		v.gone = true
+		close(v.events)
+		v.mu.Unlock()
+	}()
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-v.done:
+			return
+		case <-ticker.C:
+		}
+		state, _, err := v.status()
+		if err != nil {
+			continue
+		}
+		v.mu.Lock()
+		switch {
+		case v.entry == 0:
+		case !v.begun && state == "playing":
+			v.begun = true
+			v.events <- Event{Event: "playback-restart"}
+		case v.begun && state == "stopped":
+			v.events <- Event{Event: "end-file", Reason: "eof", PlaylistEntryID: v.entry}
+			v.entry = 0
+		}
+		v.mu.Unlock()
+	}
+}
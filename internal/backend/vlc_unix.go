@@ -0,0 +1,22 @@
+//go:build !windows
+
+package backend
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// rcControl returns the options that make VLC serve its rc interface on a
+// unix socket, and how to connect to it.
+func rcControl() ([]string, func(time.Duration) (net.Conn, error), error) {
+	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("vlc-socket-%d", os.Getpid()))
+	_ = os.Remove(socketPath)
+	dial := func(timeout time.Duration) (net.Conn, error) {
+		return net.DialTimeout("unix", socketPath, timeout)
+	}
+	return []string{"--rc-unix=" + socketPath, "--rc-fake-tty"}, dial, nil
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package backend
+
+import (
+	"net"
+	"time"
+)
+
+// rcControl returns the options that make VLC serve its rc interface on a
+// free loopback TCP port, as it has no unix sockets on Windows, and how to
+// connect to it.
+func rcControl() ([]string, func(time.Duration) (net.Conn, error), error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, err
+	}
+	addr := l.Addr().String()
+	l.Close()
+	dial := func(timeout time.Duration) (net.Conn, error) {
+		return net.DialTimeout("tcp", addr, timeout)
+	}
+	return []string{"--rc-host=" + addr, "--rc-quiet"}, dial, nil
+}
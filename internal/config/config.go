@@ -0,0 +1,390 @@
+// Package config loads user settings from $XDG_CONFIG_HOME/audictl/config.json
+// (~/.config/audictl/config.json by default). Every setting is optional; a
+// missing file simply yields the defaults.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"audictl/internal/eq"
+	"audictl/internal/theme"
+)
+
+type Config struct {
+	// RowFormat is the template for search result and queue rows, e.g.
+	// "{index}. {artist} — {title} ({duration})". See package format for
+	// the available placeholders. Empty keeps the built-in layouts.
+	RowFormat string `json:"row_format"`
+
+	// TimeDisplay selects what the progress bar shows: "elapsed" (default)
+	// or "remaining".
+	TimeDisplay string `json:"time_display"`
+
+	// SkipRules trims long intros/outros for tracks from specific channels.
+	SkipRules []SkipRule `json:"skip_rules"`
+
+	// Normalize evens out loudness between tracks so mixed-source queues
+	// don't jump in volume. It can be toggled for the session with 'g'.
+	Normalize bool `json:"normalize"`
+
+	// NormalizeMode is "loudnorm" (default), which levels every track to
+	// NormalizeTarget, or "replaygain", which applies the ReplayGain tags of
+	// local files and leaves untagged streams alone.
+	NormalizeMode string `json:"normalize_mode"`
+
+	// NormalizeTarget is the loudnorm target in LUFS; 0 means -14.
+	NormalizeTarget float64 `json:"normalize_target"`
+
+	// BitPerfect sends samples to the DAC unaltered: mpv opens an ALSA hw:
+	// device in exclusive mode and normalization, ducking and software
+	// volume are disabled. The Now Playing panel shows whether the output
+	// format matches the source.
+	BitPerfect bool `json:"bit_perfect"`
+
+	// ReplayWindow is how many seconds after a track was logged to the
+	// history that starting it again (replaying it, restarting it for the
+	// video window) still counts as the same listen and isn't logged twice.
+	// 0 means 60; a negative value logs every play.
+	ReplayWindow int `json:"replay_window"`
+
+	// ResumeLongerThan is how many minutes long a track must be for
+	// playback to resume where it was stopped or skipped, for mixes,
+	// podcasts and audiobooks. 0 means 10; a negative value never resumes.
+	ResumeLongerThan int `json:"resume_longer_than"`
+
+	// EQ names the equalizer preset to start with ("flat", "bass boost",
+	// "vocal", ... or one of EQPresets) until one is picked in the TUI.
+	EQ string `json:"eq"`
+
+	// EQPresets defines custom equalizer presets as lists of bands, e.g.
+	// {"warm": [{"freq": 125, "gain": 3}, {"freq": 8000, "gain": -2}]}.
+	EQPresets map[string][]eq.Band `json:"eq_presets"`
+
+	// PitchCorrection keeps voices at their natural pitch when playback is
+	// sped up or slowed down (mpv's scaletempo2). Defaults to true.
+	PitchCorrection *bool `json:"pitch_correction"`
+
+	// Backend selects the program that plays audio: "mpv" (default), "vlc"
+	// or "ffplay", or "libmpv" in builds with the libmpv tag. Only mpv
+	// supports the equalizer, normalization, device selection, speed, A-B
+	// loops, gapless playback and video.
+	Backend string `json:"backend"`
+
+	// YtDlpPath is the yt-dlp executable to run, for installs outside PATH.
+	// Empty uses the yt-dlp on PATH. Read at startup only.
+	YtDlpPath string `json:"yt_dlp_path"`
+
+	// Cookies is a Netscape cookies.txt file that yt-dlp sends with every
+	// request, for age-restricted and members-only videos. May start with ~.
+	// Read at startup only.
+	Cookies string `json:"cookies"`
+
+	// CookiesFromBrowser makes yt-dlp read the cookies of a browser profile
+	// instead, e.g. "firefox" or "chrome:Profile 1" (yt-dlp's
+	// --cookies-from-browser syntax). Read at startup only.
+	CookiesFromBrowser string `json:"cookies_from_browser"`
+
+	// Proxy is a proxy URL (http://, https:// or socks5://) for yt-dlp, the
+	// player and the lookups of Spotify links, lyrics, SponsorBlock and
+	// cover art, e.g. to work around region blocks. The player only takes
+	// http:// proxies. Read at startup only.
+	Proxy string `json:"proxy"`
+
+	// Proxies overrides Proxy for "youtube" (yt-dlp and video thumbnails),
+	// "spotify" (its link lookups) or "player" (the streams it plays), e.g.
+	// {"youtube": "socks5://localhost:1080", "spotify": ""}; "" there
+	// connects without Proxy. Read at startup only.
+	Proxies map[string]string `json:"proxies"`
+
+	// YouTubeSearch is what YouTube searches return: "videos" (the
+	// default), or "music" for songs from YouTube Music with their artist,
+	// album and duration, rather than lyric videos and hour-long loops.
+	// "yt:music" or "yt:videos" in a search overrides it.
+	YouTubeSearch string `json:"youtube_search"`
+
+	// Quality picks the streams played. Read at startup only.
+	Quality QualityPolicy `json:"quality"`
+
+	// LowData saves bandwidth on metered connections: streams are played
+	// at the lowest quality, the next track isn't resolved ahead and cover
+	// art isn't downloaded. The stats show an estimate of the data used.
+	// It can be toggled for the session from the command palette.
+	LowData bool `json:"low_data"`
+
+	// MusicDir is a folder of music files, laid out as Artist/Album/Track,
+	// that enables the local provider and the library browser. May start
+	// with ~. Read at startup only.
+	MusicDir string `json:"music_dir"`
+
+	// Zones name audio outputs by where they play, e.g. {"desk":
+	// "alsa/hw:0", "livingroom": "pulse/bluez_sink.xx"} with mpv
+	// --audio-device names. They head the device picker and the status bar
+	// shows the zone playing.
+	Zones map[string]string `json:"zones"`
+
+	// Radio keeps the music going when the queue runs out, with tracks
+	// related to the last one played (from YouTube mixes). Toggled with u
+	// in the TUI.
+	Radio bool `json:"radio"`
+
+	// Duck lowers the volume for a few seconds whenever a desktop
+	// notification arrives. It watches D-Bus with dbus-monitor.
+	Duck bool `json:"duck"`
+
+	// DuckMatch is the D-Bus match rule of the messages that duck, in
+	// place of notifications, e.g. "interface='org.freedesktop.Notifications'".
+	DuckMatch string `json:"duck_match"`
+
+	// Theme names the color theme: "auto" (default) picks "dark" or
+	// "light" to match the terminal's background; "solarized", "gruvbox"
+	// and "nord" are built in too, and Themes may add more. The terminal
+	// is only asked at startup; reloading keeps what "auto" picked then.
+	Theme string `json:"theme"`
+
+	// Themes defines color themes by name, e.g.
+	// {"mine": {"base": "nord", "selection": "#bf616a"}}. A theme named
+	// after a built-in one adjusts it.
+	Themes map[string]theme.Palette `json:"themes"`
+
+	// AlbumArt shows the cover art of the playing track in the Now Playing
+	// panel: "auto" (default) picks the best protocol the terminal is
+	// known to support, "kitty", "iterm2", "sixel" or "halfblock" force
+	// one, and "off" disables it.
+	AlbumArt string `json:"album_art"`
+
+	// NowPlaying writes the current track to files on every track change,
+	// for streaming overlays such as an OBS text or image source.
+	NowPlaying NowPlayingOutput `json:"now_playing"`
+
+	// Layout arranges the TUI panels until they are rearranged with the
+	// layout keys, which remember the new arrangement across runs.
+	Layout Layout `json:"layout"`
+
+	// SponsorBlock skips sponsor reads, self-promotion and other non-music
+	// segments of YouTube videos, as submitted to sponsor.ajay.app.
+	SponsorBlock SponsorBlockConfig `json:"sponsorblock"`
+
+	// Snapcast plays through a snapserver for synchronized multi-room
+	// audio. Read at startup only.
+	Snapcast SnapcastConfig `json:"snapcast"`
+}
+
+// Layout arranges the TUI panels.
+type Layout struct {
+	// View is "split" (default) for the search results beside the Now
+	// Playing and queue column, or "results" or "queue" for only one of
+	// them, full width.
+	View string `json:"view"`
+
+	// Split is the share of the width, in percent, given to the search
+	// results in the split view: 30 to 80, 0 means 67.
+	Split int `json:"split"`
+}
+
+// Layout views.
+const (
+	ViewSplit   = "split"
+	ViewResults = "results"
+	ViewQueue   = "queue"
+)
+
+// DefaultSplit is the share of the width given to the search results when
+// Layout.Split is unset.
+const DefaultSplit = 67
+
+// SplitPercent returns Split with the default filled in, kept within 30-80.
+func (l Layout) SplitPercent() int {
+	if l.Split == 0 {
+		return DefaultSplit
+	}
+	return max(30, min(80, l.Split))
+}
+
+// SponsorBlockConfig selects the SponsorBlock segments to skip.
+type SponsorBlockConfig struct {
+	Enabled bool `json:"enabled"`
+
+	// Categories are the segment categories to skip: "sponsor",
+	// "selfpromo", "interaction", "intro", "outro", "preview",
+	// "music_offtopic" or "filler". Empty means sponsor, selfpromo,
+	// interaction and music_offtopic.
+	Categories []string `json:"categories"`
+}
+
+// SnapcastConfig sends the audio to a snapserver and manages its clients.
+type SnapcastConfig struct {
+	// Pipe is the named pipe of the snapserver's pipe source, e.g.
+	// "/tmp/snapfifo" for "source = pipe:///tmp/snapfifo?name=audictl"
+	// (48000:16:2, snapserver's default format). Setting it plays into the
+	// pipe instead of a sound device.
+	Pipe string `json:"pipe"`
+
+	// Server is the host:port of the snapserver's JSON-RPC control, for
+	// listing the clients and setting their volume; empty means
+	// "localhost:1705".
+	Server string `json:"server"`
+}
+
+// NowPlayingOutput configures the now-playing files. Paths may start with
+// ~; an empty path disables that file.
+type NowPlayingOutput struct {
+	// File receives the track formatted with Format, and is emptied when
+	// playback stops.
+	File string `json:"file"`
+
+	// Format is a row template (see package format); empty means
+	// "{artist} - {title}". It may also use {elapsed}, the position in the
+	// track, which keeps the file rewritten every second while playing.
+	Format string `json:"format"`
+
+	// MaxWidth cuts longer lines with an ellipsis, for status bars with
+	// little room; 0 means no limit.
+	MaxWidth int `json:"max_width"`
+
+	// Art receives the track's cover art as a PNG, and is removed when
+	// there is none.
+	Art string `json:"art"`
+}
+
+// QualityPolicy configures which of a track's streams is played.
+type QualityPolicy struct {
+	// Preset is "best" (the default), "lossless" for lossless streams or
+	// else the highest bitrate whatever the codec, or "low" for the
+	// smallest stream within the limits. --quality overrides it.
+	Preset string `json:"preset"`
+
+	// Codecs lists the preferred codecs, best first; empty means
+	// ["opus", "aac"].
+	Codecs []string `json:"codecs"`
+
+	// MinKbps and MaxKbps bound the bitrate, e.g. a cap for metered
+	// connections; 0 means no bound.
+	MinKbps int `json:"min_kbps"`
+	MaxKbps int `json:"max_kbps"`
+}
+
+// DefaultNowPlayingFormat is the now-playing file template used when none
+// is set.
+const DefaultNowPlayingFormat = "{artist} - {title}"
+
+// DefaultNormalizeTarget is the loudnorm target used when none is set.
+const DefaultNormalizeTarget = -14.0
+
+// SkipRule applies to tracks whose channel/uploader (the "channel" tag, or
+// Track.Artist when there is none) equals Channel, compared
+// case-insensitively.
+type SkipRule struct {
+	Channel   string  `json:"channel"`
+	SkipStart float64 `json:"skip_start"` // seconds to skip at the beginning
+	SkipEnd   float64 `json:"skip_end"`   // seconds to cut from the end
+}
+
+// Default returns the configuration used when no config file exists.
+func Default() *Config {
+	return &Config{}
+}
+
+// Path returns the location of the config file.
+func Path() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot locate config dir: %w", err)
+		}
+		base = dir
+	}
+	return filepath.Join(base, "audictl", "config.json"), nil
+}
+
+// Load reads the config file. When the file is missing the defaults are
+// returned; when it is invalid the defaults are returned alongside the error.
+func Load() (*Config, error) {
+	cfg := Default()
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return Default(), fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Normalization returns the normalization mode ("loudnorm" or
+// "replaygain") and loudnorm target to use, with defaults filled in.
+func (c *Config) Normalization() (string, float64) {
+	mode := strings.ToLower(strings.TrimSpace(c.NormalizeMode))
+	if mode != "replaygain" {
+		mode = "loudnorm"
+	}
+	target := c.NormalizeTarget
+	if target == 0 {
+		target = DefaultNormalizeTarget
+	}
+	return mode, target
+}
+
+// ReplayWindowDuration returns ReplayWindow with the default filled in.
+func (c *Config) ReplayWindowDuration() time.Duration {
+	switch {
+	case c.ReplayWindow < 0:
+		return 0
+	case c.ReplayWindow == 0:
+		return 60 * time.Second
+	}
+	return time.Duration(c.ReplayWindow) * time.Second
+}
+
+// ResumeThreshold returns ResumeLongerThan as a duration with the default
+// filled in, or 0 when tracks never resume.
+func (c *Config) ResumeThreshold() time.Duration {
+	switch {
+	case c.ResumeLongerThan < 0:
+		return 0
+	case c.ResumeLongerThan == 0:
+		return 10 * time.Minute
+	}
+	return time.Duration(c.ResumeLongerThan) * time.Minute
+}
+
+// ProxyFor returns the proxy to use for name, one of the keys of Proxies:
+// its override when there is one, else Proxy.
+func (c *Config) ProxyFor(name string) string {
+	if proxy, ok := c.Proxies[name]; ok {
+		return proxy
+	}
+	return c.Proxy
+}
+
+// KeepPitch reports whether speed changes should be pitch corrected.
+func (c *Config) KeepPitch() bool {
+	return c.PitchCorrection == nil || *c.PitchCorrection
+}
+
+// SkipRuleFor returns the skip rule configured for channel, if any.
+func (c *Config) SkipRuleFor(channel string) (SkipRule, bool) {
+	channel = strings.TrimSpace(channel)
+	if channel == "" {
+		return SkipRule{}, false
+	}
+	for _, r := range c.SkipRules {
+		if strings.EqualFold(strings.TrimSpace(r.Channel), channel) {
+			return r, true
+		}
+	}
+	return SkipRule{}, false
+}
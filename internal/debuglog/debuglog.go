@@ -0,0 +1,57 @@
+// Package debuglog keeps the latest diagnostic lines in memory, such as
+// the yt-dlp commands run and the traffic with mpv, for the debug console
+// to show without leaving the TUI.
+package debuglog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keep is how many lines are kept; older ones are dropped.
+const keep = 1000
+
+// Line is one logged line.
+type Line struct {
+	Time   time.Time
+	Source string // what it came from: "app", "yt-dlp", "mpv", ...
+	Text   string
+}
+
+var (
+	mu      sync.Mutex
+	lines   []Line
+	onWrite func()
+)
+
+// Printf logs a line from source.
+func Printf(source, format string, args ...interface{}) {
+	text := strings.TrimRight(fmt.Sprintf(format, args...), "\n")
+	mu.Lock()
+	lines = append(lines, Line{Time: time.Now(), Source: source, Text: text})
+	if len(lines) > keep {
+		lines = append(lines[:0:0], lines[len(lines)-keep:]...)
+	}
+	fn := onWrite
+	mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// Lines returns the kept lines, oldest first.
+func Lines() []Line {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Line(nil), lines...)
+}
+
+// OnWrite sets a function to call after every line is logged, or none
+// with nil. It must not log itself.
+func OnWrite(fn func()) {
+	mu.Lock()
+	onWrite = fn
+	mu.Unlock()
+}
@@ -0,0 +1,69 @@
+// Package desktop hands text and links over to the rest of the desktop:
+// the system clipboard and the default browser.
+package desktop
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboards are the clipboard tools tried in order, each with the
+// environment variable that says its display server is available.
+var clipboards = []struct {
+	env  string
+	args []string
+}{
+	{"WAYLAND_DISPLAY", []string{"wl-copy"}},
+	{"DISPLAY", []string{"xclip", "-selection", "clipboard"}},
+	{"DISPLAY", []string{"xsel", "--clipboard", "--input"}},
+	{"", []string{"pbcopy"}},
+	{"", []string{"clip.exe"}},
+}
+
+// Copy puts text on the system clipboard using the first clipboard tool
+// that is installed. Without one it falls back to the OSC 52 escape
+// sequence, which most terminals (including over SSH) turn into a copy.
+func Copy(text string) error {
+	for _, c := range clipboards {
+		if c.env != "" && os.Getenv(c.env) == "" {
+			continue
+		}
+		path, err := exec.LookPath(c.args[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, c.args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+	}
+	defer tty.Close()
+	_, err = fmt.Fprintf(tty, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
+
+// Open opens url in the default browser without waiting for it.
+func Open(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
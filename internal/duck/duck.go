@@ -0,0 +1,53 @@
+// Package duck watches the D-Bus session bus for events (desktop
+// notifications by default) so the player can briefly lower its volume.
+package duck
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DefaultMatch matches notifications sent through the freedesktop
+// notification service.
+const DefaultMatch = "interface='org.freedesktop.Notifications',member='Notify'"
+
+// Watch runs dbus-monitor with the given match rule and calls fn for every
+// matching message. An empty match uses DefaultMatch. The returned function
+// stops the watcher.
+func Watch(match string, fn func()) (stop func(), err error) {
+	if match == "" {
+		match = DefaultMatch
+	}
+	cmd := exec.Command("dbus-monitor", "--session", match)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start dbus-monitor: %w", err)
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(out)
+		for scanner.Scan() {
+			line := scanner.Text()
+			// Each message starts with a header line; arguments follow indented.
+			if strings.HasPrefix(line, "method call") || strings.HasPrefix(line, "signal") {
+				// dbus-monitor announces its own NameAcquired/NameLost signals
+				if strings.Contains(line, "member=NameAcquired") || strings.Contains(line, "member=NameLost") {
+					continue
+				}
+				fn()
+			}
+		}
+		_ = cmd.Wait()
+	}()
+
+	return func() {
+		if cmd.Process != nil {
+			_ = cmd.Process.Kill()
+		}
+	}, nil
+}
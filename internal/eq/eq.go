@@ -0,0 +1,114 @@
+// Package eq describes equalizer settings and turns them into mpv audio
+// filters built from ffmpeg's peaking equalizer.
+package eq
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Frequencies are the centre frequencies (Hz) of the standard ten bands.
+var Frequencies = []float64{31, 62, 125, 250, 500, 1000, 2000, 4000, 8000, 16000}
+
+// MaxGain bounds the gain of a band in either direction, in dB.
+const MaxGain = 12.0
+
+// Band boosts or cuts the frequencies around Freq by Gain dB.
+type Band struct {
+	Freq float64 `json:"freq"`
+	Gain float64 `json:"gain"`
+}
+
+// Preset is a named set of bands.
+type Preset struct {
+	Name  string
+	Bands []Band
+}
+
+// gains builds bands for Frequencies from one gain per band.
+func gains(g ...float64) []Band {
+	bands := make([]Band, len(Frequencies))
+	for i, f := range Frequencies {
+		bands[i] = Band{Freq: f, Gain: g[i]}
+	}
+	return bands
+}
+
+// builtin are the presets that are always available.
+var builtin = []Preset{
+	{"flat", gains(0, 0, 0, 0, 0, 0, 0, 0, 0, 0)},
+	{"bass boost", gains(6, 5, 4, 2, 0, 0, 0, 0, 0, 0)},
+	{"vocal", gains(-2, -2, -1, 0, 2, 4, 4, 2, 0, -1)},
+	{"treble boost", gains(0, 0, 0, 0, 0, 0, 2, 4, 5, 6)},
+	{"loudness", gains(5, 4, 2, 0, -1, 0, 0, 2, 4, 4)},
+}
+
+// Presets returns the built-in presets followed by custom ones sorted by
+// name. A custom preset with a built-in name replaces it.
+func Presets(custom map[string][]Band) []Preset {
+	var out []Preset
+	for _, p := range builtin {
+		if bands, ok := custom[p.Name]; ok {
+			p.Bands = bands
+		}
+		out = append(out, p)
+	}
+	var names []string
+	for name := range custom {
+		if _, ok := Find(builtin, name); !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out = append(out, Preset{Name: name, Bands: custom[name]})
+	}
+	return out
+}
+
+// Find returns the preset called name, compared case-insensitively.
+func Find(presets []Preset, name string) (Preset, bool) {
+	for _, p := range presets {
+		if strings.EqualFold(p.Name, strings.TrimSpace(name)) {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// Clamp limits gain to ±MaxGain.
+func Clamp(gain float64) float64 {
+	if gain > MaxGain {
+		return MaxGain
+	}
+	if gain < -MaxGain {
+		return -MaxGain
+	}
+	return gain
+}
+
+// Filter returns the mpv filter for bands, or "" if they leave the sound
+// unchanged.
+func Filter(bands []Band) string {
+	var parts []string
+	for _, b := range bands {
+		if b.Gain == 0 || b.Freq <= 0 {
+			continue
+		}
+		// One octave wide peaking filter per band
+		parts = append(parts, fmt.Sprintf("equalizer=f=%g:t=o:w=1:g=%.1f", b.Freq, Clamp(b.Gain)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "lavfi=[" + strings.Join(parts, ",") + "]"
+}
+
+// Label formats a band frequency for display, e.g. "125" or "2k".
+func Label(freq float64) string {
+	if freq >= 1000 {
+		return fmt.Sprintf("%gk", freq/1000)
+	}
+	return fmt.Sprintf("%g", freq)
+}
@@ -0,0 +1,82 @@
+// Package format renders tracks as display rows from user-configurable
+// templates such as "{index}. {artist} - {title} [{duration}]".
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"audictl/internal/lang"
+	"audictl/internal/provider"
+)
+
+const (
+	// DefaultResultRow is used for search results when no template is configured.
+	DefaultResultRow = "{index}. {artist} - {title} [{duration}]"
+	// DefaultQueueRow is used for queue entries when no template is configured.
+	DefaultQueueRow = "{index}. {title} [{duration}]"
+)
+
+// providerIcons are the glyphs substituted for {icon}.
+var providerIcons = map[string]string{
+	"youtube": "▶",
+	"spotify": "●",
+	"direct":  "♫",
+}
+
+// Duration formats seconds as m:ss, or h:mm:ss for tracks of an hour or more.
+// Unknown durations (<= 0) yield "".
+func Duration(secs int) string {
+	if secs <= 0 {
+		return ""
+	}
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs%3600/60, secs%60)
+	}
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// ParseDuration parses a timestamp such as "83", "1:23", "1:23.5" or
+// "1:02:03" into seconds.
+func ParseDuration(s string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	var secs float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || v < 0 || (i > 0 && v >= 60) {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		secs = secs*60 + v
+	}
+	return secs, nil
+}
+
+// Row expands the placeholders in tmpl for track t at position index
+// (1-based): {index}, {title}, {artist}, {channel}, {album}, {duration},
+// {provider}, {icon} and {lang}. Brackets left empty by missing values are removed.
+func Row(tmpl string, index int, t provider.Track) string {
+	channel := t.Tags["channel"]
+	if channel == "" {
+		channel = t.Artist
+	}
+	r := strings.NewReplacer(
+		"{index}", strconv.Itoa(index),
+		"{title}", t.Title,
+		"{artist}", t.Artist,
+		"{channel}", channel,
+		"{album}", t.Album,
+		"{duration}", Duration(t.Duration),
+		"{provider}", t.Provider,
+		"{icon}", providerIcons[t.Provider],
+		"{lang}", lang.Of(t),
+	)
+	row := r.Replace(tmpl)
+	for _, empty := range []string{" []", " ()", "[]", "()"} {
+		row = strings.ReplaceAll(row, empty, "")
+	}
+	return strings.TrimSpace(row)
+}
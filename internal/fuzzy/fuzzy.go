@@ -0,0 +1,67 @@
+// Package fuzzy ranks strings by how well they match a pattern typed into
+// a finder, such as the search history or the command palette.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+)
+
+// Rank returns the indices of the items that contain the characters of
+// pattern in order, ignoring case and spaces, best first: items containing
+// pattern as is, then those where the characters are closest together.
+// Equally good matches keep the order of items. An empty pattern matches
+// everything.
+func Rank(items []string, pattern string) []int {
+	pattern = strings.Join(strings.Fields(strings.ToLower(pattern)), "")
+	if pattern == "" {
+		out := make([]int, len(items))
+		for i := range items {
+			out[i] = i
+		}
+		return out
+	}
+	type match struct {
+		index int
+		score int // lower is better
+	}
+	var matches []match
+	for i, item := range items {
+		lower := strings.ToLower(item)
+		if strings.Contains(strings.Join(strings.Fields(lower), ""), pattern) {
+			matches = append(matches, match{i, 0})
+			continue
+		}
+		if spread, ok := subsequence(lower, pattern); ok {
+			matches = append(matches, match{i, spread})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score < matches[j].score
+	})
+	out := make([]int, len(matches))
+	for i, m := range matches {
+		out[i] = m.index
+	}
+	return out
+}
+
+// subsequence reports whether the runes of pattern appear in s in order,
+// and how far apart: the length of the span they cover.
+func subsequence(s, pattern string) (int, bool) {
+	p := []rune(pattern)
+	i, first := 0, -1
+	for pos, r := range []rune(s) {
+		if r != p[i] {
+			continue
+		}
+		if first < 0 {
+			first = pos
+		}
+		i++
+		if i == len(p) {
+			return pos - first + 1, true
+		}
+	}
+	return 0, false
+}
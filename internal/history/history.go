@@ -0,0 +1,173 @@
+// Package history keeps a log of every played track as JSON lines in the
+// data directory (history.jsonl), newest at the end.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"audictl/internal/provider"
+	"audictl/internal/store"
+)
+
+const historyFile = "history.jsonl"
+
+// Entry is one play of a track. Completion is the share of the track that
+// was listened to, in percent (0 when the duration is unknown), and
+// Listened how long it played in seconds (0 in entries from before it was
+// recorded).
+type Entry struct {
+	Track      provider.Track `json:"track"`
+	PlayedAt   time.Time      `json:"played_at"`
+	Completion float64        `json:"completion"`
+	Listened   int            `json:"listened,omitempty"`
+}
+
+var mu sync.Mutex
+
+// Record appends an entry to the history log.
+func Record(e Entry) error {
+	path, err := store.Path(historyFile)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// All returns every recorded entry, oldest first. Lines that fail to parse
+// are skipped so one bad write never hides the rest of the log.
+func All() ([]Entry, error) {
+	path, err := store.Path(historyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Import adds entries from elsewhere, such as another player's export, to
+// the log and returns how many were new. Entries already in the log, the
+// same track played at the same second, are left out so importing twice
+// adds nothing. The log is rewritten in order of play.
+func Import(entries []Entry) (int, error) {
+	existing, err := All()
+	if err != nil {
+		return 0, err
+	}
+	path, err := store.Path(historyFile)
+	if err != nil {
+		return 0, err
+	}
+
+	type key struct {
+		id string
+		at int64
+	}
+	seen := map[key]bool{}
+	for _, e := range existing {
+		seen[key{e.Track.ID, e.PlayedAt.Unix()}] = true
+	}
+	all := existing
+	for _, e := range entries {
+		k := key{e.Track.ID, e.PlayedAt.Unix()}
+		if !seen[k] {
+			seen[k] = true
+			all = append(all, e)
+		}
+	}
+	added := len(all) - len(existing)
+	if added == 0 {
+		return 0, nil
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].PlayedAt.Before(all[j].PlayedAt) })
+
+	var buf bytes.Buffer
+	for _, e := range all {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return 0, err
+		}
+		buf.Write(append(data, '\n'))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return 0, err
+	}
+	return added, os.Rename(tmp, path)
+}
+
+// Recent returns up to limit entries, newest first. A limit <= 0 returns
+// everything.
+func Recent(limit int) ([]Entry, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > len(all) {
+		limit = len(all)
+	}
+	recent := make([]Entry, 0, limit)
+	for i := len(all) - 1; i >= 0 && len(recent) < limit; i-- {
+		recent = append(recent, all[i])
+	}
+	return recent, nil
+}
+
+// Completion computes the listened share of a track in percent.
+func Completion(listened time.Duration, duration int) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	pct := listened.Seconds() / float64(duration) * 100
+	if pct > 100 {
+		pct = 100
+	}
+	if pct < 0 {
+		pct = 0
+	}
+	return pct
+}
@@ -0,0 +1,107 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// skipBelow is the completion, in percent, under which a play counts as
+// skipped.
+const skipBelow = 50
+
+// ListenedFor returns how long e played: the recorded time, or for older
+// entries the completed share of the duration, never more than the track
+// is long.
+func (e Entry) ListenedFor() time.Duration {
+	secs := float64(e.Listened)
+	if secs == 0 {
+		secs = e.Completion / 100 * float64(e.Track.Duration)
+	}
+	if e.Track.Duration > 0 {
+		secs = min(secs, float64(e.Track.Duration))
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
+// Skipped reports whether e was stopped well before the end of the track.
+// Plays of tracks without a known duration never count as skipped.
+func (e Entry) Skipped() bool {
+	return e.Track.Duration > 0 && e.Completion < skipBelow
+}
+
+// Count is how much an artist or a track was played.
+type Count struct {
+	Name     string // the artist, or "artist - title" for a track
+	Plays    int
+	Skips    int
+	Listened time.Duration
+}
+
+// Stats sums up the history over a period.
+type Stats struct {
+	Plays    int
+	Skips    int
+	Listened time.Duration
+	Artists  []Count // most played first
+	Tracks   []Count // most played first
+}
+
+// Summarize counts the plays in entries after since (all of them when
+// since is zero), per artist and per track.
+func Summarize(entries []Entry, since time.Time) Stats {
+	var s Stats
+	artists := map[string]*Count{}
+	tracks := map[string]*Count{}
+	add := func(counts map[string]*Count, key, name string, e Entry) {
+		c, ok := counts[key]
+		if !ok {
+			c = &Count{Name: name}
+			counts[key] = c
+		}
+		c.Plays++
+		c.Listened += e.ListenedFor()
+		if e.Skipped() {
+			c.Skips++
+		}
+	}
+	for _, e := range entries {
+		if e.PlayedAt.Before(since) {
+			continue
+		}
+		s.Plays++
+		s.Listened += e.ListenedFor()
+		if e.Skipped() {
+			s.Skips++
+		}
+		if artist := strings.TrimSpace(e.Track.Artist); artist != "" {
+			add(artists, strings.ToLower(artist), artist, e)
+		}
+		name := e.Track.Title
+		if e.Track.Artist != "" {
+			name = e.Track.Artist + " - " + e.Track.Title
+		}
+		add(tracks, e.Track.ID, name, e)
+	}
+	s.Artists = ranked(artists)
+	s.Tracks = ranked(tracks)
+	return s
+}
+
+// ranked returns counts by plays, then by time listened.
+func ranked(counts map[string]*Count) []Count {
+	out := make([]Count, 0, len(counts))
+	for _, c := range counts {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Plays != out[j].Plays {
+			return out[i].Plays > out[j].Plays
+		}
+		if out[i].Listened != out[j].Listened {
+			return out[i].Listened > out[j].Listened
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
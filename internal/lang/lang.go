@@ -0,0 +1,126 @@
+// Package lang guesses and filters tracks by language.
+package lang
+
+import (
+	"strings"
+	"unicode"
+
+	"audictl/internal/provider"
+)
+
+// scripts maps Unicode scripts with a dominant language to its ISO 639-1
+// code. Latin script is deliberately absent: it can't tell English from
+// Spanish, so Latin-only titles stay untagged unless metadata says otherwise.
+var scripts = []struct {
+	table *unicode.RangeTable
+	code  string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Bengali, "bn"},
+	{unicode.Gurmukhi, "pa"},
+	{unicode.Gujarati, "gu"},
+	{unicode.Tamil, "ta"},
+	{unicode.Telugu, "te"},
+	{unicode.Kannada, "kn"},
+	{unicode.Malayalam, "ml"},
+	{unicode.Sinhala, "si"},
+}
+
+// Detect guesses the language of texts from the script of their letters and
+// returns its ISO 639-1 code, or "" if there is no non-Latin script to go by.
+// Any kana makes the text Japanese, since Japanese also uses Han characters.
+func Detect(texts ...string) string {
+	counts := map[string]int{}
+	for _, text := range texts {
+		for _, r := range text {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			for _, s := range scripts {
+				if unicode.Is(s.table, r) {
+					counts[s.code]++
+					break
+				}
+			}
+		}
+	}
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	best, n := "", 0
+	for _, s := range scripts {
+		if c := counts[s.code]; c > n {
+			best, n = s.code, c
+		}
+	}
+	return best
+}
+
+// Of returns track's language: the "language" tag set by its provider if
+// any, otherwise a guess from its title and artist.
+func Of(t provider.Track) string {
+	if code := t.Tags["language"]; code != "" {
+		return code
+	}
+	return Detect(t.Title, t.Artist)
+}
+
+// Tag records the language of t in its tags, preferring metadata when the
+// provider reported one.
+func Tag(t *provider.Track, metadata string) {
+	code := normalize(metadata)
+	if code == "" {
+		code = Detect(t.Title, t.Artist)
+	}
+	if code == "" {
+		return
+	}
+	if t.Tags == nil {
+		t.Tags = map[string]string{}
+	}
+	t.Tags["language"] = code
+}
+
+// ParseFilter splits a "lang:xx" token off a search query, returning the
+// remaining query and the requested language code.
+func ParseFilter(query string) (string, string) {
+	var rest []string
+	code := ""
+	for _, field := range strings.Fields(query) {
+		if v, ok := strings.CutPrefix(strings.ToLower(field), "lang:"); ok && v != "" {
+			code = normalize(v)
+			continue
+		}
+		rest = append(rest, field)
+	}
+	return strings.Join(rest, " "), code
+}
+
+// Filter returns the tracks whose language is code.
+func Filter(tracks []provider.Track, code string) []provider.Track {
+	var out []provider.Track
+	for _, t := range tracks {
+		if Of(t) == code {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// normalize reduces tags like "en-US" or "ta_IN" to the bare language code.
+func normalize(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	return code
+}
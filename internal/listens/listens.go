@@ -0,0 +1,271 @@
+// Package listens reads the listening history other services let you
+// export, so the stats and suggestions can start from real data: Spotify's
+// account data and extended streaming history, and Last.fm scrobbles as
+// CSV.
+//
+// Imported tracks are played by searching YouTube for them, the same as
+// Spotify links are.
+package listens
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"audictl/internal/history"
+	"audictl/internal/provider"
+)
+
+// minPlayed is how long a stream must have played to count, as for plays
+// in the player itself.
+const minPlayed = 2 * time.Second
+
+// ErrNothingFound is returned when a folder or file holds no listens.
+var ErrNothingFound = errors.New("no listening history found")
+
+// Export is what a Spotify data export holds.
+type Export struct {
+	Listens []history.Entry
+	Liked   []provider.Track
+}
+
+// Track returns the track for a listen of title by artist.
+func Track(artist, title, album string) provider.Track {
+	query := title
+	if artist != "" {
+		query = artist + " - " + title
+	}
+	return provider.Track{
+		ID:       "search:" + query,
+		Provider: "youtube",
+		Title:    title,
+		Artist:   artist,
+		Album:    album,
+	}
+}
+
+// spotifyLink turns a spotify:track:<id> URI into a link to the track.
+func spotifyLink(uri string) string {
+	id, ok := strings.CutPrefix(uri, "spotify:track:")
+	if !ok || id == "" {
+		return ""
+	}
+	return "https://open.spotify.com/track/" + id
+}
+
+// streamed is one entry of StreamingHistory*.json (account data) or
+// Streaming_History_Audio_*.json / endsong_*.json (extended history).
+type streamed struct {
+	// Account data
+	EndTime    string `json:"endTime"`
+	ArtistName string `json:"artistName"`
+	TrackName  string `json:"trackName"`
+	MsPlayed   int64  `json:"msPlayed"`
+
+	// Extended streaming history
+	TS       string `json:"ts"`
+	PlayedMs int64  `json:"ms_played"`
+	Track    string `json:"master_metadata_track_name"`
+	Artist   string `json:"master_metadata_album_artist_name"`
+	Album    string `json:"master_metadata_album_album_name"`
+	URI      string `json:"spotify_track_uri"`
+}
+
+// entry turns s into a history entry; ok is false for podcasts and plays
+// too short to count.
+func (s streamed) entry() (history.Entry, bool) {
+	if s.TS != "" {
+		played := time.Duration(s.PlayedMs) * time.Millisecond
+		at, err := time.Parse(time.RFC3339, s.TS)
+		if err != nil || s.Track == "" || played < minPlayed {
+			return history.Entry{}, false
+		}
+		t := Track(s.Artist, s.Track, s.Album)
+		if link := spotifyLink(s.URI); link != "" {
+			t.Links = map[string]string{"spotify": link}
+		}
+		// ts is when the stream ended
+		return history.Entry{Track: t, PlayedAt: at.Add(-played), Listened: int(played.Seconds())}, true
+	}
+	played := time.Duration(s.MsPlayed) * time.Millisecond
+	at, err := time.Parse("2006-01-02 15:04", s.EndTime)
+	if err != nil || s.TrackName == "" || played < minPlayed {
+		return history.Entry{}, false
+	}
+	t := Track(s.ArtistName, s.TrackName, "")
+	return history.Entry{Track: t, PlayedAt: at.Add(-played), Listened: int(played.Seconds())}, true
+}
+
+// library is YourLibrary.json, the liked songs among others.
+type library struct {
+	Tracks []struct {
+		Artist string `json:"artist"`
+		Album  string `json:"album"`
+		Track  string `json:"track"`
+		URI    string `json:"uri"`
+	} `json:"tracks"`
+}
+
+// Spotify reads the listens and liked songs of a Spotify data export:
+// the unpacked folder of either "Account data" or "Extended streaming
+// history", or any folder below which they are.
+func Spotify(dir string) (Export, error) {
+	var exp Export
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		name := d.Name()
+		switch {
+		case strings.HasPrefix(name, "StreamingHistory"),
+			strings.HasPrefix(name, "Streaming_History_Audio"),
+			strings.HasPrefix(name, "endsong"):
+			var streams []streamed
+			if err := readJSON(path, &streams); err != nil {
+				return err
+			}
+			for _, s := range streams {
+				if e, ok := s.entry(); ok {
+					exp.Listens = append(exp.Listens, e)
+				}
+			}
+		case name == "YourLibrary.json":
+			var lib library
+			if err := readJSON(path, &lib); err != nil {
+				return err
+			}
+			for _, l := range lib.Tracks {
+				t := Track(l.Artist, l.Track, l.Album)
+				if link := spotifyLink(l.URI); link != "" {
+					t.Links = map[string]string{"spotify": link}
+				}
+				exp.Liked = append(exp.Liked, t)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Export{}, err
+	}
+	if len(exp.Listens) == 0 && len(exp.Liked) == 0 {
+		return Export{}, fmt.Errorf("%s: %w", dir, ErrNothingFound)
+	}
+	return exp, nil
+}
+
+// readJSON decodes the JSON file at path into v.
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// lastfmTimes are the date formats Last.fm exporters write.
+var lastfmTimes = []string{
+	"02 Jan 2006 15:04",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006, 15:04",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+}
+
+// Lastfm reads scrobbles from a CSV export. Files with a header row (such
+// as "uts,utc_time,artist,...,track,...") are read by column name; files
+// without one are taken as artist, album, track, date, as the common
+// lastfm-to-csv exporter writes them. Scrobbles lack how long they were
+// played for.
+func Lastfm(path string) ([]history.Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	cols := map[string]int{"artist": 0, "album": 1, "track": 2, "date": 3}
+	var entries []history.Entry
+	for first := true; ; first = false {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first && isHeader(rec) {
+			cols = map[string]int{}
+			for i, name := range rec {
+				cols[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+			if _, ok := cols["track"]; !ok {
+				cols["track"] = column(cols, "title")
+			}
+			continue
+		}
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i >= 0 && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		title := field("track")
+		at, ok := scrobbleTime(field("uts"), field("date"), field("utc_time"))
+		if title == "" || !ok {
+			continue
+		}
+		entries = append(entries, history.Entry{Track: Track(field("artist"), title, field("album")), PlayedAt: at})
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, ErrNothingFound)
+	}
+	return entries, nil
+}
+
+// isHeader reports whether rec names columns rather than holding a
+// scrobble.
+func isHeader(rec []string) bool {
+	for _, f := range rec {
+		switch strings.ToLower(strings.TrimSpace(f)) {
+		case "artist", "track", "title", "uts":
+			return true
+		}
+	}
+	return false
+}
+
+// column returns the index of name in cols, or -1.
+func column(cols map[string]int, name string) int {
+	if i, ok := cols[name]; ok {
+		return i
+	}
+	return -1
+}
+
+// scrobbleTime reads when a scrobble was, from a unix time or the first
+// date that parses.
+func scrobbleTime(uts string, dates ...string) (time.Time, bool) {
+	if secs, err := strconv.ParseInt(uts, 10, 64); err == nil && secs > 0 {
+		return time.Unix(secs, 0), true
+	}
+	for _, d := range dates {
+		for _, layout := range lastfmTimes {
+			if t, err := time.Parse(layout, d); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,216 @@
+// Package lyrics fetches song lyrics from LRCLIB (lrclib.net), preferring
+// time-synced LRC lyrics and falling back to plain text, and caches them
+// under the data directory.
+package lyrics
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"audictl/internal/ratelimit"
+	"audictl/internal/store"
+	"audictl/internal/trackmeta"
+)
+
+// API is the LRCLIB server queried by Fetch.
+const API = "https://lrclib.net/api"
+
+// ErrNotFound is returned when no lyrics are known for a song.
+var ErrNotFound = errors.New("no lyrics found")
+
+// Line is one line of synced lyrics, sung from Time seconds on.
+type Line struct {
+	Time float64 `json:"time"`
+	Text string  `json:"text"`
+}
+
+// Lyrics are the lyrics of one song. Synced is empty when only plain text
+// is known.
+type Lyrics struct {
+	Synced       []Line `json:"synced,omitempty"`
+	Plain        string `json:"plain,omitempty"`
+	Instrumental bool   `json:"instrumental,omitempty"`
+}
+
+// LineAt returns the index of the synced line being sung at pos seconds,
+// or -1 before the first one.
+func (l Lyrics) LineAt(pos float64) int {
+	return sort.Search(len(l.Synced), func(i int) bool { return l.Synced[i].Time > pos }) - 1
+}
+
+// record is an LRCLIB track as returned by /get and /search.
+type record struct {
+	Instrumental bool   `json:"instrumental"`
+	PlainLyrics  string `json:"plainLyrics"`
+	SyncedLyrics string `json:"syncedLyrics"`
+}
+
+func (r record) lyrics() Lyrics {
+	return Lyrics{Synced: ParseLRC(r.SyncedLyrics), Plain: strings.TrimSpace(r.PlainLyrics), Instrumental: r.Instrumental}
+}
+
+func (r record) empty() bool {
+	return !r.Instrumental && r.SyncedLyrics == "" && r.PlainLyrics == ""
+}
+
+// Fetch returns the lyrics of title by artist, from the cache when they
+// were fetched before. duration (seconds, 0 if unknown) helps LRCLIB pick
+// the right recording. Artist and title are cleaned up first (see trackmeta.Clean).
+func Fetch(artist, title string, duration int) (Lyrics, error) {
+	artist, title = trackmeta.Clean(artist, title)
+	key := cacheKey(artist, title)
+	if l, ok := loadCached(key); ok {
+		return l, nil
+	}
+	l, err := fetch(artist, title, duration)
+	if err != nil {
+		return Lyrics{}, err
+	}
+	saveCached(key, l)
+	return l, nil
+}
+
+func fetch(artist, title string, duration int) (Lyrics, error) {
+	// An exact match first, then a looser search
+	q := url.Values{"artist_name": {artist}, "track_name": {title}}
+	if duration > 0 {
+		q.Set("duration", strconv.Itoa(duration))
+	}
+	var rec record
+	found, err := get(API+"/get?"+q.Encode(), &rec)
+	if err != nil {
+		return Lyrics{}, err
+	}
+	if found && !rec.empty() && (rec.SyncedLyrics != "" || rec.Instrumental) {
+		return rec.lyrics(), nil
+	}
+
+	var results []record
+	search := url.Values{"q": {strings.TrimSpace(artist + " " + title)}}
+	if _, err := get(API+"/search?"+search.Encode(), &results); err != nil {
+		return Lyrics{}, err
+	}
+	for _, r := range results {
+		if r.SyncedLyrics != "" {
+			return r.lyrics(), nil
+		}
+	}
+	if found && !rec.empty() {
+		return rec.lyrics(), nil
+	}
+	for _, r := range results {
+		if !r.empty() {
+			return r.lyrics(), nil
+		}
+	}
+	return Lyrics{}, ErrNotFound
+}
+
+// get decodes the JSON at rawURL into v. It reports false for a 404.
+func get(rawURL string, v interface{}) (bool, error) {
+	resp, err := ratelimit.Get(rawURL)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("lrclib: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("lrclib: %w", err)
+	}
+	return true, nil
+}
+
+// lrcTag matches the timestamps at the start of an LRC line, e.g.
+// [01:23.45].
+var lrcTag = regexp.MustCompile(`^\[(\d+):(\d+(?:\.\d+)?)\]`)
+
+// ParseLRC parses LRC lyrics into lines sorted by time. Lines with several
+// timestamps (repeated choruses) appear once per timestamp; metadata tags
+// such as [ar:...] are skipped.
+func ParseLRC(text string) []Line {
+	var lines []Line
+	for _, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimSpace(raw)
+		var times []float64
+		for {
+			m := lrcTag.FindStringSubmatch(raw)
+			if m == nil {
+				break
+			}
+			min, _ := strconv.ParseFloat(m[1], 64)
+			sec, _ := strconv.ParseFloat(m[2], 64)
+			times = append(times, min*60+sec)
+			raw = raw[len(m[0]):]
+		}
+		for _, t := range times {
+			lines = append(lines, Line{Time: t, Text: strings.TrimSpace(raw)})
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Time < lines[j].Time })
+	return lines
+}
+
+func cacheKey(artist, title string) string {
+	sum := sha1.Sum([]byte(strings.ToLower(artist + "\x00" + title)))
+	return hex.EncodeToString(sum[:])
+}
+
+func cachePath(key string) (string, error) {
+	dir, err := store.Path("lyrics")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, key+".json"), nil
+}
+
+func loadCached(key string) (Lyrics, bool) {
+	path, err := cachePath(key)
+	if err != nil {
+		return Lyrics{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Lyrics{}, false
+	}
+	var l Lyrics
+	if json.Unmarshal(data, &l) != nil {
+		return Lyrics{}, false
+	}
+	return l, true
+}
+
+// saveCached stores l; the cache is only an optimization, so failures are
+// ignored.
+func saveCached(key string, l Lyrics) {
+	path, err := cachePath(key)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if os.WriteFile(tmp, data, 0o644) == nil {
+		_ = os.Rename(tmp, path)
+	}
+}
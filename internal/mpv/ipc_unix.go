@@ -0,0 +1,21 @@
+//go:build !windows
+
+package mpv
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ipcPath returns where the IPC server called name listens: a unix socket
+// in the temporary directory.
+func ipcPath(name string) string {
+	return filepath.Join(os.TempDir(), name)
+}
+
+// dialIPC connects to mpv's IPC server at path.
+func dialIPC(path string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout("unix", path, timeout)
+}
@@ -0,0 +1,129 @@
+//go:build windows
+
+package mpv
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// errPipeBusy is ERROR_PIPE_BUSY: every instance of the pipe is taken for
+// now.
+const errPipeBusy = syscall.Errno(231)
+
+// ipcPath returns where the IPC server called name listens: mpv serves
+// --input-ipc-server on a named pipe on Windows.
+func ipcPath(name string) string {
+	return `\\.\pipe\` + name
+}
+
+// dialIPC connects to mpv's named pipe at path, retrying while it doesn't
+// exist yet or is busy until timeout.
+func dialIPC(path string, timeout time.Duration) (net.Conn, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err == nil {
+			return &pipeConn{File: f}, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, errPipeBusy) || time.Now().After(deadline) {
+			return nil, err
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// pipeConn is a named pipe opened as a file, used as a net.Conn. A file
+// opened this way can't time out its reads and writes, so past a deadline
+// they are given up on and the pipe is closed under them.
+type pipeConn struct {
+	*os.File
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func (*pipeConn) LocalAddr() net.Addr  { return pipeAddr{} }
+func (*pipeConn) RemoteAddr() net.Addr { return pipeAddr{} }
+
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline, c.writeDeadline = t, t
+	return nil
+}
+
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	return nil
+}
+
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeDeadline = t
+	return nil
+}
+
+func (c *pipeConn) Read(b []byte) (int, error) {
+	c.mu.Lock()
+	deadline := c.readDeadline
+	c.mu.Unlock()
+	if deadline.IsZero() {
+		return c.File.Read(b)
+	}
+	// A read given up on may still finish later, into buf rather than b
+	buf := make([]byte, len(b))
+	n, err := c.timed(deadline, func() (int, error) { return c.File.Read(buf) })
+	return copy(b, buf[:n]), err
+}
+
+func (c *pipeConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	deadline := c.writeDeadline
+	c.mu.Unlock()
+	if deadline.IsZero() {
+		return c.File.Write(b)
+	}
+	buf := append([]byte(nil), b...)
+	return c.timed(deadline, func() (int, error) { return c.File.Write(buf) })
+}
+
+// timed runs op, closing the pipe and returning os.ErrDeadlineExceeded if
+// it hasn't returned by deadline.
+func (c *pipeConn) timed(deadline time.Time, op func() (int, error)) (int, error) {
+	wait := time.Until(deadline)
+	if wait <= 0 {
+		return 0, os.ErrDeadlineExceeded
+	}
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		n, err := op()
+		done <- result{n, err}
+	}()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.n, r.err
+	case <-timer.C:
+		c.File.Close()
+		return 0, os.ErrDeadlineExceeded
+	}
+}
+
+// pipeAddr is the address of a pipeConn.
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
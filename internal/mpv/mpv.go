@@ -3,56 +3,304 @@ package mpv
 import (
 	"encoding/json"
 	"fmt"
-	"net"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"syscall"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
+
+	"audictl/internal/debuglog"
+	"audictl/internal/proc"
 )
 
-// Start spawns mpv and returns the started *exec.Cmd. Caller may kill or Wait on it.
-func Start(url string, title string, device string, resample bool) (*exec.Cmd, error) {
+// Options controls how Start spawns mpv.
+type Options struct {
+	Device   string // value for --audio-device, empty for mpv's default
+	Resample bool   // allow resampling to the device rate
+	Video    bool   // open a video window instead of playing audio-only
+
+	// AudioOutput selects mpv's audio driver (--ao), e.g. "null" to play
+	// without a sound device; empty for mpv's default.
+	AudioOutput string
+
+	// YtDlpPath points mpv's ytdl_hook, which plays YouTube page URLs, at a
+	// yt-dlp outside PATH; empty for mpv's default.
+	YtDlpPath string
+
+	// YtDlpOptions are passed to yt-dlp by the ytdl_hook (ytdl-raw-options),
+	// keyed by option name without dashes, e.g. {"cookies": "cookies.txt"}.
+	YtDlpOptions map[string]string
+
+	// HTTPProxy is an http:// proxy for mpv's own HTTP requests, e.g. for
+	// direct media URLs; empty for none.
+	HTTPProxy string
+
+	// BitPerfect opens the device exclusively and keeps mpv's own volume
+	// and ReplayGain processing out of the signal path.
+	BitPerfect bool
+
+	// PCMFile makes mpv write raw 48 kHz 16-bit stereo samples to a file
+	// or named pipe instead of a sound device, e.g. the pipe a snapserver
+	// source reads; empty plays as usual.
+	PCMFile string
+}
+
+// PCMOptions are the options, without dashes, that send the audio to
+// file as PCMFile describes.
+func PCMOptions(file string) [][2]string {
+	return [][2]string{
+		{"ao", "pcm"},
+		{"ao-pcm-file", file},
+		{"ao-pcm-waveheader", "no"},
+		{"audio-format", "s16"},
+		{"audio-samplerate", "48000"},
+		{"audio-channels", "stereo"},
+	}
+}
+
+// FileOptions are applied to a single file loaded with Load or Append.
+type FileOptions struct {
+	StartPos float64 // position in seconds to start playback from
+	CutEnd   float64 // stop playback this many seconds before the end
+
+	// YtdlFormat is the format the ytdl_hook asks yt-dlp for when the URL
+	// is a page, e.g. an audio-only selector; empty for mpv's default.
+	YtdlFormat string
+}
+
+// Instance is one running mpv and the IPC socket it listens on. Every
+// command goes to that socket, so several players (or a player started by
+// another process) never answer for each other.
+type Instance struct {
+	cmd    *exec.Cmd // nil for an mpv this package didn't spawn
+	socket string
+}
+
+// socketSeq numbers the sockets of the players started by this process.
+var socketSeq atomic.Int64
+
+// NewSocketPath returns an IPC socket path no other player uses: a unix
+// socket, or a named pipe on Windows.
+func NewSocketPath() string {
+	return ipcPath(fmt.Sprintf("mpv-socket-%d-%d", os.Getpid(), socketSeq.Add(1)))
+}
+
+// Attach returns an Instance for an mpv listening on socket, e.g. one
+// embedded through libmpv.
+func Attach(socket string) *Instance {
+	return &Instance{socket: socket}
+}
+
+// Socket returns the IPC socket path of m.
+func (m *Instance) Socket() string {
+	return m.socket
+}
+
+// Start spawns a persistent mpv that idles until files are loaded into it
+// with Load/Append. The same process plays every track, which avoids
+// re-opening the audio device between tracks and lets appended files play
+// gaplessly. Caller may Kill or Wait on it.
+func Start(opts Options) (*Instance, error) {
 	// Start mpv in audio-only mode by default for a terminal music player.
 	// Use --really-quiet to suppress all terminal output that would corrupt TUI.
 	// Use --no-terminal to prevent mpv from trying to read/write the terminal.
 	// Use --input-ipc-server for socket-based IPC control
-	socketPath := getTempSocketPath()
+	socketPath := NewSocketPath()
 	args := []string{
-		"--no-video",
+		"--idle=yes",
 		"--no-terminal",
 		"--really-quiet",
+		"--gapless-audio=weak",
+		"--prefetch-playlist=yes",
 		fmt.Sprintf("--input-ipc-server=%s", socketPath),
 	}
-	if device != "" {
-		args = append(args, "--audio-device="+device)
+	if opts.Video {
+		args = append(args, "--force-window=yes")
+	} else {
+		args = append(args, "--no-video")
 	}
-	// Append the target URL as the last argument
-	args = append(args, url)
+	if opts.Device != "" {
+		args = append(args, "--audio-device="+opts.Device)
+	}
+	if opts.AudioOutput != "" {
+		args = append(args, "--ao="+opts.AudioOutput)
+	}
+	if opts.YtDlpPath != "" {
+		args = append(args, "--script-opts-append=ytdl_hook-ytdl_path="+opts.YtDlpPath)
+	}
+	for _, kv := range RawOptions(opts.YtDlpOptions) {
+		args = append(args, "--ytdl-raw-options-append="+kv)
+	}
+	if opts.HTTPProxy != "" {
+		args = append(args, "--http-proxy="+opts.HTTPProxy)
+	}
+	if opts.BitPerfect {
+		args = append(args, "--audio-exclusive=yes", "--volume=100", "--replaygain=no")
+	}
+	if opts.PCMFile != "" {
+		for _, o := range PCMOptions(opts.PCMFile) {
+			args = append(args, "--"+o[0]+"="+o[1])
+		}
+	}
+
+	// A leftover socket from an earlier run would make WaitReady succeed early
+	_ = os.Remove(socketPath)
 
+	debuglog.Printf("mpv", "mpv %s", strings.Join(args, " "))
 	cmd := exec.Command("mpv", args...)
 	// Redirect stdout/stderr to null to prevent TUI corruption
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 	cmd.Stdin = nil
 	// ensure mpv does not remain in process group if we kill
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	proc.Detach(cmd)
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start mpv: %w", err)
 	}
-	return cmd, nil
+	return &Instance{cmd: cmd, socket: socketPath}, nil
+}
+
+// Wait waits for a spawned mpv to exit.
+func (m *Instance) Wait() error {
+	if m.cmd == nil {
+		return nil
+	}
+	return m.cmd.Wait()
+}
+
+// RawOptions returns options as sorted key=value pairs for
+// --ytdl-raw-options-append, which takes one pair at a time so values may
+// contain commas.
+func RawOptions(options map[string]string) []string {
+	var pairs []string
+	for k, v := range options {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// Load replaces whatever mpv is playing (and anything appended after it)
+// with url.
+func (m *Instance) Load(url string, opts FileOptions) error {
+	return m.loadFile(url, "replace", opts)
+}
+
+// Append adds url to mpv's playlist after the current file, so mpv moves on
+// to it gaplessly when the current file ends.
+func (m *Instance) Append(url string, opts FileOptions) error {
+	return m.loadFile(url, "append", opts)
+}
+
+func (m *Instance) loadFile(url, flags string, opts FileOptions) error {
+	fileOpts := map[string]string{}
+	if opts.StartPos > 0 {
+		fileOpts["start"] = fmt.Sprintf("%.1f", opts.StartPos)
+	}
+	if opts.CutEnd > 0 {
+		// Negative times are relative to the end of the file
+		fileOpts["end"] = fmt.Sprintf("-%.1f", opts.CutEnd)
+	}
+	if opts.YtdlFormat != "" {
+		fileOpts["ytdl-format"] = opts.YtdlFormat
+	}
+	// Named arguments keep this working across mpv versions, which disagree
+	// on the position of loadfile's options parameter.
+	return m.send(map[string]interface{}{
+		"command": map[string]interface{}{
+			"name":    "loadfile",
+			"url":     url,
+			"flags":   flags,
+			"options": fileOpts,
+		},
+	})
+}
+
+// Stop stops playback and clears mpv's playlist; mpv keeps running idle.
+func (m *Instance) Stop() error {
+	return m.SendCommand("stop")
+}
+
+// ClearAppended removes every playlist entry except the one playing.
+func (m *Instance) ClearAppended() error {
+	return m.SendCommand("playlist-clear")
+}
+
+// SetVideo shows or hides the video window for subsequently loaded files.
+func (m *Instance) SetVideo(on bool) error {
+	vid, window := "no", "no"
+	if on {
+		vid, window = "auto", "yes"
+	}
+	if err := m.SendCommand("set", "force-window", window); err != nil {
+		return err
+	}
+	return m.SendCommand("set", "vid", vid)
+}
+
+// Event is an asynchronous notification from mpv, e.g. start-file or
+// end-file (with Reason "eof", "stop", "quit", "error" or "redirect").
+type Event struct {
+	Event           string `json:"event"`
+	Reason          string `json:"reason"`
+	PlaylistEntryID int    `json:"playlist_entry_id"`
+	FileError       string `json:"file_error"`
+}
+
+// WaitReady waits up to timeout for mpv's IPC socket to accept connections.
+func (m *Instance) WaitReady(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := dialIPC(m.socket, 200*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("mpv ipc socket not ready: %w", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Events opens a dedicated IPC connection and streams mpv's events on the
+// returned channel, which is closed when mpv goes away.
+func (m *Instance) Events() (<-chan Event, error) {
+	conn, err := dialIPC(m.socket, 500*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan Event, 16)
+	go func() {
+		defer close(events)
+		defer conn.Close()
+		dec := json.NewDecoder(conn)
+		for {
+			var ev Event
+			if err := dec.Decode(&ev); err != nil {
+				return
+			}
+			if ev.Event != "" {
+				logEvent(ev)
+				events <- ev
+			}
+		}
+	}()
+	return events, nil
 }
 
-// KillCmd attempts to kill the mpv process (and its process group) started by Start
-func KillCmd(cmd *exec.Cmd) error {
+// Kill attempts to kill the mpv process (and its process group) started by
+// Start, and removes its socket
+func (m *Instance) Kill() error {
+	defer os.Remove(m.socket)
+	cmd := m.cmd
 	if cmd == nil || cmd.Process == nil {
 		return nil
 	}
 	// kill process group
-	pgid, err := syscall.Getpgid(cmd.Process.Pid)
-	if err == nil {
-		_ = syscall.Kill(-pgid, syscall.SIGTERM)
-	}
+	_ = proc.Terminate(cmd)
 	// fallback kill
 	return cmd.Process.Kill()
 }
@@ -69,42 +317,356 @@ func RunCapture(url string, title string, device string, resample bool) (string,
 	return string(out), err
 }
 
-// getTempSocketPath returns a unique socket path for mpv IPC
-func getTempSocketPath() string {
-	return filepath.Join(os.TempDir(), fmt.Sprintf("mpv-socket-%d", os.Getpid()))
+// SendCommand sends a command to mpv via IPC socket
+func (m *Instance) SendCommand(cmd string, args ...interface{}) error {
+	return m.send(map[string]interface{}{
+		"command": append([]interface{}{cmd}, args...),
+	})
 }
 
-// SendCommand sends a command to mpv via IPC socket
-func SendCommand(cmd string, args ...interface{}) error {
-	socketPath := getTempSocketPath()
-	conn, err := net.DialTimeout("unix", socketPath, 500*time.Millisecond)
+// send writes a single JSON command to mpv's IPC socket
+func (m *Instance) send(command interface{}) error {
+	socketPath := m.socket
+	conn, err := dialIPC(socketPath, 500*time.Millisecond)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	// Build JSON command
-	command := map[string]interface{}{
-		"command": append([]interface{}{cmd}, args...),
-	}
 	data, _ := json.Marshal(command)
+	debuglog.Printf("mpv", "→ %s", data)
 	data = append(data, '\n')
 
 	_, err = conn.Write(data)
 	return err
 }
 
+// logEvent logs an event from mpv for the debug console.
+func logEvent(ev Event) {
+	text := ev.Event
+	if ev.Reason != "" {
+		text += " (" + ev.Reason + ")"
+	}
+	if ev.FileError != "" {
+		text += ": " + ev.FileError
+	}
+	debuglog.Printf("mpv", "← %s", text)
+}
+
+// GetProperty reads a property from the running mpv via the IPC socket
+func (m *Instance) GetProperty(name string) (interface{}, error) {
+	socketPath := m.socket
+	conn, err := dialIPC(socketPath, 500*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(time.Second))
+
+	command := map[string]interface{}{
+		"command":    []interface{}{"get_property", name},
+		"request_id": 1,
+	}
+	data, _ := json.Marshal(command)
+	data = append(data, '\n')
+	if _, err := conn.Write(data); err != nil {
+		return nil, err
+	}
+
+	// mpv may interleave events with the reply; skip until the response arrives
+	dec := json.NewDecoder(conn)
+	for {
+		var resp struct {
+			Event     string      `json:"event"`
+			Error     string      `json:"error"`
+			Data      interface{} `json:"data"`
+			RequestID int         `json:"request_id"`
+		}
+		if err := dec.Decode(&resp); err != nil {
+			return nil, err
+		}
+		if resp.Event != "" || resp.RequestID != 1 {
+			continue
+		}
+		if resp.Error != "success" {
+			return nil, fmt.Errorf("mpv: get_property %s: %s", name, resp.Error)
+		}
+		return resp.Data, nil
+	}
+}
+
 // Seek seeks to a position relative to current time (in seconds)
-func Seek(seconds float64) error {
-	return SendCommand("seek", seconds, "relative")
+func (m *Instance) Seek(seconds float64) error {
+	return m.SendCommand("seek", seconds, "relative")
+}
+
+// SeekTo seeks to an absolute position (in seconds)
+func (m *Instance) SeekTo(seconds float64) error {
+	return m.SendCommand("seek", seconds, "absolute")
+}
+
+// SetABLoop loops playback between a and b (in seconds); a negative value
+// clears that loop point
+func (m *Instance) SetABLoop(a, b float64) error {
+	point := func(v float64) interface{} {
+		if v < 0 {
+			return "no"
+		}
+		return v
+	}
+	if err := m.SendCommand("set", "ab-loop-a", point(a)); err != nil {
+		return err
+	}
+	return m.SendCommand("set", "ab-loop-b", point(b))
 }
 
 // Pause toggles pause state
-func Pause() error {
-	return SendCommand("cycle", "pause")
+func (m *Instance) Pause() error {
+	return m.SendCommand("cycle", "pause")
 }
 
 // Play resumes playback
-func Play() error {
-	return SendCommand("set", "pause", false)
+func (m *Instance) Play() error {
+	return m.SendCommand("set", "pause", false)
+}
+
+// Mute toggles mute state
+func (m *Instance) Mute() error {
+	return m.SendCommand("cycle", "mute")
+}
+
+// SetMute mutes or unmutes playback
+func (m *Instance) SetMute(on bool) error {
+	return m.SendCommand("set", "mute", on)
+}
+
+// SetSpeed sets the playback speed (1 is normal)
+func (m *Instance) SetSpeed(speed float64) error {
+	return m.SendCommand("set", "speed", speed)
+}
+
+// SetPitchCorrection turns scaletempo2 pitch correction for speed changes
+// on or off
+func (m *Instance) SetPitchCorrection(on bool) error {
+	return m.SendCommand("set", "audio-pitch-correction", on)
+}
+
+// SetVolume sets mpv's volume (100 is unchanged)
+func (m *Instance) SetVolume(v float64) error {
+	return m.SendCommand("set", "volume", v)
+}
+
+// Device is an audio output mpv can play to.
+type Device struct {
+	Name        string `json:"name"`        // value for --audio-device
+	Description string `json:"description"` // human readable name
+}
+
+// deviceLine matches entries of mpv --audio-device=help, e.g.
+//
+//	'alsa/default:CARD=PCH' (HDA Intel PCH, ALC3246 Analog)
+var deviceLine = regexp.MustCompile(`^\s*'([^']+)'\s*\((.*)\)\s*$`)
+
+// ListDevices returns the audio outputs mpv can use, as reported by
+// mpv --audio-device=help.
+func ListDevices() ([]Device, error) {
+	out, err := exec.Command("mpv", "--no-config", "--audio-device=help").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audio devices: %w", err)
+	}
+	var devices []Device
+	for _, line := range strings.Split(string(out), "\n") {
+		if m := deviceLine.FindStringSubmatch(line); m != nil {
+			devices = append(devices, Device{Name: m[1], Description: m[2]})
+		}
+	}
+	return devices, nil
+}
+
+// HardwareDevice returns the first raw ALSA hw: device in devices. Those
+// bypass the dmix/plug layers that would otherwise resample and mix.
+func HardwareDevice(devices []Device) (Device, bool) {
+	for _, d := range devices {
+		if IsHardwareDevice(d.Name) {
+			return d, true
+		}
+	}
+	return Device{}, false
+}
+
+// IsHardwareDevice reports whether name is a raw ALSA hw: device.
+func IsHardwareDevice(name string) bool {
+	return strings.HasPrefix(name, "alsa/hw:")
+}
+
+// AudioFormat describes PCM audio as mpv reports it.
+type AudioFormat struct {
+	SampleRate int
+	Format     string // sample format, e.g. "s16" or "floatp"
+	Channels   int
+}
+
+func (f AudioFormat) String() string {
+	return fmt.Sprintf("%d Hz %s %dch", f.SampleRate, f.Format, f.Channels)
+}
+
+// AudioFormats returns the format of the decoded source and the format
+// actually sent to the audio device.
+func (m *Instance) AudioFormats() (AudioFormat, AudioFormat, error) {
+	in, err := m.audioFormat("audio-params")
+	if err != nil {
+		return AudioFormat{}, AudioFormat{}, err
+	}
+	out, err := m.audioFormat("audio-out-params")
+	if err != nil {
+		return AudioFormat{}, AudioFormat{}, err
+	}
+	return in, out, nil
+}
+
+func (m *Instance) audioFormat(property string) (AudioFormat, error) {
+	v, err := m.GetProperty(property)
+	if err != nil {
+		return AudioFormat{}, err
+	}
+	params, ok := v.(map[string]interface{})
+	if !ok {
+		return AudioFormat{}, fmt.Errorf("mpv: unexpected %s value", property)
+	}
+	var f AudioFormat
+	if rate, ok := params["samplerate"].(float64); ok {
+		f.SampleRate = int(rate)
+	}
+	if format, ok := params["format"].(string); ok {
+		f.Format = format
+	}
+	if channels, ok := params["channel-count"].(float64); ok {
+		f.Channels = int(channels)
+	}
+	return f, nil
+}
+
+// Metadata describes the playing file as mpv decodes it. Fields mpv
+// doesn't know (yet) are left zero.
+type Metadata struct {
+	Codec      string // e.g. "opus"
+	Bitrate    int    // bits per second, averaged by mpv
+	SampleRate int
+	Channels   int
+	// StreamTitle is the live-stream title sent as ICY metadata by
+	// internet radio, which changes with each song; Station is the name of
+	// the station.
+	StreamTitle string
+	Station     string
+}
+
+// Metadata reads the codec, bitrate, sample rate and ICY metadata of the
+// playing file.
+func (m *Instance) Metadata() (Metadata, error) {
+	var md Metadata
+	v, err := m.GetProperty("audio-codec-name")
+	if err != nil {
+		// Nothing is decoded yet
+		return md, err
+	}
+	md.Codec, _ = v.(string)
+	if v, err := m.GetProperty("audio-bitrate"); err == nil {
+		if rate, ok := v.(float64); ok {
+			md.Bitrate = int(rate)
+		}
+	}
+	if f, err := m.audioFormat("audio-params"); err == nil {
+		md.SampleRate, md.Channels = f.SampleRate, f.Channels
+	}
+	if v, err := m.GetProperty("metadata"); err == nil {
+		tags, _ := v.(map[string]interface{})
+		for key, value := range tags {
+			// Servers differ in the case of the keys
+			text, _ := value.(string)
+			switch strings.ToLower(key) {
+			case "icy-title":
+				md.StreamTitle = strings.TrimSpace(text)
+			case "icy-name":
+				md.Station = strings.TrimSpace(text)
+			}
+		}
+	}
+	return md, nil
+}
+
+// Chapter is a chapter of the playing file, starting Time seconds in.
+type Chapter struct {
+	Time  float64
+	Title string
+}
+
+// Chapters returns the chapters of the playing file: those embedded in it,
+// or found by mpv's ytdl hook for page URLs.
+func (m *Instance) Chapters() ([]Chapter, error) {
+	v, err := m.GetProperty("chapter-list")
+	if err != nil {
+		return nil, err
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mpv: unexpected chapter-list value")
+	}
+	chapters := make([]Chapter, 0, len(list))
+	for _, item := range list {
+		c, _ := item.(map[string]interface{})
+		t, _ := c["time"].(float64)
+		title, _ := c["title"].(string)
+		chapters = append(chapters, Chapter{Time: t, Title: strings.TrimSpace(title)})
+	}
+	return chapters, nil
+}
+
+// SetDevice switches the running mpv to another audio output.
+func (m *Instance) SetDevice(name string) error {
+	if name == "" {
+		name = "auto"
+	}
+	return m.SendCommand("set", "audio-device", name)
+}
+
+// Normalize sets up loudness normalization: mode "loudnorm" levels playback
+// to target LUFS with ffmpeg's loudnorm filter, "replaygain" applies the
+// files' track ReplayGain tags and "" turns normalization off.
+func (m *Instance) Normalize(mode string, target float64) error {
+	// Removing a filter that isn't there only makes mpv log an error
+	if err := m.SendCommand("af", "remove", "@norm"); err != nil {
+		return err
+	}
+	replaygain := "no"
+	switch mode {
+	case "replaygain":
+		replaygain = "track"
+	case "loudnorm":
+		filter := fmt.Sprintf("@norm:lavfi=[loudnorm=I=%.1f:TP=-1.5:LRA=11]", target)
+		if err := m.SendCommand("af", "add", filter); err != nil {
+			return err
+		}
+	}
+	return m.SendCommand("set", "replaygain", replaygain)
+}
+
+// SetEQ replaces the equalizer filter; an empty filter removes it.
+func (m *Instance) SetEQ(filter string) error {
+	if err := m.SendCommand("af", "remove", "@eq"); err != nil {
+		return err
+	}
+	if filter == "" {
+		return nil
+	}
+	return m.SendCommand("af", "add", "@eq:"+filter)
+}
+
+// Duck lowers playback volume to the given factor (0-1) using a labelled
+// volume filter, leaving the user's volume setting untouched. Calling it with
+// a factor >= 1 removes the filter again.
+func (m *Instance) Duck(factor float64) error {
+	if factor >= 1 {
+		return m.SendCommand("af", "remove", "@duck")
+	}
+	return m.SendCommand("af", "add", fmt.Sprintf("@duck:volume=volume=%.2f", factor))
 }
@@ -0,0 +1,45 @@
+// Package netproxy builds the HTTP clients of the lookups made in process
+// (Spotify, lyrics, SponsorBlock, cover art) so they connect through the
+// proxy configured, like yt-dlp and the player do.
+package netproxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// schemes are the proxy kinds Go's HTTP client supports.
+var schemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// Parse checks a proxy URL such as "http://host:3128" or
+// "socks5://localhost:1080". It returns nil for "", no proxy.
+func Parse(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", raw, err)
+	}
+	if !schemes[u.Scheme] || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: want http://, https:// or socks5:// and a host", raw)
+	}
+	return u, nil
+}
+
+// Client returns an HTTP client giving up after timeout that connects
+// through proxy, or with "" through the proxy of the environment
+// (HTTPS_PROXY and the like), if any.
+func Client(proxy string, timeout time.Duration) (*http.Client, error) {
+	u, err := Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if u != nil {
+		transport.Proxy = http.ProxyURL(u)
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
@@ -0,0 +1,258 @@
+package playlist
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"audictl/internal/provider"
+)
+
+// Entry is a single item read from an M3U, PLS or XSPF playlist file.
+// Title and Artist are only set when the file carries that metadata.
+type Entry struct {
+	Location string
+	Title    string
+	Artist   string
+	Duration int
+}
+
+// IsPlaylistFile reports whether path has a playlist file extension we can
+// import.
+func IsPlaylistFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".m3u", ".m3u8", ".pls", ".xspf":
+		return true
+	}
+	return false
+}
+
+// ReadFile parses an M3U/M3U8, PLS or XSPF playlist. Relative locations and
+// file:// URIs are turned into absolute local paths.
+func ReadFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".m3u", ".m3u8":
+		entries, err = parseM3U(f)
+	case ".pls":
+		entries, err = parsePLS(f)
+	case ".xspf":
+		entries, err = parseXSPF(f)
+	default:
+		return nil, fmt.Errorf("unsupported playlist format: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Dir(path)
+	for i := range entries {
+		entries[i].Location = resolveLocation(base, entries[i].Location)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entries found in %s", filepath.Base(path))
+	}
+	return entries, nil
+}
+
+func resolveLocation(base, loc string) string {
+	if strings.HasPrefix(loc, "file://") {
+		if u, err := url.Parse(loc); err == nil {
+			return u.Path
+		}
+	}
+	if strings.Contains(loc, "://") || filepath.IsAbs(loc) {
+		return loc
+	}
+	return filepath.Join(base, loc)
+}
+
+// splitArtistTitle splits "Artist - Title" display strings.
+func splitArtistTitle(s string) (artist, title string) {
+	if i := strings.Index(s, " - "); i > 0 {
+		return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+3:])
+	}
+	return "", strings.TrimSpace(s)
+}
+
+func parseM3U(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	var pending Entry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#EXTINF:"):
+			// #EXTINF:<seconds>[ attributes],<display title>
+			info := strings.TrimPrefix(line, "#EXTINF:")
+			display := ""
+			if i := strings.Index(info, ","); i >= 0 {
+				display = info[i+1:]
+				info = info[:i]
+			}
+			if fields := strings.Fields(info); len(fields) > 0 {
+				pending.Duration, _ = strconv.Atoi(fields[0])
+			}
+			pending.Artist, pending.Title = splitArtistTitle(display)
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			pending.Location = line
+			if pending.Duration < 0 {
+				pending.Duration = 0
+			}
+			entries = append(entries, pending)
+			pending = Entry{}
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func parsePLS(r io.Reader) ([]Entry, error) {
+	byIndex := map[int]*Entry{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			continue
+		}
+		key, value := strings.ToLower(line[:eq]), strings.TrimSpace(line[eq+1:])
+		var field string
+		for _, prefix := range []string{"file", "title", "length"} {
+			if strings.HasPrefix(key, prefix) {
+				field = prefix
+				key = strings.TrimPrefix(key, prefix)
+				break
+			}
+		}
+		n, err := strconv.Atoi(key)
+		if field == "" || err != nil {
+			continue
+		}
+		e := byIndex[n]
+		if e == nil {
+			e = &Entry{}
+			byIndex[n] = e
+		}
+		switch field {
+		case "file":
+			e.Location = value
+		case "title":
+			e.Artist, e.Title = splitArtistTitle(value)
+		case "length":
+			if d, err := strconv.Atoi(value); err == nil && d > 0 {
+				e.Duration = d
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	indexes := make([]int, 0, len(byIndex))
+	for n := range byIndex {
+		indexes = append(indexes, n)
+	}
+	sort.Ints(indexes)
+	var entries []Entry
+	for _, n := range indexes {
+		if byIndex[n].Location != "" {
+			entries = append(entries, *byIndex[n])
+		}
+	}
+	return entries, nil
+}
+
+type xspfPlaylist struct {
+	Tracks []struct {
+		Location []string `xml:"location"`
+		Title    string   `xml:"title"`
+		Creator  string   `xml:"creator"`
+		Duration int      `xml:"duration"` // milliseconds
+	} `xml:"trackList>track"`
+}
+
+func parseXSPF(r io.Reader) ([]Entry, error) {
+	var doc xspfPlaylist
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse xspf: %w", err)
+	}
+	var entries []Entry
+	for _, t := range doc.Tracks {
+		if len(t.Location) == 0 {
+			continue
+		}
+		entries = append(entries, Entry{
+			Location: strings.TrimSpace(t.Location[0]),
+			Title:    strings.TrimSpace(t.Title),
+			Artist:   strings.TrimSpace(t.Creator),
+			Duration: t.Duration / 1000,
+		})
+	}
+	return entries, nil
+}
+
+// trackLocation picks the link written to exported playlists.
+func trackLocation(t provider.Track) string {
+	for _, key := range []string{"youtube", "url", "spotify"} {
+		if l := t.Links[key]; l != "" {
+			return l
+		}
+	}
+	for _, l := range t.Links {
+		return l
+	}
+	return ""
+}
+
+// WriteM3U writes tracks as an extended M3U playlist with EXTINF metadata.
+// Tracks without any link are skipped.
+func WriteM3U(w io.Writer, tracks []provider.Track) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#EXTM3U")
+	for _, t := range tracks {
+		loc := trackLocation(t)
+		if loc == "" {
+			continue
+		}
+		display := t.Title
+		if t.Artist != "" {
+			display = t.Artist + " - " + t.Title
+		}
+		duration := t.Duration
+		if duration <= 0 {
+			duration = -1
+		}
+		fmt.Fprintf(bw, "#EXTINF:%d,%s\n%s\n", duration, display, loc)
+	}
+	return bw.Flush()
+}
+
+// ExportM3U writes tracks to the M3U file at path.
+func ExportM3U(path string, tracks []provider.Track) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteM3U(f, tracks); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,172 @@
+// Package playlist stores named playlists as JSON files under the data
+// directory (one file per playlist).
+package playlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"audictl/internal/provider"
+	"audictl/internal/store"
+)
+
+type Playlist struct {
+	Name    string           `json:"name"`
+	Tracks  []provider.Track `json:"tracks"`
+	Updated time.Time        `json:"updated"`
+}
+
+// ErrNotFound is returned when a playlist with the given name does not exist.
+var ErrNotFound = errors.New("playlist not found")
+
+// Dir returns the directory playlists are stored in, creating it if needed.
+func Dir() (string, error) {
+	base, err := store.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "playlists")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("cannot create playlist dir: %w", err)
+	}
+	return dir, nil
+}
+
+// fileName maps a playlist name to a safe file name.
+func fileName(name string) string {
+	clean := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	return clean + ".json"
+}
+
+func path(name string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("playlist name is empty")
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName(name)), nil
+}
+
+// List returns all saved playlists sorted by name. Tracks are included so
+// callers can show counts without loading each playlist again.
+func List() ([]Playlist, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var lists []Playlist
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		pl, err := readFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		lists = append(lists, *pl)
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return strings.ToLower(lists[i].Name) < strings.ToLower(lists[j].Name)
+	})
+	return lists, nil
+}
+
+func readFile(p string) (*Playlist, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var pl Playlist
+	if err := json.Unmarshal(data, &pl); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(p), err)
+	}
+	if pl.Name == "" {
+		pl.Name = strings.TrimSuffix(filepath.Base(p), ".json")
+	}
+	return &pl, nil
+}
+
+// Load reads the named playlist.
+func Load(name string) (*Playlist, error) {
+	p, err := path(name)
+	if err != nil {
+		return nil, err
+	}
+	pl, err := readFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return pl, err
+}
+
+// Save writes the playlist, replacing any playlist with the same name.
+func Save(pl *Playlist) error {
+	if _, err := path(pl.Name); err != nil {
+		return err
+	}
+	pl.Updated = time.Now()
+	return store.Save(filepath.Join("playlists", fileName(pl.Name)), pl)
+}
+
+// Create saves a new playlist with the given tracks.
+func Create(name string, tracks []provider.Track) (*Playlist, error) {
+	pl := &Playlist{Name: strings.TrimSpace(name), Tracks: append([]provider.Track(nil), tracks...)}
+	return pl, Save(pl)
+}
+
+// Delete removes the named playlist.
+func Delete(name string) error {
+	p, err := path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Add appends tracks to the named playlist, creating it if it doesn't exist.
+func Add(name string, tracks ...provider.Track) error {
+	pl, err := Load(name)
+	if errors.Is(err, ErrNotFound) {
+		pl = &Playlist{Name: strings.TrimSpace(name)}
+	} else if err != nil {
+		return err
+	}
+	pl.Tracks = append(pl.Tracks, tracks...)
+	return Save(pl)
+}
+
+// Remove deletes the track at idx from the named playlist.
+func Remove(name string, idx int) error {
+	pl, err := Load(name)
+	if err != nil {
+		return err
+	}
+	if idx < 0 || idx >= len(pl.Tracks) {
+		return fmt.Errorf("index %d out of range", idx)
+	}
+	pl.Tracks = append(pl.Tracks[:idx], pl.Tracks[idx+1:]...)
+	return Save(pl)
+}
@@ -0,0 +1,10 @@
+// Package proc hides how the helper processes the player runs, such as mpv
+// and ffplay, are put in a group of their own, paused and killed, which
+// differs between Unix and Windows.
+package proc
+
+import "errors"
+
+// ErrUnsupported is returned for what the platform can't do, such as
+// pausing a process on Windows.
+var ErrUnsupported = errors.New("not supported on this platform")
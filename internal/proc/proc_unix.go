@@ -0,0 +1,58 @@
+//go:build !windows
+
+package proc
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// CanSuspend is whether the program can stop itself as Ctrl-Z does.
+const CanSuspend = true
+
+// Detach makes cmd, once started, the leader of a process group of its
+// own: Ctrl-C and Ctrl-Z in the terminal don't reach it, and Kill takes
+// its children along.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// Terminate asks the process group of cmd to exit.
+func Terminate(cmd *exec.Cmd) error {
+	return signal(cmd, syscall.SIGTERM)
+}
+
+// Kill kills the process group of cmd. Unlike Terminate it also ends a
+// paused process.
+func Kill(cmd *exec.Cmd) error {
+	return signal(cmd, syscall.SIGKILL)
+}
+
+// Pause stops the process group of cmd until Continue.
+func Pause(cmd *exec.Cmd) error {
+	return signal(cmd, syscall.SIGSTOP)
+}
+
+// Continue resumes the process group of cmd after Pause.
+func Continue(cmd *exec.Cmd) error {
+	return signal(cmd, syscall.SIGCONT)
+}
+
+// SuspendSelf stops this program as Ctrl-Z would, returning once the shell
+// resumes it.
+func SuspendSelf() error {
+	return syscall.Kill(syscall.Getpid(), syscall.SIGTSTP)
+}
+
+// signal sends sig to the process group of the started cmd.
+func signal(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	pgid, err := syscall.Getpgid(cmd.Process.Pid)
+	if err != nil {
+		// Gone already, or never got a group: signal the process alone
+		return cmd.Process.Signal(sig)
+	}
+	return syscall.Kill(-pgid, sig)
+}
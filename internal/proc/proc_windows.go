@@ -0,0 +1,46 @@
+//go:build windows
+
+package proc
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// CanSuspend is whether the program can stop itself as Ctrl-Z does.
+const CanSuspend = false
+
+// Detach starts cmd in a process group of its own, so Ctrl-C in the
+// console doesn't reach it.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// Terminate ends cmd. Windows has no signal asking a process to exit, so
+// it is killed.
+func Terminate(cmd *exec.Cmd) error {
+	return Kill(cmd)
+}
+
+// Kill kills cmd.
+func Kill(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
+
+// Pause is not supported on Windows.
+func Pause(cmd *exec.Cmd) error {
+	return ErrUnsupported
+}
+
+// Continue is not supported on Windows.
+func Continue(cmd *exec.Cmd) error {
+	return ErrUnsupported
+}
+
+// SuspendSelf is not supported on Windows.
+func SuspendSelf() error {
+	return ErrUnsupported
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+type searchKey struct {
+	provider string
+	kind     SearchKind
+	query    string
+	limit    int
+}
+
+type cacheEntry struct {
+	key     searchKey
+	tracks  []Track
+	fetched time.Time
+}
+
+type streamKey struct {
+	trackID string
+	quality QualityPref
+}
+
+type streamEntry struct {
+	stream   Stream
+	resolved time.Time
+}
+
+// CachedProvider wraps a Provider and memoizes Search results in an LRU
+// keyed by (provider, kind, query), so repeated searches return instantly.
+// Resolved streams are kept per track until they expire, so replaying a
+// track or jumping back to the previous one skips resolving it again.
+// All other methods are passed through unchanged.
+type CachedProvider struct {
+	Provider
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[searchKey]*list.Element
+	lru     *list.List
+	streams map[streamKey]streamEntry
+}
+
+// NewCached wraps p with a search cache holding up to size queries, each kept
+// for at most ttl (0 keeps entries until they are evicted).
+func NewCached(p Provider, size int, ttl time.Duration) *CachedProvider {
+	if size <= 0 {
+		size = 100
+	}
+	return &CachedProvider{
+		Provider: p,
+		size:     size,
+		ttl:      ttl,
+		entries:  map[searchKey]*list.Element{},
+		lru:      list.New(),
+		streams:  map[streamKey]streamEntry{},
+	}
+}
+
+func (c *CachedProvider) Search(query string, kind SearchKind, limit int) ([]Track, error) {
+	key := searchKey{
+		provider: c.Name(),
+		kind:     kind,
+		query:    strings.Join(strings.Fields(strings.ToLower(query)), " "),
+		limit:    limit,
+	}
+
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		if c.ttl <= 0 || time.Since(entry.fetched) < c.ttl {
+			c.lru.MoveToFront(el)
+			tracks := append([]Track(nil), entry.tracks...)
+			c.mu.Unlock()
+			return tracks, nil
+		}
+		c.lru.Remove(el)
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+
+	tracks, err := c.Provider.Search(query, kind, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.lru.Remove(el)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{
+		key:     key,
+		tracks:  append([]Track(nil), tracks...),
+		fetched: time.Now(),
+	})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	return tracks, nil
+}
+
+func (c *CachedProvider) ResolveStream(track Track, q QualityPref) (Stream, error) {
+	key := streamKey{trackID: track.ID, quality: q}
+
+	c.mu.Lock()
+	if e, ok := c.streams[key]; ok {
+		if e.stream.Usable(e.resolved) {
+			c.mu.Unlock()
+			return e.stream, nil
+		}
+		delete(c.streams, key)
+	}
+	c.mu.Unlock()
+
+	stream, err := c.Provider.ResolveStream(track, q)
+	if err != nil {
+		return Stream{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streams[key] = streamEntry{stream: stream, resolved: time.Now()}
+	if len(c.streams) > c.size {
+		c.pruneStreams()
+	}
+	return stream, nil
+}
+
+// pruneStreams drops expired streams, then the oldest ones until the cache
+// fits its size again. Must be called with c.mu held.
+func (c *CachedProvider) pruneStreams() {
+	for k, e := range c.streams {
+		if !e.stream.Usable(e.resolved) {
+			delete(c.streams, k)
+		}
+	}
+	for len(c.streams) > c.size {
+		var oldest streamKey
+		var oldestAt time.Time
+		for k, e := range c.streams {
+			if oldestAt.IsZero() || e.resolved.Before(oldestAt) {
+				oldest, oldestAt = k, e.resolved
+			}
+		}
+		delete(c.streams, oldest)
+	}
+}
+
+// ForgetStream drops the cached streams of the track with id, e.g. after
+// playing one failed.
+func (c *CachedProvider) ForgetStream(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.streams {
+		if k.trackID == id {
+			delete(c.streams, k)
+		}
+	}
+}
+
+// Clear drops every cached search result.
+func (c *CachedProvider) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[searchKey]*list.Element{}
+	c.lru.Init()
+}
+
+// unwrap returns the provider p caches, or p itself, so callers can look for
+// the optional interfaces a provider implements.
+func unwrap(p Provider) Provider {
+	if c, ok := p.(*CachedProvider); ok {
+		return c.Provider
+	}
+	return p
+}
@@ -0,0 +1,42 @@
+package provider
+
+import "errors"
+
+// Errors providers wrap their failures in, so callers can tell the user
+// what went wrong and what to do about it. Test with errors.Is.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrGeoBlocked      = errors.New("not available in this country")
+	ErrAgeRestricted   = errors.New("age-restricted")
+	ErrExtractorFailed = errors.New("extraction failed")
+	// ErrNetwork means the service couldn't be reached at all, as when the
+	// connection dropped; trying again later may succeed.
+	ErrNetwork = errors.New("network unreachable")
+	// ErrUnsupported is returned by providers for operations they don't
+	// offer, e.g. Search on one that only plays given locations.
+	ErrUnsupported = errors.New("not supported")
+)
+
+// Explain describes err for the user, with advice for the errors above,
+// e.g. "video is age-restricted — configure cookies". Other errors are
+// returned as they are.
+func Explain(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrAgeRestricted):
+		return "video is age-restricted — configure cookies for a signed-in account"
+	case errors.Is(err, ErrGeoBlocked):
+		return "video is not available in your country — try a proxy"
+	case errors.Is(err, ErrRateLimited):
+		return "YouTube is rate limiting requests — wait a few minutes or configure cookies"
+	case errors.Is(err, ErrNotFound):
+		return "video is unavailable — it may be private, removed or the link is wrong"
+	case errors.Is(err, ErrExtractorFailed):
+		return "yt-dlp could not read the page — try updating it (yt-dlp -U)"
+	case errors.Is(err, ErrNetwork):
+		return "could not connect — check your network connection"
+	}
+	return err.Error()
+}
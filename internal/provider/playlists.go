@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Playlist is a playlist kept by a provider, such as one of the signed-in
+// user's YouTube playlists.
+type Playlist struct {
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+	Name     string `json:"name"`
+	Count    int    `json:"count"` // number of tracks, 0 when unknown
+}
+
+// PlaylistSource is implemented by providers that can list the user's own
+// playlists.
+type PlaylistSource interface {
+	Playlists() ([]Playlist, error)
+	PlaylistTracks(id string) ([]Track, error)
+}
+
+// PlaylistResult is what listing the playlists of one provider returned.
+type PlaylistResult struct {
+	Provider  string
+	Playlists []Playlist
+	Err       error
+}
+
+// playlistSource returns p, or the provider it caches, as a PlaylistSource.
+func playlistSource(p Provider) (PlaylistSource, bool) {
+	src, ok := unwrap(p).(PlaylistSource)
+	return src, ok
+}
+
+// Playlists lists the playlists of every provider that keeps any,
+// concurrently, in registration order.
+func (r *Registry) Playlists() []PlaylistResult {
+	r.mu.RLock()
+	var names []string
+	var sources []PlaylistSource
+	for _, name := range r.order {
+		if src, ok := playlistSource(r.providers[name]); ok {
+			names = append(names, name)
+			sources = append(sources, src)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]PlaylistResult, len(sources))
+	var wg sync.WaitGroup
+	for i, src := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lists, err := src.Playlists()
+			results[i] = PlaylistResult{Provider: names[i], Playlists: lists, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// PlaylistTracks returns the tracks of pl from the provider that keeps it.
+func (r *Registry) PlaylistTracks(pl Playlist) ([]Track, error) {
+	p, ok := r.Get(pl.Provider)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", pl.Provider)
+	}
+	src, ok := playlistSource(p)
+	if !ok {
+		return nil, fmt.Errorf("%s playlists: %w", pl.Provider, ErrUnsupported)
+	}
+	return src.PlaylistTracks(pl.ID)
+}
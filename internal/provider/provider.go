@@ -1,6 +1,9 @@
 package provider
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Track struct {
 	ID       string            `json:"id"`
@@ -26,6 +29,31 @@ type Stream struct {
 	Lossless   bool              `json:"lossless"`
 	ExpiresAt  time.Time         `json:"expires_at"`
 	Meta       map[string]string `json:"meta"`
+	// Chapters are the chapters of long videos and audiobooks, in order,
+	// when the provider knows them.
+	Chapters []Chapter `json:"chapters,omitempty"`
+}
+
+// Chapter is a titled part of a track starting Start seconds in.
+type Chapter struct {
+	Start float64 `json:"start"`
+	Title string  `json:"title"`
+}
+
+const (
+	// streamMaxAge bounds how long a stream without ExpiresAt is reused.
+	streamMaxAge = 30 * time.Minute
+	// streamSlack keeps a stream from expiring while mpv is still connecting.
+	streamSlack = 30 * time.Second
+)
+
+// Usable reports whether a stream resolved at resolvedAt can still be
+// played, honouring ExpiresAt when the provider reported one.
+func (s Stream) Usable(resolvedAt time.Time) bool {
+	if !s.ExpiresAt.IsZero() {
+		return time.Now().Add(streamSlack).Before(s.ExpiresAt)
+	}
+	return time.Since(resolvedAt) < streamMaxAge
 }
 
 type SearchKind int
@@ -34,15 +62,39 @@ const (
 	SearchKindTrack SearchKind = iota
 	SearchKindAlbum
 	SearchKindPlaylist
+	// SearchKindSong searches a music catalogue, for songs with their
+	// artist and album, where the provider has one; others search tracks.
+	SearchKindSong
 )
 
 type QualityPref int
 
 const (
+	// QualityAny takes the provider's best stream within the limits it
+	// was configured with.
 	QualityAny QualityPref = iota
+	// QualityLosslessFirst prefers lossless streams, or else the highest
+	// bitrate whatever the codec.
 	QualityLosslessFirst
+	// QualityLow takes the smallest stream still worth listening to, to
+	// save data.
+	QualityLow
 )
 
+// ParseQuality returns the preference named "best", "lossless" or "low";
+// "" is "best".
+func ParseQuality(name string) (QualityPref, error) {
+	switch name {
+	case "", "best":
+		return QualityAny, nil
+	case "lossless":
+		return QualityLosslessFirst, nil
+	case "low":
+		return QualityLow, nil
+	}
+	return QualityAny, fmt.Errorf("unknown quality %q (want best, lossless or low)", name)
+}
+
 type Provider interface {
 	Name() string
 	Search(query string, kind SearchKind, limit int) ([]Track, error)
@@ -0,0 +1,160 @@
+// Package providertest provides a scripted provider.Provider for tests of
+// the code built on providers: searches, track lookups and stream
+// resolution answer with what the test set up, after a set latency, and
+// every call is recorded.
+package providertest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"audictl/internal/provider"
+)
+
+// Provider is a provider.Provider answering from scripted results. Tracks
+// without a scripted stream resolve to "mock://" followed by their ID.
+type Provider struct {
+	name string
+
+	mu      sync.Mutex
+	latency time.Duration
+	results map[string][]provider.Track // by query
+	tracks  map[string]provider.Track   // by ID
+	streams map[string]provider.Stream  // by track ID
+	errs    map[string]error            // by query or track ID
+	calls   []string
+}
+
+// New returns an empty provider named name.
+func New(name string) *Provider {
+	return &Provider{
+		name:    name,
+		results: map[string][]provider.Track{},
+		tracks:  map[string]provider.Track{},
+		streams: map[string]provider.Stream{},
+		errs:    map[string]error{},
+	}
+}
+
+// Track returns a track of the provider with the given ID, title and
+// duration in seconds, and makes GetTrack find it.
+func (p *Provider) Track(id, title string, duration int) provider.Track {
+	t := provider.Track{
+		ID:       p.name + ":" + id,
+		Provider: p.name,
+		Title:    title,
+		Artist:   "Artist " + id,
+		Duration: duration,
+	}
+	p.mu.Lock()
+	p.tracks[t.ID] = t
+	p.mu.Unlock()
+	return t
+}
+
+// SetLatency makes every call take d before it answers.
+func (p *Provider) SetLatency(d time.Duration) {
+	p.mu.Lock()
+	p.latency = d
+	p.mu.Unlock()
+}
+
+// SetResults makes searches for query return tracks.
+func (p *Provider) SetResults(query string, tracks ...provider.Track) {
+	p.mu.Lock()
+	p.results[query] = tracks
+	p.mu.Unlock()
+}
+
+// SetStream makes the track with id resolve to s.
+func (p *Provider) SetStream(id string, s provider.Stream) {
+	p.mu.Lock()
+	p.streams[id] = s
+	p.mu.Unlock()
+}
+
+// Fail makes searches for key, or looking up or resolving the track with
+// ID key, return err until Fail is called again with a nil err.
+func (p *Provider) Fail(key string, err error) {
+	p.mu.Lock()
+	if err == nil {
+		delete(p.errs, key)
+	} else {
+		p.errs[key] = err
+	}
+	p.mu.Unlock()
+}
+
+// Calls returns the calls made so far, in order, such as "search lofi" or
+// "resolve mock:1".
+func (p *Provider) Calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.calls...)
+}
+
+// Count returns how many of the calls so far start with prefix, e.g.
+// "resolve " or "resolve mock:1".
+func (p *Provider) Count(prefix string) int {
+	n := 0
+	for _, c := range p.Calls() {
+		if strings.HasPrefix(c, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// call records a call and waits out the latency. It returns the error
+// scripted for key, if any.
+func (p *Provider) call(what, key string) error {
+	p.mu.Lock()
+	p.calls = append(p.calls, what+" "+key)
+	latency := p.latency
+	err := p.errs[key]
+	p.mu.Unlock()
+	time.Sleep(latency)
+	return err
+}
+
+func (p *Provider) Name() string { return p.name }
+
+func (p *Provider) Search(query string, kind provider.SearchKind, limit int) ([]provider.Track, error) {
+	if err := p.call("search", query); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tracks := p.results[query]
+	if limit > 0 && len(tracks) > limit {
+		tracks = tracks[:limit]
+	}
+	return append([]provider.Track(nil), tracks...), nil
+}
+
+func (p *Provider) GetTrack(id string) (provider.Track, error) {
+	if err := p.call("get", id); err != nil {
+		return provider.Track{}, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t, ok := p.tracks[id]
+	if !ok {
+		return provider.Track{}, fmt.Errorf("%s: %w", id, provider.ErrNotFound)
+	}
+	return t, nil
+}
+
+func (p *Provider) ResolveStream(track provider.Track, qualityPreference provider.QualityPref) (provider.Stream, error) {
+	if err := p.call("resolve", track.ID); err != nil {
+		return provider.Stream{}, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s, ok := p.streams[track.ID]; ok {
+		return s, nil
+	}
+	return provider.Stream{URL: "mock://" + track.ID}, nil
+}
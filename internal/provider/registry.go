@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Registry looks up providers by name so tracks can be resolved by the
+// provider that produced them.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+	order     []string
+}
+
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: map[string]Provider{}}
+	for _, p := range providers {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register adds p, replacing any provider registered under the same name.
+func (r *Registry) Register(p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.providers[p.Name()]; !ok {
+		r.order = append(r.order, p.Name())
+	}
+	r.providers[p.Name()] = p
+}
+
+// Get returns the provider registered under name.
+func (r *Registry) Get(name string) (Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names returns the registered provider names in registration order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.order...)
+}
+
+// ResolveStream resolves track through the provider named in track.Provider.
+func (r *Registry) ResolveStream(track Track, qualityPreference QualityPref) (Stream, error) {
+	p, ok := r.Get(track.Provider)
+	if !ok {
+		return Stream{}, fmt.Errorf("unknown provider %q", track.Provider)
+	}
+	return p.ResolveStream(track, qualityPreference)
+}
+
+// ForgetStream drops any stream of track cached by its provider, so the
+// next ResolveStream resolves it afresh.
+func (r *Registry) ForgetStream(track Track) {
+	p, _ := r.Get(track.Provider)
+	if c, ok := p.(*CachedProvider); ok {
+		c.ForgetStream(track.ID)
+	}
+}
+
+// ClearCache drops the search results cached by every provider, so the
+// next searches ask the providers again.
+func (r *Registry) ClearCache() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.providers {
+		if c, ok := p.(*CachedProvider); ok {
+			c.Clear()
+		}
+	}
+}
+
+// SearchResult is what searching one provider returned.
+type SearchResult struct {
+	Provider string
+	Tracks   []Track
+	Err      error
+}
+
+// SearchAll searches every provider concurrently and returns their
+// results in registration order. Providers that don't support searching
+// are left out.
+func (r *Registry) SearchAll(query string, kind SearchKind, limit int) []SearchResult {
+	r.mu.RLock()
+	providers := make([]Provider, len(r.order))
+	for i, name := range r.order {
+		providers[i] = r.providers[name]
+	}
+	r.mu.RUnlock()
+
+	results := make([]SearchResult, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracks, err := p.Search(query, kind, limit)
+			results[i] = SearchResult{Provider: p.Name(), Tracks: tracks, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	out := results[:0]
+	for _, res := range results {
+		if !errors.Is(res.Err, ErrUnsupported) {
+			out = append(out, res)
+		}
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package provider
+
+import "fmt"
+
+// RelatedSource is implemented by providers that can suggest tracks like a
+// given one, such as the tracks of a YouTube mix.
+type RelatedSource interface {
+	Related(track Track, limit int) ([]Track, error)
+}
+
+// Related returns up to limit tracks like track, from its own provider if
+// that suggests any, otherwise from the first provider that does. Tracks
+// from providers without suggestions, such as local files, are looked up
+// by title there.
+func (r *Registry) Related(track Track, limit int) ([]Track, error) {
+	r.mu.RLock()
+	var src RelatedSource
+	if s, ok := unwrap(r.providers[track.Provider]).(RelatedSource); ok {
+		src = s
+	} else {
+		for _, name := range r.order {
+			if s, ok := unwrap(r.providers[name]).(RelatedSource); ok {
+				src = s
+				break
+			}
+		}
+	}
+	r.mu.RUnlock()
+	if src == nil {
+		return nil, fmt.Errorf("related tracks: %w", ErrUnsupported)
+	}
+	return src.Related(track, limit)
+}
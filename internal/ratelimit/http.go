@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"audictl/internal/netproxy"
+)
+
+// hostLimits are the request rates (per second) used for known services.
+// MusicBrainz asks clients to stay at or below one request per second.
+var hostLimits = map[string]float64{
+	"open.spotify.com":       5,
+	"api.spotify.com":        5,
+	"musicbrainz.org":        1,
+	"lrclib.net":             5,
+	"api.radio-browser.info": 5,
+}
+
+const (
+	defaultRate = 10
+	maxRetries  = 3
+)
+
+// Client performs HTTP requests with a per-host rate limit and retries 429
+// (and 503) responses, honouring Retry-After.
+type Client struct {
+	HTTP     *http.Client
+	mu       sync.Mutex
+	limiters map[string]*Limiter
+}
+
+// timeout is how long the requests of clients made here may take.
+const timeout = 15 * time.Second
+
+// DefaultClient is shared by all providers so limits apply process-wide.
+var DefaultClient = &Client{HTTP: &http.Client{Timeout: timeout}}
+
+// NewClient returns a client connecting through proxy, see netproxy.Client.
+// Its limits are its own: use it for hosts DefaultClient doesn't reach.
+func NewClient(proxy string) (*Client, error) {
+	hc, err := netproxy.Client(proxy, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{HTTP: hc}, nil
+}
+
+// SetProxy makes DefaultClient connect through proxy. Call it at startup,
+// before any request.
+func SetProxy(proxy string) error {
+	hc, err := netproxy.Client(proxy, timeout)
+	if err != nil {
+		return err
+	}
+	DefaultClient.HTTP = hc
+	return nil
+}
+
+// Get issues a GET request through DefaultClient.
+func Get(rawURL string) (*http.Response, error) {
+	return DefaultClient.Get(rawURL)
+}
+
+func (c *Client) limiter(host string) *Limiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.limiters == nil {
+		c.limiters = map[string]*Limiter{}
+	}
+	if l, ok := c.limiters[host]; ok {
+		return l
+	}
+	rate := float64(defaultRate)
+	for h, r := range hostLimits {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			rate = r
+			break
+		}
+	}
+	l := New(rate, int(rate))
+	c.limiters[host] = l
+	return l
+}
+
+func (c *Client) Get(rawURL string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do sends req, waiting for the host's rate limit first. Requests with a
+// body are not retried.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	host := req.URL.Hostname()
+	lim := c.limiter(host)
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	for attempt := 0; ; attempt++ {
+		lim.Wait()
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+			return resp, nil
+		}
+		if attempt >= maxRetries || req.Body != nil {
+			return resp, nil
+		}
+
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"))
+		if !ok {
+			wait = Backoff(attempt, time.Second, 30*time.Second)
+		}
+		resp.Body.Close()
+		if wait > 2*time.Minute {
+			return nil, fmt.Errorf("%s rate limited for %s", host, wait.Round(time.Second))
+		}
+		// Hold back every caller of this host, not just this request
+		lim.Pause(wait)
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
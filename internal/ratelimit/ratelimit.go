@@ -0,0 +1,116 @@
+// Package ratelimit throttles calls to remote services so bulk operations
+// (large imports, playlist expansion) don't get the user banned.
+package ratelimit
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket: it allows rate events per second on average
+// with bursts of up to burst events.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	until  time.Time // no events before this time (set by Pause)
+}
+
+// providerLimits are the call rates (per second) of providers whose calls
+// don't go through a Client, such as the yt-dlp runs of YouTube's.
+var providerLimits = map[string]float64{
+	"youtube": 2,
+}
+
+var (
+	providersMu sync.Mutex
+	providers   = map[string]*Limiter{}
+)
+
+// ForProvider returns the limiter of the provider with name. It is shared
+// by the whole process, so every instance of the provider, whoever made it,
+// stays within the one rate.
+func ForProvider(name string) *Limiter {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if l, ok := providers[name]; ok {
+		return l
+	}
+	rate, ok := providerLimits[name]
+	if !ok {
+		rate = defaultRate
+	}
+	l := New(rate, int(rate))
+	providers[name] = l
+	return l
+}
+
+func New(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+
+	var wait time.Duration
+	if l.tokens < 0 && l.rate > 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	if pause := l.until.Sub(now); pause > wait {
+		wait = pause
+	}
+	return wait
+}
+
+// Wait blocks until the next event is allowed.
+func (l *Limiter) Wait() {
+	if d := l.reserve(); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// Pause blocks all events for d, e.g. after the server answered with a
+// Retry-After header.
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.until) {
+		l.until = until
+	}
+}
+
+// Backoff returns the delay before retry number attempt (starting at 0):
+// exponential from base, capped at max, with up to 50% random jitter so
+// concurrent clients don't retry in lockstep.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 0; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
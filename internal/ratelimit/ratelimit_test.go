@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterBurstThenRate(t *testing.T) {
+	l := New(10, 3)
+	for i := range 3 {
+		if d := l.reserve(); d != 0 {
+			t.Fatalf("event %d of the burst waits %v", i+1, d)
+		}
+	}
+	// The bucket is empty: the next token comes 1/rate later, the one
+	// after that twice as late
+	if d := l.reserve(); d < 90*time.Millisecond || d > 100*time.Millisecond {
+		t.Errorf("first event past the burst waits %v, want 100ms", d)
+	}
+	if d := l.reserve(); d < 190*time.Millisecond || d > 200*time.Millisecond {
+		t.Errorf("second event past the burst waits %v, want 200ms", d)
+	}
+}
+
+func TestLimiterRefills(t *testing.T) {
+	l := New(100, 1)
+	l.reserve()
+	time.Sleep(20 * time.Millisecond)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("event after the bucket refilled waits %v", d)
+	}
+}
+
+func TestLimiterPause(t *testing.T) {
+	l := New(10, 5)
+	l.Pause(time.Second)
+	l.Pause(10 * time.Millisecond) // a shorter pause doesn't cut it short
+	if d := l.reserve(); d < 900*time.Millisecond || d > time.Second {
+		t.Errorf("event while paused waits %v, want about 1s", d)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for _, tc := range []struct {
+		attempt int
+		want    time.Duration // before jitter
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{5, 30 * time.Second}, // capped
+		{100, 30 * time.Second},
+	} {
+		for range 20 {
+			d := Backoff(tc.attempt, time.Second, 30*time.Second)
+			if d < tc.want/2 || d > tc.want {
+				t.Errorf("Backoff(%d) = %v, want between %v and %v", tc.attempt, d, tc.want/2, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestForProviderIsShared(t *testing.T) {
+	if ForProvider("youtube") != ForProvider("youtube") {
+		t.Error("two limiters for youtube")
+	}
+	if ForProvider("youtube") == ForProvider("other") {
+		t.Error("providers share a limiter")
+	}
+	if l := ForProvider("youtube"); l.rate != providerLimits["youtube"] {
+		t.Errorf("youtube rate = %v, want %v", l.rate, providerLimits["youtube"])
+	}
+	if l := ForProvider("other"); l.rate != defaultRate {
+		t.Errorf("rate of an unlisted provider = %v, want %v", l.rate, defaultRate)
+	}
+}
@@ -0,0 +1,166 @@
+// Package recommend suggests what to listen to next from the playback
+// history: the artists played most, lately and at this time of day, and
+// tracks of theirs that haven't been played lately.
+package recommend
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"audictl/internal/history"
+	"audictl/internal/provider"
+)
+
+// halfLife is how long until a play counts half as much as one today.
+const halfLife = 30 * 24 * time.Hour
+
+// sameTimeBoost is how much more a play counts when it happened at the
+// same time of day as now.
+const sameTimeBoost = 2.0
+
+// ErrNoHistory is returned when there is nothing to base suggestions on.
+var ErrNoHistory = errors.New("nothing played yet to base suggestions on")
+
+// Artist is an artist worth suggesting.
+type Artist struct {
+	Name   string
+	Score  float64
+	Plays  int
+	Reason string // why it was picked, e.g. "often played in the evening"
+}
+
+// TimeOfDay names the part of the day t falls in: "morning", "afternoon",
+// "evening" or "night".
+func TimeOfDay(t time.Time) string {
+	switch h := t.Hour(); {
+	case h >= 5 && h < 12:
+		return "morning"
+	case h >= 12 && h < 17:
+		return "afternoon"
+	case h >= 17 && h < 22:
+		return "evening"
+	default:
+		return "night"
+	}
+}
+
+// Artists ranks the artists in entries, best first, and returns up to limit
+// of them (all when limit <= 0). Each play counts by how much of the track
+// was listened to, how recent it is and whether it was at the same time of
+// day as now.
+func Artists(entries []history.Entry, now time.Time, limit int) []Artist {
+	type tally struct {
+		name    string
+		score   float64
+		plays   int
+		nowPart int // plays at the same time of day as now
+	}
+	part := TimeOfDay(now)
+	byKey := map[string]*tally{}
+	for _, e := range entries {
+		name := strings.TrimSpace(e.Track.Artist)
+		if name == "" {
+			continue
+		}
+		key := strings.ToLower(name)
+		t, ok := byKey[key]
+		if !ok {
+			t = &tally{name: name}
+			byKey[key] = t
+		}
+		// Skipped tracks count for little, unknown completion as listened
+		weight := 1.0
+		if e.Track.Duration > 0 {
+			weight = 0.2 + 0.8*e.Completion/100
+		}
+		age := max(now.Sub(e.PlayedAt), 0)
+		weight *= math.Pow(0.5, float64(age)/float64(halfLife))
+		if TimeOfDay(e.PlayedAt.In(now.Location())) == part {
+			weight *= sameTimeBoost
+			t.nowPart++
+		}
+		t.score += weight
+		t.plays++
+	}
+
+	artists := make([]Artist, 0, len(byKey))
+	for _, t := range byKey {
+		reason := fmt.Sprintf("played %d times", t.plays)
+		if t.plays == 1 {
+			reason = "played once"
+		}
+		if t.plays >= 3 && t.nowPart*2 > t.plays {
+			reason = "often played in the " + part
+			if part == "night" {
+				reason = "often played at night"
+			}
+		}
+		artists = append(artists, Artist{Name: t.name, Score: t.score, Plays: t.plays, Reason: reason})
+	}
+	sort.Slice(artists, func(i, j int) bool {
+		if artists[i].Score != artists[j].Score {
+			return artists[i].Score > artists[j].Score
+		}
+		return artists[i].Name < artists[j].Name
+	})
+	if limit > 0 && len(artists) > limit {
+		artists = artists[:limit]
+	}
+	return artists
+}
+
+// PlayedSince returns the IDs of the tracks in entries played after since.
+func PlayedSince(entries []history.Entry, since time.Time) map[string]bool {
+	played := map[string]bool{}
+	for _, e := range entries {
+		if e.PlayedAt.After(since) {
+			played[e.Track.ID] = true
+		}
+	}
+	return played
+}
+
+// Queue searches each artist's tracks with search and takes up to
+// perArtist of them that are not in skip, taking turns between the
+// artists so the queue mixes them. It fails only when every search does.
+func Queue(search func(query string) ([]provider.Track, error), artists []Artist, skip map[string]bool, perArtist int) ([]provider.Track, error) {
+	picks := make([][]provider.Track, len(artists))
+	seen := map[string]bool{}
+	var lastErr error
+	failed := 0
+	for i, a := range artists {
+		tracks, err := search(a.Name)
+		if err != nil {
+			lastErr = err
+			failed++
+			continue
+		}
+		for _, t := range tracks {
+			if len(picks[i]) == perArtist {
+				break
+			}
+			if skip[t.ID] || seen[t.ID] {
+				continue
+			}
+			seen[t.ID] = true
+			picks[i] = append(picks[i], t)
+		}
+	}
+	if len(artists) > 0 && failed == len(artists) {
+		return nil, lastErr
+	}
+
+	var queue []provider.Track
+	for round := 0; round < perArtist; round++ {
+		for _, p := range picks {
+			if round < len(p) {
+				queue = append(queue, p[round])
+			}
+		}
+	}
+	return queue, nil
+}
@@ -0,0 +1,135 @@
+// Package session stores named snapshots of the player state (queue,
+// position, volume, modes and output device) as JSON files under the data
+// directory, so listening contexts like "podcasts" and "focus music" can be
+// switched between.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"audictl/internal/provider"
+	"audictl/internal/store"
+)
+
+type Session struct {
+	Name          string           `json:"name"`
+	Queue         []provider.Track `json:"queue"`
+	QueueIdx      int              `json:"queue_idx"`
+	Playing       bool             `json:"playing"`  // a track was playing when saved
+	Position      float64          `json:"position"` // seconds into the current track
+	Volume        float64          `json:"volume"`   // mpv volume, 0 if unknown
+	Muted         bool             `json:"muted"`
+	Video         bool             `json:"video"`
+	Normalize     bool             `json:"normalize"`
+	ShowRemaining bool             `json:"show_remaining"`
+	Device        string           `json:"device"`
+	Saved         time.Time        `json:"saved"`
+}
+
+// ErrNotFound is returned when a session with the given name does not exist.
+var ErrNotFound = errors.New("session not found")
+
+// Dir returns the directory sessions are stored in, creating it if needed.
+func Dir() (string, error) {
+	base, err := store.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "sessions")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("cannot create session dir: %w", err)
+	}
+	return dir, nil
+}
+
+// fileName maps a session name to a safe file name.
+func fileName(name string) string {
+	clean := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	return clean + ".json"
+}
+
+// List returns all saved sessions, most recently saved first.
+func List() ([]Session, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var sessions []Session
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var s Session
+		if err := json.Unmarshal(data, &s); err != nil {
+			continue
+		}
+		if s.Name == "" {
+			s.Name = strings.TrimSuffix(e.Name(), ".json")
+		}
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Saved.After(sessions[j].Saved)
+	})
+	return sessions, nil
+}
+
+// Load reads the named session.
+func Load(name string) (*Session, error) {
+	var s Session
+	if err := store.Load(filepath.Join("sessions", fileName(name)), &s); err != nil {
+		return nil, err
+	}
+	if s.Name == "" {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+// Save writes the session, replacing any session with the same name.
+func Save(s *Session) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return fmt.Errorf("session name is empty")
+	}
+	if _, err := Dir(); err != nil {
+		return err
+	}
+	s.Saved = time.Now()
+	return store.Save(filepath.Join("sessions", fileName(s.Name)), s)
+}
+
+// Delete removes the named session.
+func Delete(name string) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, fileName(name))); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package snapcast talks to a snapserver over its JSON-RPC control
+// interface (TCP, one JSON object per line) to list the clients playing a
+// stream and set their volume.
+package snapcast
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+)
+
+// DefaultServer is the snapserver's JSON-RPC address when none is
+// configured.
+const DefaultServer = "localhost:1705"
+
+// timeout bounds each request, connecting included.
+const timeout = 3 * time.Second
+
+// Client is a device playing from the snapserver.
+type Client struct {
+	ID        string
+	Name      string // configured name, else the host name
+	Connected bool
+	Muted     bool
+	Volume    int // percent
+	Group     string
+}
+
+// Server is a snapserver's control interface.
+type Server struct {
+	addr string
+}
+
+// New returns the control interface at addr (host:port), DefaultServer if
+// empty.
+func New(addr string) *Server {
+	if addr == "" {
+		addr = DefaultServer
+	}
+	return &Server{addr: addr}
+}
+
+// call sends one request and decodes its result into result.
+func (s *Server) call(method string, params, result interface{}) error {
+	conn, err := net.DialTimeout("tcp", s.addr, timeout)
+	if err != nil {
+		return fmt.Errorf("snapserver: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	req := map[string]interface{}{"id": 1, "jsonrpc": "2.0", "method": method}
+	if params != nil {
+		req["params"] = params
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return fmt.Errorf("snapserver: %w", err)
+	}
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return fmt.Errorf("snapserver: %w", err)
+		}
+		var resp struct {
+			ID     *int            `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(line, &resp) != nil || resp.ID == nil {
+			// A notification about something else
+			continue
+		}
+		if resp.Error != nil {
+			return errors.New("snapserver: " + resp.Error.Message)
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	}
+}
+
+// Clients lists the clients the snapserver knows, by name.
+func (s *Server) Clients() ([]Client, error) {
+	var status struct {
+		Server struct {
+			Groups []struct {
+				ID      string `json:"id"`
+				Name    string `json:"name"`
+				Clients []struct {
+					ID        string `json:"id"`
+					Connected bool   `json:"connected"`
+					Host      struct {
+						Name string `json:"name"`
+					} `json:"host"`
+					Config struct {
+						Name   string `json:"name"`
+						Volume struct {
+							Muted   bool `json:"muted"`
+							Percent int  `json:"percent"`
+						} `json:"volume"`
+					} `json:"config"`
+				} `json:"clients"`
+			} `json:"groups"`
+		} `json:"server"`
+	}
+	if err := s.call("Server.GetStatus", nil, &status); err != nil {
+		return nil, err
+	}
+	var clients []Client
+	for _, g := range status.Server.Groups {
+		for _, c := range g.Clients {
+			name := c.Config.Name
+			if name == "" {
+				name = c.Host.Name
+			}
+			clients = append(clients, Client{
+				ID:        c.ID,
+				Name:      name,
+				Connected: c.Connected,
+				Muted:     c.Config.Volume.Muted,
+				Volume:    c.Config.Volume.Percent,
+				Group:     g.Name,
+			})
+		}
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].Name < clients[j].Name })
+	return clients, nil
+}
+
+// SetVolume sets the volume of the client with id, in percent (0-100),
+// and whether it is muted.
+func (s *Server) SetVolume(id string, percent int, muted bool) error {
+	percent = max(0, min(100, percent))
+	params := map[string]interface{}{
+		"id":     id,
+		"volume": map[string]interface{}{"percent": percent, "muted": muted},
+	}
+	return s.call("Client.SetVolume", params, nil)
+}
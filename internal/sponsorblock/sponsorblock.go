@@ -0,0 +1,95 @@
+// Package sponsorblock looks up the crowd-sourced non-music segments of
+// YouTube videos (sponsor reads, intros, self-promotion, ...) from the
+// SponsorBlock API so playback can skip them.
+package sponsorblock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"audictl/internal/ratelimit"
+)
+
+// API is the SponsorBlock server queried by Segments.
+const API = "https://sponsor.ajay.app/api/skipSegments"
+
+// Categories are the segment categories SponsorBlock knows.
+var Categories = []string{
+	"sponsor", "selfpromo", "interaction", "intro", "outro",
+	"preview", "music_offtopic", "filler",
+}
+
+// DefaultCategories are skipped when none are configured: the ones that
+// are never part of the music.
+var DefaultCategories = []string{"sponsor", "selfpromo", "interaction", "music_offtopic"}
+
+// Segment is a part of a video to skip, in seconds.
+type Segment struct {
+	Start    float64
+	End      float64
+	Category string
+}
+
+// Segments returns the segments of the YouTube video with id in the given
+// categories, sorted by start. A video nobody submitted segments for has
+// none.
+func Segments(id string, categories []string) ([]Segment, error) {
+	if len(categories) == 0 {
+		categories = DefaultCategories
+	}
+	cats, _ := json.Marshal(categories)
+	q := url.Values{"videoID": {id}, "categories": {string(cats)}}
+	resp, err := ratelimit.Get(API + "?" + q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sponsorblock: %s", resp.Status)
+	}
+
+	var body []struct {
+		Segment    [2]float64 `json:"segment"`
+		Category   string     `json:"category"`
+		ActionType string     `json:"actionType"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("sponsorblock: %w", err)
+	}
+	var segments []Segment
+	for _, s := range body {
+		// "mute" and "poi" (highlight) segments aren't meant to be skipped
+		if s.ActionType != "" && s.ActionType != "skip" {
+			continue
+		}
+		if s.Segment[1] <= s.Segment[0] {
+			continue
+		}
+		segments = append(segments, Segment{Start: s.Segment[0], End: s.Segment[1], Category: s.Category})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].Start < segments[j].Start })
+	return segments, nil
+}
+
+// Label names a category for display, e.g. "self-promotion".
+func Label(category string) string {
+	switch category {
+	case "selfpromo":
+		return "self-promotion"
+	case "interaction":
+		return "interaction reminder"
+	case "music_offtopic":
+		return "non-music section"
+	case "preview":
+		return "preview"
+	case "filler":
+		return "filler"
+	}
+	return category
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"strings"
+	"sync"
+
+	"audictl/internal/provider"
+)
+
+const pinsFile = "pins.json"
+
+// Pins maps normalized search queries to tracks the user pinned for them.
+// Pinned tracks are always ranked first when the same query is searched again.
+type Pins struct {
+	mu      sync.Mutex
+	Queries map[string][]provider.Track `json:"queries"`
+}
+
+// LoadPins reads the pin list from the data directory.
+func LoadPins() (*Pins, error) {
+	p := &Pins{Queries: map[string][]provider.Track{}}
+	if err := Load(pinsFile, p); err != nil {
+		return p, err
+	}
+	if p.Queries == nil {
+		p.Queries = map[string][]provider.Track{}
+	}
+	return p, nil
+}
+
+// normalizeQuery lowercases the query and collapses whitespace so that
+// "Never  Gonna" and "never gonna" share the same pins.
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// IsPinned reports whether the track with the given ID is pinned for query.
+func (p *Pins) IsPinned(query, id string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, t := range p.Queries[normalizeQuery(query)] {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Toggle pins the track for query, or unpins it if it already was pinned.
+// It returns true when the track ends up pinned and persists the change.
+func (p *Pins) Toggle(query string, track provider.Track) (bool, error) {
+	key := normalizeQuery(query)
+	if key == "" {
+		return false, nil
+	}
+
+	// Held through the save, so saves land on disk in the order of the
+	// changes they write
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pinned := true
+	list := p.Queries[key]
+	for i, t := range list {
+		if t.ID == track.ID {
+			list = append(list[:i:i], list[i+1:]...)
+			pinned = false
+			break
+		}
+	}
+	if pinned {
+		list = append(list, track)
+	}
+	if len(list) == 0 {
+		delete(p.Queries, key)
+	} else {
+		p.Queries[key] = list
+	}
+	return pinned, Save(pinsFile, p)
+}
+
+// Rank returns results with the tracks pinned for query moved to the front,
+// in the order they were pinned. Pinned tracks missing from results are
+// prepended so they show up even when the provider stops returning them.
+func (p *Pins) Rank(query string, results []provider.Track) []provider.Track {
+	p.mu.Lock()
+	pins := append([]provider.Track(nil), p.Queries[normalizeQuery(query)]...)
+	p.mu.Unlock()
+	if len(pins) == 0 {
+		return results
+	}
+
+	pinnedIDs := make(map[string]bool, len(pins))
+	ranked := make([]provider.Track, 0, len(pins)+len(results))
+	for _, t := range pins {
+		pinnedIDs[t.ID] = true
+		ranked = append(ranked, t)
+	}
+	for _, t := range results {
+		if !pinnedIDs[t.ID] {
+			ranked = append(ranked, t)
+		}
+	}
+	return ranked
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const positionsFile = "positions.json"
+
+// maxPositions caps the remembered positions; the oldest are dropped.
+const maxPositions = 200
+
+// Position is where playback of a track was left.
+type Position struct {
+	Seconds float64   `json:"seconds"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// Positions remembers where long tracks (mixes, podcasts, audiobooks) were
+// stopped or skipped, keyed by track ID, so they can resume there.
+type Positions struct {
+	mu     sync.Mutex
+	Tracks map[string]Position `json:"tracks"`
+}
+
+// LoadPositions reads the saved positions from the data directory.
+func LoadPositions() (*Positions, error) {
+	p := &Positions{Tracks: map[string]Position{}}
+	if err := Load(positionsFile, p); err != nil {
+		return p, err
+	}
+	if p.Tracks == nil {
+		p.Tracks = map[string]Position{}
+	}
+	return p, nil
+}
+
+// Get returns the position saved for the track with the given ID.
+func (p *Positions) Get(id string) (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pos, ok := p.Tracks[id]
+	return pos.Seconds, ok
+}
+
+// Set saves secs as the position of the track with the given ID, or forgets
+// it when secs is 0, and persists the change.
+func (p *Positions) Set(id string, secs float64) error {
+	// Held through the save, see Pins.Toggle
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, had := p.Tracks[id]
+	if secs <= 0 {
+		delete(p.Tracks, id)
+	} else {
+		p.Tracks[id] = Position{Seconds: secs, SavedAt: time.Now()}
+	}
+	if len(p.Tracks) > maxPositions {
+		ids := make([]string, 0, len(p.Tracks))
+		for id := range p.Tracks {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			return p.Tracks[ids[i]].SavedAt.Before(p.Tracks[ids[j]].SavedAt)
+		})
+		for _, id := range ids[:len(ids)-maxPositions] {
+			delete(p.Tracks, id)
+		}
+	}
+	if secs <= 0 && !had {
+		return nil
+	}
+	return Save(positionsFile, p)
+}
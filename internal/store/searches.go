@@ -0,0 +1,75 @@
+package store
+
+import (
+	"strings"
+	"sync"
+
+	"audictl/internal/fuzzy"
+)
+
+const searchesFile = "searches.json"
+
+// maxSearches caps the remembered search queries; the oldest are dropped.
+const maxSearches = 500
+
+// Searches is the search query history, oldest first. A query searched
+// again moves to the end instead of appearing twice.
+type Searches struct {
+	mu      sync.Mutex
+	Queries []string `json:"queries"`
+}
+
+// LoadSearches reads the search history from the data directory.
+func LoadSearches() (*Searches, error) {
+	s := &Searches{}
+	err := Load(searchesFile, s)
+	return s, err
+}
+
+// Add records query as the most recent search and persists the history.
+func (s *Searches) Add(query string) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	// Held through the save, see Pins.Toggle
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := normalizeQuery(query)
+	kept := s.Queries[:0]
+	for _, q := range s.Queries {
+		if normalizeQuery(q) != key {
+			kept = append(kept, q)
+		}
+	}
+	s.Queries = append(kept, query)
+	if len(s.Queries) > maxSearches {
+		s.Queries = s.Queries[len(s.Queries)-maxSearches:]
+	}
+	return Save(searchesFile, s)
+}
+
+// Recent returns the queries newest first.
+func (s *Searches) Recent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, len(s.Queries))
+	for i, q := range s.Queries {
+		out[len(out)-1-i] = q
+	}
+	return out
+}
+
+// Match returns the queries containing the characters of pattern in
+// order (a fuzzy match, ignoring case and spaces), best first: queries
+// containing pattern as is, then those where the characters are closest
+// together, newer before older.
+func (s *Searches) Match(pattern string) []string {
+	recent := s.Recent()
+	ranked := fuzzy.Rank(recent, pattern)
+	out := make([]string, len(ranked))
+	for i, idx := range ranked {
+		out[i] = recent[idx]
+	}
+	return out
+}
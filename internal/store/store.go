@@ -0,0 +1,76 @@
+// Package store persists small pieces of player state (pins, playlists,
+// history, ...) as JSON files under the user's data directory.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the directory used for persisted state, creating it if needed.
+// It honours $XDG_DATA_HOME and falls back to ~/.local/share/audictl.
+func Dir() (string, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot locate data dir: %w", err)
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	dir := filepath.Join(base, "audictl")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("cannot create data dir: %w", err)
+	}
+	return dir, nil
+}
+
+// Path returns the full path of a named file inside the data directory.
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Load decodes the named JSON file into v. A missing file is not an error;
+// v is simply left untouched.
+func Load(name string, v interface{}) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// Save encodes v as JSON into the named file. The write goes through a
+// temporary file so a crash never leaves a half-written file behind.
+func Save(name string, v interface{}) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,131 @@
+// Package testmode lets the player run end to end without speakers or
+// network: searches return short generated tones stored as local WAV files,
+// which play through the direct provider while mpv outputs to --ao=null.
+package testmode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"audictl/internal/provider"
+	"audictl/providers/direct"
+)
+
+// Enabled reports whether AUDICTL_TEST_MODE is set.
+func Enabled() bool {
+	v := os.Getenv("AUDICTL_TEST_MODE")
+	return v != "" && v != "0"
+}
+
+// AudioOutput is the mpv audio driver used in test mode.
+const AudioOutput = "null"
+
+const (
+	sampleRate = 44100
+	toneLength = 3 // seconds
+)
+
+// notes are the tones generated, one track each.
+var notes = []struct {
+	name string
+	freq float64
+}{
+	{"A4", 440},
+	{"C5", 523.25},
+	{"E5", 659.25},
+	{"G5", 783.99},
+	{"A5", 880},
+}
+
+// Provider answers every search with the generated tones. Its tracks are
+// direct tracks, so a registry containing the direct provider plays them.
+type Provider struct {
+	tracks []provider.Track
+}
+
+// New writes the tones to dir (created if needed) and returns a provider
+// serving them.
+func New(dir string) (*Provider, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	p := &Provider{}
+	for _, n := range notes {
+		path := filepath.Join(dir, "tone-"+n.name+".wav")
+		if err := writeTone(path, n.freq, toneLength); err != nil {
+			return nil, fmt.Errorf("testmode: %w", err)
+		}
+		p.tracks = append(p.tracks, direct.NewTrack(path, "Test tone "+n.name, "testmode", toneLength))
+	}
+	return p, nil
+}
+
+func (p *Provider) Name() string { return "testmode" }
+
+// Search returns the tones whose title contains query, or all of them when
+// none match.
+func (p *Provider) Search(query string, kind provider.SearchKind, limit int) ([]provider.Track, error) {
+	var out []provider.Track
+	for _, t := range p.tracks {
+		if strings.Contains(strings.ToLower(t.Title), strings.ToLower(strings.TrimSpace(query))) {
+			out = append(out, t)
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, p.tracks...)
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (p *Provider) GetTrack(id string) (provider.Track, error) {
+	for _, t := range p.tracks {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return provider.Track{}, fmt.Errorf("testmode: unknown track %s", id)
+}
+
+func (p *Provider) ResolveStream(track provider.Track, q provider.QualityPref) (provider.Stream, error) {
+	return direct.New().ResolveStream(track, q)
+}
+
+// writeTone writes a mono 16-bit PCM WAV file with a sine wave of freq Hz.
+func writeTone(path string, freq float64, seconds int) error {
+	n := sampleRate * seconds
+	samples := make([]int16, n)
+	for i := range samples {
+		// Fade in/out over 10ms to avoid clicks between tracks
+		env := math.Min(1, math.Min(float64(i), float64(n-i))/(sampleRate/100))
+		samples[i] = int16(0.3 * env * math.MaxInt16 * math.Sin(2*math.Pi*freq*float64(i)/sampleRate))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dataSize := uint32(n * 2)
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'}, 36 + dataSize, [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, uint32(16), uint16(1), uint16(1),
+		uint32(sampleRate), uint32(sampleRate * 2), uint16(2), uint16(16),
+		[4]byte{'d', 'a', 't', 'a'}, dataSize,
+	}
+	for _, v := range header {
+		if err := binary.Write(f, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(f, binary.LittleEndian, samples); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,85 @@
+package theme
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// queryTimeout bounds the wait for the terminal to report its background.
+const queryTimeout = 200 * time.Millisecond
+
+// Light reports whether the terminal has a light background, from
+// $COLORFGBG or else by asking the terminal (OSC 11). Terminals that can't
+// tell are assumed dark.
+func Light() bool {
+	if v := os.Getenv("COLORFGBG"); v != "" {
+		// "fg;bg" or "fg;default;bg" with ANSI color numbers
+		fields := strings.Split(v, ";")
+		if bg, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+			return bg == 7 || bg == 15
+		}
+	}
+	r, g, b, ok := queryBackground()
+	if !ok {
+		return false
+	}
+	return 0.299*r+0.587*g+0.114*b > 0.5
+}
+
+// oscReply matches the answer to an OSC 11 query, e.g.
+// "\x1b]11;rgb:ffff/ffff/ffff\x1b\\".
+var oscReply = regexp.MustCompile(`\]11;rgba?:([0-9a-fA-F]+)/([0-9a-fA-F]+)/([0-9a-fA-F]+)`)
+
+// queryBackground asks the terminal for its background color and returns
+// it with components from 0 to 1. The query is followed by a device
+// attributes request, which every terminal answers, so reading stops
+// without waiting for the timeout on terminals that ignore OSC 11.
+func queryBackground() (r, g, b float64, ok bool) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	defer tty.Close()
+	// Not tty.Fd(), which would switch the file to blocking mode and
+	// disable the read deadline
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	var fd int
+	_ = conn.Control(func(f uintptr) { fd = int(f) })
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	defer term.Restore(fd, state)
+
+	if _, err := tty.WriteString("\x1b]11;?\x1b\\\x1b[c"); err != nil {
+		return 0, 0, 0, false
+	}
+	_ = tty.SetReadDeadline(time.Now().Add(queryTimeout))
+	var reply []byte
+	buf := make([]byte, 64)
+	for {
+		n, err := tty.Read(buf)
+		reply = append(reply, buf[:n]...)
+		// The device attributes answer ends in "c"
+		if err != nil || strings.Contains(string(reply), "[?") && strings.HasSuffix(string(reply), "c") {
+			break
+		}
+	}
+	m := oscReply.FindStringSubmatch(string(reply))
+	if m == nil {
+		return 0, 0, 0, false
+	}
+	component := func(hex string) float64 {
+		v, _ := strconv.ParseUint(hex, 16, 64)
+		return float64(v) / float64(uint64(1)<<(4*len(hex))-1)
+	}
+	return component(m[1]), component(m[2]), component(m[3]), true
+}
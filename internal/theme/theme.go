@@ -0,0 +1,225 @@
+// Package theme defines the color themes of the TUI: built-in named
+// palettes, palettes from the config file, and picking a light or dark one
+// to match the terminal.
+package theme
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Palette is a theme as written in the config file. Colors are names
+// ("darkcyan"), #rrggbb or "default" for the terminal's own color; unset
+// colors come from Base, a built-in or other user theme ("dark" when
+// empty).
+type Palette struct {
+	Base string `json:"base"`
+
+	Background    string `json:"background"`
+	Text          string `json:"text"`
+	Border        string `json:"border"`
+	Title         string `json:"title"`
+	Field         string `json:"field"`          // input field background
+	Selection     string `json:"selection"`      // selected list row background
+	SelectionText string `json:"selection_text"` // selected list row text
+
+	Accent   string `json:"accent"`   // playing, success, key names
+	Warning  string `json:"warning"`  // progress messages, highlights
+	Error    string `json:"error"`    // failures
+	Muted    string `json:"muted"`    // secondary details
+	Emphasis string `json:"emphasis"` // titles within text
+	Bar      string `json:"bar"`      // progress bar and sliders
+}
+
+// Builtin are the themes available by name. "dark" is the classic look.
+var Builtin = map[string]Palette{
+	"dark": {
+		Background: "black", Text: "white", Border: "white", Title: "white",
+		Field: "darkslategray", Selection: "darkcyan", SelectionText: "black",
+		Accent: "green", Warning: "yellow", Error: "red", Muted: "gray", Emphasis: "white", Bar: "aqua",
+	},
+	"light": {
+		Background: "default", Text: "#1f2328", Border: "#57606a", Title: "#1f2328",
+		Field: "#d0d7de", Selection: "#9ec5fe", SelectionText: "#000000",
+		Accent: "#1a7f37", Warning: "#9a6700", Error: "#cf222e", Muted: "#6e7781", Emphasis: "#000000", Bar: "#0969da",
+	},
+	"solarized": {
+		Background: "#002b36", Text: "#839496", Border: "#586e75", Title: "#93a1a1",
+		Field: "#073642", Selection: "#268bd2", SelectionText: "#002b36",
+		Accent: "#859900", Warning: "#b58900", Error: "#dc322f", Muted: "#586e75", Emphasis: "#eee8d5", Bar: "#2aa198",
+	},
+	"gruvbox": {
+		Background: "#282828", Text: "#ebdbb2", Border: "#a89984", Title: "#ebdbb2",
+		Field: "#3c3836", Selection: "#458588", SelectionText: "#282828",
+		Accent: "#b8bb26", Warning: "#fabd2f", Error: "#fb4934", Muted: "#928374", Emphasis: "#fbf1c7", Bar: "#83a598",
+	},
+	"nord": {
+		Background: "#2e3440", Text: "#d8dee9", Border: "#4c566a", Title: "#eceff4",
+		Field: "#3b4252", Selection: "#5e81ac", SelectionText: "#eceff4",
+		Accent: "#a3be8c", Warning: "#ebcb8b", Error: "#bf616a", Muted: "#616e88", Emphasis: "#eceff4", Bar: "#88c0d0",
+	},
+}
+
+// Theme is a resolved palette.
+type Theme struct {
+	Name string
+
+	Background, Text, Border, Title tcell.Color
+	Field, Selection, SelectionText tcell.Color
+	Accent, Warning, Error, Muted   tcell.Color
+	Emphasis, Bar                   tcell.Color
+}
+
+// Names returns the names of the built-in themes and those in custom,
+// sorted.
+func Names(custom map[string]Palette) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, m := range []map[string]Palette{Builtin, custom} {
+		for name := range m {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the theme called name, looking in custom before the
+// built-in themes. "" and "auto" pick "light" or "dark" to match the
+// terminal's background (see Light).
+func Resolve(name string, custom map[string]Palette) (Theme, error) {
+	if name == "" || name == "auto" {
+		name = "dark"
+		if Light() {
+			name = "light"
+		}
+	}
+	pal, err := flatten(name, custom, 0)
+	if err != nil {
+		return Theme{}, err
+	}
+	t := Theme{Name: name}
+	for _, c := range []struct {
+		dst  *tcell.Color
+		src  string
+		name string
+	}{
+		{&t.Background, pal.Background, "background"},
+		{&t.Text, pal.Text, "text"},
+		{&t.Border, pal.Border, "border"},
+		{&t.Title, pal.Title, "title"},
+		{&t.Field, pal.Field, "field"},
+		{&t.Selection, pal.Selection, "selection"},
+		{&t.SelectionText, pal.SelectionText, "selection_text"},
+		{&t.Accent, pal.Accent, "accent"},
+		{&t.Warning, pal.Warning, "warning"},
+		{&t.Error, pal.Error, "error"},
+		{&t.Muted, pal.Muted, "muted"},
+		{&t.Emphasis, pal.Emphasis, "emphasis"},
+		{&t.Bar, pal.Bar, "bar"},
+	} {
+		color, ok := parseColor(c.src)
+		if !ok {
+			return Theme{}, fmt.Errorf("theme %s: bad %s color %q", name, c.name, c.src)
+		}
+		*c.dst = color
+	}
+	return t, nil
+}
+
+// flatten returns the palette called name with the colors it leaves unset
+// filled in from its bases.
+func flatten(name string, custom map[string]Palette, depth int) (Palette, error) {
+	if depth > 8 {
+		return Palette{}, fmt.Errorf("theme %s: base themes form a loop", name)
+	}
+	pal, ok := custom[name]
+	if !ok {
+		if pal, ok = Builtin[name]; !ok {
+			return Palette{}, fmt.Errorf("unknown theme %q (have %s)", name, strings.Join(Names(custom), ", "))
+		}
+		return pal, nil
+	}
+	base := pal.Base
+	if base == "" {
+		base = "dark"
+	}
+	if base == name {
+		// A user theme customizing the built-in one of the same name
+		if _, builtin := Builtin[name]; builtin && depth == 0 {
+			return merge(pal, Builtin[name]), nil
+		}
+		return Palette{}, fmt.Errorf("theme %s: base themes form a loop", name)
+	}
+	parent, err := flatten(base, custom, depth+1)
+	if err != nil {
+		return Palette{}, err
+	}
+	return merge(pal, parent), nil
+}
+
+// merge fills the unset colors of pal from parent.
+func merge(pal, parent Palette) Palette {
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	fill(&pal.Background, parent.Background)
+	fill(&pal.Text, parent.Text)
+	fill(&pal.Border, parent.Border)
+	fill(&pal.Title, parent.Title)
+	fill(&pal.Field, parent.Field)
+	fill(&pal.Selection, parent.Selection)
+	fill(&pal.SelectionText, parent.SelectionText)
+	fill(&pal.Accent, parent.Accent)
+	fill(&pal.Warning, parent.Warning)
+	fill(&pal.Error, parent.Error)
+	fill(&pal.Muted, parent.Muted)
+	fill(&pal.Emphasis, parent.Emphasis)
+	fill(&pal.Bar, parent.Bar)
+	return pal
+}
+
+func parseColor(s string) (tcell.Color, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "default" {
+		return tcell.ColorDefault, true
+	}
+	c := tcell.GetColor(s)
+	return c, c != tcell.ColorDefault
+}
+
+// Apply makes t the theme of all tview primitives created afterwards. The
+// UI writes status text with the color tags [green], [yellow], [red],
+// [gray], [white] and [aqua]; Apply points those names at the theme's
+// accent, warning, error, muted, emphasis and bar colors. Call it before
+// the UI starts, or from its goroutine when the theme changes, as draws
+// read these.
+func (t Theme) Apply() {
+	tview.Styles.PrimitiveBackgroundColor = t.Background
+	tview.Styles.ContrastBackgroundColor = t.Field
+	tview.Styles.MoreContrastBackgroundColor = t.Selection
+	tview.Styles.BorderColor = t.Border
+	tview.Styles.TitleColor = t.Title
+	tview.Styles.GraphicsColor = t.Border
+	tview.Styles.PrimaryTextColor = t.Text
+	tview.Styles.SecondaryTextColor = t.Warning
+	tview.Styles.TertiaryTextColor = t.Accent
+	tview.Styles.InverseTextColor = t.SelectionText
+	tview.Styles.ContrastSecondaryTextColor = t.Muted
+
+	tcell.ColorNames["green"] = t.Accent
+	tcell.ColorNames["yellow"] = t.Warning
+	tcell.ColorNames["red"] = t.Error
+	tcell.ColorNames["gray"] = t.Muted
+	tcell.ColorNames["white"] = t.Emphasis
+	tcell.ColorNames["aqua"] = t.Bar
+}
@@ -0,0 +1,107 @@
+// Package trackmeta tidies the artist and title of videos. A YouTube
+// uploader is often a channel ("ArtistVEVO", "Artist - Topic", a label)
+// rather than the artist, and titles carry noise such as "Artist - Song
+// (Official Video) [4K]", which would otherwise end up in the queue, the
+// history and the lyrics lookups.
+package trackmeta
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Video is what is known of a video.
+type Video struct {
+	Title    string // the video's title
+	Uploader string // the channel that uploaded it
+
+	// Artist, Track and Album describe the song when YouTube Music knows
+	// it; they are empty for most videos.
+	Artist string
+	Track  string
+	Album  string
+}
+
+// Song is the tidied metadata of a video.
+type Song struct {
+	Artist string
+	Title  string
+	Album  string
+}
+
+// noiseWords are the extras videos add to song titles.
+const noiseWords = `official|video|audio|lyrics?|visuali[sz]er|remaster(ed)?|hd|hq|4k|mv|m/v`
+
+// bracketNoise matches the bracketed extras of video titles, e.g.
+// "(Official Video)" or "[Lyrics]".
+var bracketNoise = regexp.MustCompile(`(?i)\s*[\(\[][^\)\]]*\b(` + noiseWords + `)\b[^\)\]]*[\)\]]`)
+
+// trailingNoise matches the same extras left unbracketed at the end of a
+// title, e.g. "Song | Official Music Video" or "Song Lyrics".
+var trailingNoise = regexp.MustCompile(`(?i)(\s+[|/-]+)?\s+(official\s+(music\s+)?(video|audio)|lyric\s+video|lyrics|hd|4k)\s*$`)
+
+// quoted matches titles such as `Artist "Song"`.
+var quoted = regexp.MustCompile(`^(.+?)\s+["“](.+)["”]$`)
+
+// titleSeparators split "Artist - Song" titles.
+var titleSeparators = []string{" - ", " – ", " — "}
+
+// Normalize returns the artist, title and album of v: YouTube Music's when
+// it has them, or else split from the title and tidied.
+func Normalize(v Video) Song {
+	if v.Track != "" && v.Artist != "" {
+		return Song{Artist: strings.TrimSpace(v.Artist), Title: strings.TrimSpace(v.Track), Album: strings.TrimSpace(v.Album)}
+	}
+	if name, ok := strings.CutSuffix(v.Uploader, " - Topic"); ok {
+		// Songs YouTube Music uploads itself, titled with just the song
+		return Song{Artist: strings.TrimSpace(name), Title: StripNoise(v.Title), Album: strings.TrimSpace(v.Album)}
+	}
+	artist, title := Clean(v.Uploader, v.Title)
+	return Song{Artist: artist, Title: title, Album: strings.TrimSpace(v.Album)}
+}
+
+// Clean turns a video's uploader and title into the artist and title of
+// the song: "Artist - Song (Official Video)" by "ArtistVEVO" becomes
+// "Artist" and "Song".
+func Clean(artist, title string) (string, string) {
+	title = StripNoise(title)
+	split := false
+	for _, sep := range titleSeparators {
+		if a, t, ok := strings.Cut(title, sep); ok && strings.TrimSpace(a) != "" && strings.TrimSpace(t) != "" {
+			artist, title, split = a, t, true
+			break
+		}
+	}
+	if !split {
+		if m := quoted.FindStringSubmatch(title); m != nil {
+			artist, title = m[1], m[2]
+		}
+	}
+	return Channel(artist), strings.TrimSpace(title)
+}
+
+// StripNoise removes the extras of a video title, keeping the song's
+// title and credits such as "(feat. Someone)".
+func StripNoise(title string) string {
+	title = bracketNoise.ReplaceAllString(title, "")
+	for {
+		stripped := trailingNoise.ReplaceAllString(title, "")
+		if stripped == title {
+			break
+		}
+		title = stripped
+	}
+	return strings.TrimSpace(title)
+}
+
+// Channel returns the artist a channel name stands for: "ArtistVEVO",
+// "Artist - Topic" and "Artist Official" are all "Artist".
+func Channel(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.TrimSuffix(name, "VEVO")
+	name = strings.TrimSuffix(name, " - Topic")
+	for _, suffix := range []string{" Official", " official", " OFFICIAL"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,222 @@
+// Package visualizer turns the sound being played into spectrum bars or a
+// waveform, CAVA style: it records the monitor of the default sound device
+// with parec (PulseAudio, or PipeWire's pulse server) or pw-record and runs
+// an FFT over the latest samples.
+package visualizer
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os/exec"
+	"sync"
+)
+
+// SampleRate is the rate the tap records at, mono.
+const SampleRate = 44100
+
+// frameSize is how many of the latest samples each picture is made of.
+const frameSize = 2048
+
+const (
+	// minFreq and maxFreq bound the spectrum shown by the bars.
+	minFreq = 50.0
+	maxFreq = 16000.0
+	// floorDB is the level shown as an empty bar.
+	floorDB = -70.0
+	// falloff is how much of its height a bar keeps per picture when the
+	// sound gets quieter, so bars sink instead of flickering.
+	falloff = 0.85
+)
+
+// ErrNoTap is returned by Start when no program to record the sound is
+// installed.
+var ErrNoTap = errors.New("no audio capture: install parec or pw-record")
+
+// taps are the recording commands tried in order, each printing signed
+// 16-bit little-endian mono samples of what the default device plays.
+var taps = [][]string{
+	{"parec", "--format=s16le", "--rate=44100", "--channels=1", "--latency-msec=20", "--device=@DEFAULT_MONITOR@"},
+	{"pw-record", "--rate=44100", "--channels=1", "--format=s16", "-P", "{ stream.capture.sink=true }", "-"},
+}
+
+// Analyzer records the sound being played and keeps the latest samples.
+type Analyzer struct {
+	cmd *exec.Cmd
+
+	mu      sync.Mutex
+	samples []float64 // ring buffer of the latest frameSize samples
+	pos     int
+	bars    []float64 // last heights, for the falloff
+	err     error     // why recording stopped
+}
+
+// Start records with the first tap that is installed.
+func Start() (*Analyzer, error) {
+	for _, args := range taps {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, args[1:]...)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			continue
+		}
+		a := &Analyzer{cmd: cmd, samples: make([]float64, frameSize)}
+		go a.read(stdout)
+		return a, nil
+	}
+	return nil, ErrNoTap
+}
+
+// read fills the ring buffer until the tap exits.
+func (a *Analyzer) read(r io.Reader) {
+	buf := make([]byte, 1024)
+	for {
+		n, err := io.ReadFull(r, buf)
+		a.mu.Lock()
+		for i := 0; i+1 < n; i += 2 {
+			a.samples[a.pos] = float64(int16(binary.LittleEndian.Uint16(buf[i:]))) / 32768
+			a.pos = (a.pos + 1) % frameSize
+		}
+		if err != nil {
+			a.err = errors.New("audio capture stopped")
+			a.mu.Unlock()
+			_ = a.cmd.Wait()
+			return
+		}
+		a.mu.Unlock()
+	}
+}
+
+// Stop ends the recording.
+func (a *Analyzer) Stop() {
+	if a.cmd.Process != nil {
+		_ = a.cmd.Process.Kill()
+	}
+}
+
+// Err reports why recording stopped, or nil while it goes on.
+func (a *Analyzer) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// frame returns the latest samples, oldest first. Must be called with a.mu
+// held.
+func (a *Analyzer) frame() []float64 {
+	out := make([]float64, frameSize)
+	n := copy(out, a.samples[a.pos:])
+	copy(out[n:], a.samples[:a.pos])
+	return out
+}
+
+// Wave returns n of the latest samples, -1 to 1, for an oscilloscope.
+func (a *Analyzer) Wave(n int) []float64 {
+	a.mu.Lock()
+	frame := a.frame()
+	a.mu.Unlock()
+	if n <= 0 {
+		return nil
+	}
+	// Show the last quarter of the frame, about 12ms, stretched to fit
+	span := frame[frameSize-frameSize/4:]
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = span[i*len(span)/n]
+	}
+	return out
+}
+
+// Bars returns the spectrum as n bars from low to high frequencies,
+// 0 to 1 each.
+func (a *Analyzer) Bars(n int) []float64 {
+	if n <= 0 {
+		return nil
+	}
+	a.mu.Lock()
+	frame := a.frame()
+	prev := a.bars
+	a.mu.Unlock()
+
+	mags := spectrum(frame)
+	binHz := float64(SampleRate) / frameSize
+	out := make([]float64, n)
+	for i := range out {
+		// Logarithmic bands, like the ear hears them
+		lo := minFreq * math.Pow(maxFreq/minFreq, float64(i)/float64(n))
+		hi := minFreq * math.Pow(maxFreq/minFreq, float64(i+1)/float64(n))
+		first, last := int(lo/binHz), int(hi/binHz)
+		if last <= first {
+			last = first + 1
+		}
+		peak := 0.0
+		for b := first; b < last && b < len(mags); b++ {
+			peak = max(peak, mags[b])
+		}
+		db := 20 * math.Log10(peak+1e-12)
+		v := math.Max(0, math.Min(1, (db-floorDB)/-floorDB))
+		if len(prev) == n {
+			v = math.Max(v, prev[i]*falloff)
+		}
+		out[i] = v
+	}
+
+	a.mu.Lock()
+	a.bars = out
+	a.mu.Unlock()
+	return append([]float64(nil), out...)
+}
+
+// spectrum returns the magnitudes of the frequencies in frame, Hann
+// windowed and scaled so a full-scale sine reads about 1.
+func spectrum(frame []float64) []float64 {
+	n := len(frame)
+	buf := make([]complex128, n)
+	for i, s := range frame {
+		w := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+		buf[i] = complex(s*w, 0)
+	}
+	fft(buf)
+	mags := make([]float64, n/2)
+	for i := range mags {
+		re, im := real(buf[i]), imag(buf[i])
+		mags[i] = math.Sqrt(re*re+im*im) / (float64(n) / 4)
+	}
+	return mags
+}
+
+// fft transforms x in place. len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	// Bit-reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j |= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		angle := -2 * math.Pi / float64(size)
+		step := complex(math.Cos(angle), math.Sin(angle))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even, odd := x[start+k], w*x[start+k+size/2]
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
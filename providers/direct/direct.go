@@ -0,0 +1,77 @@
+// Package direct provides tracks that point straight at a local file or a
+// plain stream URL which mpv can play without any extraction step.
+package direct
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"audictl/internal/provider"
+)
+
+type DirectProvider struct{}
+
+func New() *DirectProvider { return &DirectProvider{} }
+
+func (d *DirectProvider) Name() string { return "direct" }
+
+// IsPlayable reports whether location is something mpv can open directly:
+// an http(s) URL or an existing local file.
+func IsPlayable(location string) bool {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return true
+	}
+	info, err := os.Stat(location)
+	return err == nil && !info.IsDir()
+}
+
+// NewTrack builds a track for location. When title is empty the file name
+// (or URL) is used instead.
+func NewTrack(location, title, artist string, duration int) provider.Track {
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(location), filepath.Ext(location))
+	}
+	return provider.Track{
+		ID:       "direct:" + location,
+		Provider: "direct",
+		Title:    title,
+		Artist:   artist,
+		Duration: duration,
+		Links:    map[string]string{"url": location},
+		IsStream: duration <= 0 && strings.Contains(location, "://"),
+	}
+}
+
+// Search is not supported: direct tracks only come from files and playlists.
+func (d *DirectProvider) Search(query string, kind provider.SearchKind, limit int) ([]provider.Track, error) {
+	return nil, fmt.Errorf("direct provider: search %w", provider.ErrUnsupported)
+}
+
+// GetTrack accepts a location, with or without the direct: prefix.
+func (d *DirectProvider) GetTrack(id string) (provider.Track, error) {
+	location := strings.TrimPrefix(id, "direct:")
+	if !IsPlayable(location) {
+		return provider.Track{}, fmt.Errorf("not a playable file or url: %s", location)
+	}
+	return NewTrack(location, "", "", 0), nil
+}
+
+func (d *DirectProvider) ResolveStream(track provider.Track, qualityPreference provider.QualityPref) (provider.Stream, error) {
+	location := track.Links["url"]
+	if location == "" {
+		location = strings.TrimPrefix(track.ID, "direct:")
+	}
+	if location == "" {
+		return provider.Stream{}, fmt.Errorf("track has no location")
+	}
+	ext := filepath.Ext(location)
+	if i := strings.IndexAny(ext, "?#"); i >= 0 {
+		ext = ext[:i]
+	}
+	return provider.Stream{
+		URL:       location,
+		Container: strings.TrimPrefix(ext, "."),
+	}, nil
+}
@@ -0,0 +1,260 @@
+// Package local provides the music files in a folder, laid out as
+// Artist/Album/Track the way most rippers and players organise them.
+package local
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"audictl/internal/provider"
+)
+
+// audioExts are the file extensions taken for music.
+var audioExts = map[string]bool{
+	".mp3": true, ".flac": true, ".ogg": true, ".oga": true, ".opus": true,
+	".m4a": true, ".aac": true, ".wav": true, ".aiff": true, ".aif": true,
+	".wma": true, ".ape": true, ".wv": true, ".mka": true, ".alac": true,
+}
+
+// LocalProvider lists the music under a folder. The folder is read on
+// first use and again after Rescan.
+type LocalProvider struct {
+	root string
+
+	mu      sync.Mutex
+	tracks  []provider.Track
+	scanned bool
+}
+
+// New returns a provider for the music under root.
+func New(root string) *LocalProvider { return &LocalProvider{root: root} }
+
+func (l *LocalProvider) Name() string { return "local" }
+
+// Tracks returns every track in the folder, sorted by artist, album and
+// file name.
+func (l *LocalProvider) Tracks() ([]provider.Track, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.scanned {
+		tracks, err := scan(l.root)
+		if err != nil {
+			return nil, err
+		}
+		l.tracks, l.scanned = tracks, true
+	}
+	return l.tracks, nil
+}
+
+// Rescan makes the next call read the folder again.
+func (l *LocalProvider) Rescan() {
+	l.mu.Lock()
+	l.scanned = false
+	l.mu.Unlock()
+}
+
+// scan walks root for music files.
+func scan(root string) ([]provider.Track, error) {
+	var tracks []provider.Track
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// Skip what can't be read rather than giving up on the rest
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !audioExts[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		tracks = append(tracks, trackFor(path, rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("music folder: %w", err)
+	}
+	sort.SliceStable(tracks, func(i, j int) bool {
+		a, b := tracks[i], tracks[j]
+		if a.Artist != b.Artist {
+			return strings.ToLower(a.Artist) < strings.ToLower(b.Artist)
+		}
+		if a.Album != b.Album {
+			return strings.ToLower(a.Album) < strings.ToLower(b.Album)
+		}
+		return a.ID < b.ID
+	})
+	return tracks, nil
+}
+
+// trackFor builds the track for the file at path, rel being its path
+// under the music folder. The first folder names the artist and the last
+// one the album; loose files named "Artist - Title" are split as well.
+func trackFor(path, rel string) provider.Track {
+	dirs := strings.Split(filepath.Dir(rel), string(filepath.Separator))
+	if dirs[0] == "." {
+		dirs = nil
+	}
+	base := strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+	title, number := trimTrackNumber(base)
+
+	var artist, album string
+	switch len(dirs) {
+	case 0:
+		if a, t, ok := strings.Cut(title, " - "); ok {
+			artist, title = a, t
+		}
+	case 1:
+		artist = dirs[0]
+	default:
+		artist, album = dirs[0], dirs[len(dirs)-1]
+	}
+	t := provider.Track{
+		ID:       "local:" + path,
+		Provider: "local",
+		Title:    title,
+		Artist:   artist,
+		Album:    album,
+		Links:    map[string]string{"url": path},
+	}
+	if number != "" {
+		t.Tags = map[string]string{"track": number}
+	}
+	return t
+}
+
+// trimTrackNumber splits a leading track number off a file name, as in
+// "01 - Title", "01. Title" or "01 Title". A number followed by a space
+// only counts when zero-padded, so "99 Luftballons" keeps its title.
+func trimTrackNumber(name string) (title, number string) {
+	i := 0
+	for i < len(name) && name[i] >= '0' && name[i] <= '9' {
+		i++
+	}
+	if i == 0 || i > 3 {
+		return name, ""
+	}
+	rest := strings.TrimLeft(name[i:], " .-_")
+	sep := name[i : len(name)-len(rest)]
+	if rest == "" || sep == "" || (!strings.ContainsAny(sep, ".-_") && name[0] != '0') {
+		return name, ""
+	}
+	return rest, name[:i]
+}
+
+// Search returns the tracks whose artist, album and title together contain
+// every word of query.
+func (l *LocalProvider) Search(query string, kind provider.SearchKind, limit int) ([]provider.Track, error) {
+	tracks, err := l.Tracks()
+	if err != nil {
+		return nil, err
+	}
+	var out []provider.Track
+	for _, t := range tracks {
+		if Matches(t, query) {
+			out = append(out, t)
+			if limit > 0 && len(out) == limit {
+				break
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no results found")
+	}
+	return out, nil
+}
+
+// Matches reports whether the artist, album and title of t together
+// contain every word of query, ignoring case.
+func Matches(t provider.Track, query string) bool {
+	text := strings.ToLower(t.Artist + " " + t.Album + " " + t.Title)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetTrack accepts a file path, with or without the local: prefix.
+func (l *LocalProvider) GetTrack(id string) (provider.Track, error) {
+	path := strings.TrimPrefix(id, "local:")
+	tracks, err := l.Tracks()
+	if err != nil {
+		return provider.Track{}, err
+	}
+	for _, t := range tracks {
+		if t.Links["url"] == path {
+			return t, nil
+		}
+	}
+	return provider.Track{}, fmt.Errorf("%w: %s", provider.ErrNotFound, path)
+}
+
+func (l *LocalProvider) ResolveStream(track provider.Track, qualityPreference provider.QualityPref) (provider.Stream, error) {
+	path := track.Links["url"]
+	if path == "" {
+		path = strings.TrimPrefix(track.ID, "local:")
+	}
+	if path == "" {
+		return provider.Stream{}, fmt.Errorf("track has no location")
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	return provider.Stream{
+		URL:       path,
+		Container: strings.TrimPrefix(ext, "."),
+		Lossless:  ext == ".flac" || ext == ".wav" || ext == ".aiff" || ext == ".aif" || ext == ".ape" || ext == ".wv" || ext == ".alac",
+	}, nil
+}
+
+// Artist is an artist of the library with their albums.
+type Artist struct {
+	Name   string
+	Albums []Album
+}
+
+// Album is an album of an artist with its tracks.
+type Album struct {
+	Name   string
+	Tracks []provider.Track
+}
+
+// Group arranges tracks, sorted as Tracks returns them, by artist and
+// album.
+func Group(tracks []provider.Track) []Artist {
+	var artists []Artist
+	for _, t := range tracks {
+		if n := len(artists); n == 0 || artists[n-1].Name != t.Artist {
+			artists = append(artists, Artist{Name: t.Artist})
+		}
+		a := &artists[len(artists)-1]
+		if n := len(a.Albums); n == 0 || a.Albums[n-1].Name != t.Album {
+			a.Albums = append(a.Albums, Album{Name: t.Album})
+		}
+		al := &a.Albums[len(a.Albums)-1]
+		al.Tracks = append(al.Tracks, t)
+	}
+	return artists
+}
+
+// Tracks returns every track of the artist's albums.
+func (a Artist) Tracks() []provider.Track {
+	var out []provider.Track
+	for _, al := range a.Albums {
+		out = append(out, al.Tracks...)
+	}
+	return out
+}
@@ -4,15 +4,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 
 	"audictl/internal/provider"
+	"audictl/internal/ratelimit"
 	yprov "audictl/providers/youtube"
 )
 
+// client makes the oEmbed lookups, see SetProxy.
+var client = ratelimit.DefaultClient
+
+// SetProxy makes the oEmbed lookups connect through proxy, "" for the
+// environment's. Call it at startup.
+func SetProxy(proxy string) error {
+	c, err := ratelimit.NewClient(proxy)
+	if err != nil {
+		return err
+	}
+	client = c
+	return nil
+}
+
 type SpotifyProvider struct {
 	yt provider.Provider
 }
@@ -48,7 +62,7 @@ func parseSpotifyURL(rawURL string) (idType, id string, err error) {
 // Returns JSON with "title" field like "Never Gonna Give You Up"
 func spotifyOEmbed(spotifyURL string) (title string, err error) {
 	apiURL := "https://open.spotify.com/oembed?url=" + url.QueryEscape(spotifyURL)
-	resp, err := http.Get(apiURL)
+	resp, err := client.Get(apiURL)
 	if err != nil {
 		return "", fmt.Errorf("oembed request failed: %w", err)
 	}
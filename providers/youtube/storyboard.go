@@ -0,0 +1,115 @@
+package youtube
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"math"
+	"strings"
+)
+
+// ErrNoStoryboard is returned for videos YouTube keeps no preview frames
+// for, such as live streams.
+var ErrNoStoryboard = errors.New("no storyboard")
+
+// Storyboard is the grid of preview frames YouTube shows when scrubbing a
+// video. The frames are tiled Rows by Columns into sheets, each an image
+// covering the next stretch of the video.
+type Storyboard struct {
+	Width, Height int     // of one frame, in pixels
+	Rows, Columns int     // frames per sheet
+	Interval      float64 // seconds between frames
+	Sheets        []StoryboardSheet
+}
+
+// StoryboardSheet is one image of a storyboard.
+type StoryboardSheet struct {
+	URL      string
+	Duration float64 // seconds of video its frames cover
+}
+
+// Storyboards returns the storyboard of the video with id, the one with
+// the largest frames when there are several.
+func Storyboards(id string) (Storyboard, error) {
+	id = strings.TrimPrefix(id, "youtube:")
+	out, err := ytDlpOutput("-j", "https://www.youtube.com/watch?v="+id)
+	if err != nil {
+		return Storyboard{}, err
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal(out, &meta); err != nil {
+		return Storyboard{}, err
+	}
+	return parseStoryboard(meta)
+}
+
+// parseStoryboard picks the storyboard with the largest frames out of the
+// formats yt-dlp lists in meta.
+func parseStoryboard(meta map[string]interface{}) (Storyboard, error) {
+	formats, _ := meta["formats"].([]interface{})
+	var best Storyboard
+	for _, v := range formats {
+		f, ok := v.(map[string]interface{})
+		if !ok || safeString(f["format_note"]) != "storyboard" {
+			continue
+		}
+		sb := Storyboard{
+			Width:   int(safeFloat64(f["width"])),
+			Height:  int(safeFloat64(f["height"])),
+			Rows:    int(safeFloat64(f["rows"])),
+			Columns: int(safeFloat64(f["columns"])),
+		}
+		fragments, _ := f["fragments"].([]interface{})
+		for _, fv := range fragments {
+			frag, ok := fv.(map[string]interface{})
+			if !ok || safeString(frag["url"]) == "" {
+				continue
+			}
+			sb.Sheets = append(sb.Sheets, StoryboardSheet{URL: safeString(frag["url"]), Duration: safeFloat64(frag["duration"])})
+		}
+		if fps := safeFloat64(f["fps"]); fps > 0 {
+			sb.Interval = 1 / fps
+		} else if len(sb.Sheets) > 0 && sb.Rows*sb.Columns > 0 {
+			sb.Interval = sb.Sheets[0].Duration / float64(sb.Rows*sb.Columns)
+		}
+		if sb.Width <= 0 || sb.Height <= 0 || sb.Rows <= 0 || sb.Columns <= 0 || sb.Interval <= 0 || len(sb.Sheets) == 0 {
+			continue
+		}
+		if sb.Width > best.Width {
+			best = sb
+		}
+	}
+	if best.Width == 0 {
+		return Storyboard{}, ErrNoStoryboard
+	}
+	return best, nil
+}
+
+// Frame returns the URL of the sheet holding the frame shown at pos
+// seconds, and where in the sheet the frame is. Positions past the last
+// sheet give its last frame; ok is false for negative ones.
+func (s Storyboard) Frame(pos float64) (url string, rect image.Rectangle, ok bool) {
+	if pos < 0 || len(s.Sheets) == 0 || s.Interval <= 0 {
+		return "", image.Rectangle{}, false
+	}
+	perSheet := s.Rows * s.Columns
+	start := 0.0
+	sheet := len(s.Sheets) - 1
+	for i, sh := range s.Sheets {
+		if pos < start+sh.Duration {
+			sheet = i
+			break
+		}
+		if i < len(s.Sheets)-1 {
+			start += sh.Duration
+		}
+	}
+	last := perSheet - 1
+	if d := s.Sheets[sheet].Duration; d > 0 {
+		// The last sheet is usually only partly filled
+		last = min(last, int(math.Ceil(d/s.Interval))-1)
+	}
+	n := max(min(int((pos-start)/s.Interval), last), 0)
+	x, y := n%s.Columns*s.Width, n/s.Columns*s.Height
+	return s.Sheets[sheet].URL, image.Rect(x, y, x+s.Width, y+s.Height), true
+}
@@ -0,0 +1,68 @@
+package youtube
+
+import (
+	"encoding/json"
+	"image"
+	"testing"
+)
+
+func TestParseStoryboard(t *testing.T) {
+	// Trimmed from yt-dlp -j: an audio format, a small storyboard, a large
+	// one with fps and a broken one
+	const formats = `{"formats": [
+		{"format_id": "251", "format_note": "medium", "abr": 130},
+		{"format_id": "sb1", "format_note": "storyboard", "width": 80, "height": 45, "rows": 10, "columns": 10,
+			"fragments": [{"url": "https://i.ytimg.com/sb1/M0.jpg", "duration": 500}]},
+		{"format_id": "sb0", "format_note": "storyboard", "width": 160, "height": 90, "rows": 5, "columns": 5, "fps": 0.5,
+			"fragments": [{"url": "https://i.ytimg.com/sb0/M0.jpg", "duration": 50}, {"url": "https://i.ytimg.com/sb0/M1.jpg", "duration": 20}]},
+		{"format_id": "sb2", "format_note": "storyboard", "width": 320, "height": 180, "rows": 3, "columns": 3}
+	]}`
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(formats), &meta); err != nil {
+		t.Fatal(err)
+	}
+	sb, err := parseStoryboard(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sb.Width != 160 || sb.Height != 90 || sb.Rows != 5 || sb.Columns != 5 || sb.Interval != 2 || len(sb.Sheets) != 2 {
+		t.Errorf("storyboard = %+v, want the 160x90 one every 2s over two sheets", sb)
+	}
+
+	if _, err := parseStoryboard(map[string]interface{}{}); err != ErrNoStoryboard {
+		t.Errorf("err = %v without formats, want ErrNoStoryboard", err)
+	}
+}
+
+func TestStoryboardFrame(t *testing.T) {
+	sb := Storyboard{
+		Width: 160, Height: 90,
+		Rows: 2, Columns: 3,
+		Interval: 10,
+		Sheets: []StoryboardSheet{
+			{URL: "M0", Duration: 60},
+			{URL: "M1", Duration: 25}, // three frames of six
+		},
+	}
+	for _, tc := range []struct {
+		pos  float64
+		url  string
+		rect image.Rectangle
+		ok   bool
+	}{
+		{0, "M0", image.Rect(0, 0, 160, 90), true},
+		{15, "M0", image.Rect(160, 0, 320, 90), true},
+		{35, "M0", image.Rect(0, 90, 160, 180), true},
+		{59.9, "M0", image.Rect(320, 90, 480, 180), true},
+		{60, "M1", image.Rect(0, 0, 160, 90), true},
+		{84, "M1", image.Rect(320, 0, 480, 90), true},
+		// Past the end, the last frame there is
+		{500, "M1", image.Rect(320, 0, 480, 90), true},
+		{-1, "", image.Rectangle{}, false},
+	} {
+		url, rect, ok := sb.Frame(tc.pos)
+		if url != tc.url || rect != tc.rect || ok != tc.ok {
+			t.Errorf("Frame(%v) = %q, %v, %v, want %q, %v, %v", tc.pos, url, rect, ok, tc.url, tc.rect, tc.ok)
+		}
+	}
+}
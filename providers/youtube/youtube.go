@@ -1,15 +1,27 @@
 package youtube
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"audictl/internal/debuglog"
+	"audictl/internal/lang"
 	"audictl/internal/provider"
+	"audictl/internal/ratelimit"
+	"audictl/internal/trackmeta"
 )
 
 type YouTubeProvider struct{}
@@ -18,14 +30,170 @@ func New() *YouTubeProvider { return &YouTubeProvider{} }
 
 func (y *YouTubeProvider) Name() string { return "youtube" }
 
+// ytDlpPath is the yt-dlp executable run for every lookup.
+var ytDlpPath = "yt-dlp"
+
+// SetYtDlpPath makes the provider run the yt-dlp at path instead of the one
+// on PATH. An empty path restores the default.
+func SetYtDlpPath(path string) {
+	if path == "" {
+		path = "yt-dlp"
+	}
+	ytDlpPath = path
+}
+
+// ytDlpOptions are passed to every yt-dlp run, see SetOptions.
+var ytDlpOptions map[string]string
+
+// SetOptions passes options to every yt-dlp run, keyed by option name
+// without dashes, e.g. {"cookies": "cookies.txt"} for --cookies
+// cookies.txt. An empty value passes the option as a flag. mpv's
+// ytdl-raw-options take the same form.
+func SetOptions(options map[string]string) {
+	ytDlpOptions = options
+}
+
+// ErrYtDlpMissing is returned when yt-dlp can't be run at all.
+var ErrYtDlpMissing = errors.New("yt-dlp not found")
+
+// CheckYtDlp runs yt-dlp --version and returns the version, or an error
+// saying how to fix a missing install.
+func CheckYtDlp() (string, error) {
+	out, err := getYtDlpCmd("--version").Output()
+	if err != nil {
+		return "", ytDlpError(err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ytDlpError turns a failure to start yt-dlp into ErrYtDlpMissing with a
+// hint, and a failed run into the error its stderr describes (see
+// stderrError). Other errors are left alone.
+func ytDlpError(err error) error {
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("%w (%s): install it from https://github.com/yt-dlp/yt-dlp or set yt_dlp_path in the config", ErrYtDlpMissing, ytDlpPath)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		debuglog.Printf("yt-dlp", "%v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		if e := stderrError(exitErr.Stderr); e != nil {
+			return e
+		}
+	}
+	return err
+}
+
+// errorPhrases map what yt-dlp's error messages say to provider errors,
+// checked in order and compared case-insensitively.
+var errorPhrases = []struct {
+	err     error
+	phrases []string
+}{
+	{provider.ErrAgeRestricted, []string{"confirm your age", "age-restricted", "age restricted", "inappropriate for some users"}},
+	{provider.ErrGeoBlocked, []string{"available in your country", "blocked it in your country", "geo restriction", "geo-restricted"}},
+	{provider.ErrRateLimited, []string{"http error 429", "too many requests", "not a bot", "rate-limited", "rate limited"}},
+	{provider.ErrNotFound, []string{"video unavailable", "private video", "has been removed", "does not exist", "no longer available", "http error 404", "has been terminated"}},
+	{provider.ErrExtractorFailed, []string{"unable to extract", "unsupported url", "nsig extraction failed", "signature extraction failed", "requested format is not available", "please report this issue"}},
+	{provider.ErrNetwork, []string{"urlopen error", "failed to resolve", "name resolution", "name or service not known", "getaddrinfo failed", "network is unreachable", "no route to host", "connection refused", "connection reset", "timed out"}},
+}
+
+// stderrError returns the last ERROR line yt-dlp printed on stderr as an
+// error, wrapping the matching provider error if there is one, or nil if
+// there is no such line.
+func stderrError(stderr []byte) error {
+	var msg string
+	for _, line := range strings.Split(string(stderr), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "ERROR:"); ok {
+			msg = strings.TrimSpace(rest)
+		}
+	}
+	if msg == "" {
+		return nil
+	}
+	lower := strings.ToLower(msg)
+	for _, ep := range errorPhrases {
+		for _, phrase := range ep.phrases {
+			if strings.Contains(lower, phrase) {
+				return fmt.Errorf("%w: %s", ep.err, msg)
+			}
+		}
+	}
+	return fmt.Errorf("yt-dlp: %s", msg)
+}
+
+// limiter spaces out yt-dlp runs, whichever YouTubeProvider makes them, so
+// rapid searches and playlist expansion don't get the user throttled.
+var limiter = ratelimit.ForProvider("youtube")
+
+const (
+	// maxRateRetries is how often a run YouTube rate limited is retried.
+	maxRateRetries = 2
+	// backoffBase and backoffMax bound how long runs are held back after
+	// YouTube rate limited one, see backoff.
+	backoffBase = 2 * time.Second
+	backoffMax  = 2 * time.Minute
+)
+
+var (
+	throttleMu sync.Mutex
+	throttled  int // runs rate limited in a row
+)
+
+// backoff notes how the yt-dlp run that returned err went and reports
+// whether to run it again. While YouTube answers that it is rate limiting,
+// every run is held back for a jittered delay that grows with each such
+// answer in a row; the run itself is retried up to maxRateRetries times.
+func backoff(err error, attempt int) bool {
+	throttleMu.Lock()
+	if !errors.Is(err, provider.ErrRateLimited) {
+		throttled = 0
+		throttleMu.Unlock()
+		return false
+	}
+	throttled++
+	wait := ratelimit.Backoff(throttled-1, backoffBase, backoffMax)
+	throttleMu.Unlock()
+	debuglog.Printf("yt-dlp", "rate limited, holding back for %s", wait.Round(time.Millisecond))
+	limiter.Pause(wait)
+	return attempt < maxRateRetries
+}
+
+// ytDlpOutput runs yt-dlp with args when the limiter allows and returns
+// what it printed, retrying as backoff says.
+func ytDlpOutput(args ...string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		limiter.Wait()
+		out, err := getYtDlpCmd(args...).Output()
+		if err != nil {
+			err = ytDlpError(err)
+		}
+		if !backoff(err, attempt) {
+			return out, err
+		}
+	}
+}
+
 // getYtDlpCmd returns an exec.Cmd for yt-dlp with proper PATH including deno
 func getYtDlpCmd(args ...string) *exec.Cmd {
-	cmd := exec.Command("yt-dlp", args...)
+	var names []string
+	for name := range ytDlpOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var opts []string
+	for _, name := range names {
+		opts = append(opts, "--"+name)
+		if value := ytDlpOptions[name]; value != "" {
+			opts = append(opts, value)
+		}
+	}
+	cmd := exec.Command(ytDlpPath, append(opts, args...)...)
+	debuglog.Printf("yt-dlp", "%s", strings.Join(cmd.Args, " "))
 	// Ensure deno is in PATH for yt-dlp's JavaScript runtime
 	home, _ := os.UserHomeDir()
 	denoPath := filepath.Join(home, ".deno", "bin")
 	currentPath := os.Getenv("PATH")
-	cmd.Env = append(os.Environ(), fmt.Sprintf("PATH=%s:%s", denoPath, currentPath))
+	cmd.Env = append(os.Environ(), "PATH="+denoPath+string(os.PathListSeparator)+currentPath)
 	return cmd
 }
 
@@ -39,44 +207,23 @@ func (y *YouTubeProvider) Search(query string, kind provider.SearchKind, limit i
 	}
 
 	// use ytsearch to get multiple results
-	q := fmt.Sprintf("ytsearch%d:%s", limit, query)
-	cmd := getYtDlpCmd("-j", "--flat-playlist", q)
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("yt-dlp search failed: %w", err)
+	args := []string{"-j", "--flat-playlist", fmt.Sprintf("ytsearch%d:%s", limit, query)}
+	if kind == provider.SearchKindSong {
+		// The songs section of YouTube Music's results
+		q := "https://music.youtube.com/search?q=" + url.QueryEscape(query) + "#songs"
+		args = []string{"-j", "--flat-playlist", "--playlist-end", strconv.Itoa(limit), q}
 	}
-
-	// yt-dlp outputs one JSON object per line
 	var tracks []provider.Track
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		var meta map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &meta); err != nil {
-			continue
-		}
-		title := safeString(meta["title"])
-		uploader := safeString(meta["uploader"])
-		if uploader == "" {
-			uploader = safeString(meta["channel"])
+	err := runJSON(func(meta map[string]interface{}) {
+		if t, ok := y.trackFromMeta(meta); ok {
+			tracks = append(tracks, t)
 		}
-		duration := int(safeFloat64(meta["duration"]))
-		id := safeString(meta["id"])
-		if id == "" {
-			id = safeString(meta["url"])
-		}
-
-		t := provider.Track{
-			ID:       "youtube:" + id,
-			Provider: y.Name(),
-			Title:    title,
-			Artist:   uploader,
-			Duration: duration,
-			Links:    map[string]string{"youtube": fmt.Sprintf("https://www.youtube.com/watch?v=%s", id)},
-		}
-		tracks = append(tracks, t)
+	}, args...)
+	if errors.Is(err, ErrYtDlpMissing) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp search failed: %w", err)
 	}
 
 	if len(tracks) == 0 {
@@ -91,8 +238,7 @@ func (y *YouTubeProvider) GetTrack(id string) (provider.Track, error) {
 		id = strings.TrimPrefix(id, "youtube:")
 	}
 	url := "https://www.youtube.com/watch?v=" + id
-	cmd := getYtDlpCmd("-j", url)
-	out, err := cmd.Output()
+	out, err := ytDlpOutput("-j", url)
 	if err != nil {
 		return provider.Track{}, err
 	}
@@ -100,18 +246,8 @@ func (y *YouTubeProvider) GetTrack(id string) (provider.Track, error) {
 	if err := json.Unmarshal(out, &meta); err != nil {
 		return provider.Track{}, err
 	}
-	title := safeString(meta["title"])
-	uploader := safeString(meta["uploader"])
-	duration := int(safeFloat64(meta["duration"]))
-
-	t := provider.Track{
-		ID:       "youtube:" + id,
-		Provider: y.Name(),
-		Title:    title,
-		Artist:   uploader,
-		Duration: duration,
-		Links:    map[string]string{"youtube": url},
-	}
+	meta["id"] = id
+	t, _ := y.trackFromMeta(meta)
 	return t, nil
 }
 
@@ -128,12 +264,20 @@ func (y *YouTubeProvider) ResolveStream(track provider.Track, qualityPreference
 	}
 
 	// Try JSON extraction to get formats and direct URLs
-	jcmd := getYtDlpCmd("-f", "bestaudio[ext=webm+opus]/bestaudio/best", "-j", target)
-	jout, err := jcmd.Output()
+	selector := FormatSelector(qualityPreference)
+	jout, err := ytDlpOutput("-f", selector, "-j", target)
 	if err != nil {
+		// mpv needs yt-dlp for page URLs too, and would fail the same way
+		// on videos that are gone or locked, so there is nothing to fall
+		// back to
+		for _, fatal := range []error{ErrYtDlpMissing, provider.ErrNotFound, provider.ErrAgeRestricted, provider.ErrGeoBlocked, provider.ErrRateLimited, provider.ErrNetwork} {
+			if errors.Is(err, fatal) {
+				return provider.Stream{}, err
+			}
+		}
 		// If yt-dlp JSON extraction fails, fall back to returning the page URL so mpv can handle it.
 		// This avoids hard failure when yt-dlp lacks a JS runtime or SABR formats.
-		return provider.Stream{URL: target, Meta: map[string]string{"note": "fallback to page URL"}}, nil
+		return provider.Stream{URL: target, Meta: map[string]string{"note": "fallback to page URL", "ytdl-format": selector}}, nil
 	}
 
 	var meta map[string]interface{}
@@ -141,37 +285,17 @@ func (y *YouTubeProvider) ResolveStream(track provider.Track, qualityPreference
 		return provider.Stream{}, err
 	}
 
-	// Find best audio format with a direct URL
-	var chosenURL, chosenExt, chosenCodec string
-	var chosenAbr float64
-	if fmts, ok := meta["formats"]; ok {
-		if arr, ok := fmts.([]interface{}); ok {
-			for _, fi := range arr {
-				if m, ok := fi.(map[string]interface{}); ok {
-					urlv := safeString(m["url"])
-					if urlv == "" {
-						continue
-					}
-					acodec := safeString(m["acodec"])
-					if acodec == "none" {
-						continue
-					}
-					abr := safeFloat64(m["abr"])
-					ext := safeString(m["ext"])
-					if chosenURL == "" || abr > chosenAbr {
-						chosenURL = urlv
-						chosenAbr = abr
-						chosenExt = ext
-						chosenCodec = acodec
-					}
-				}
-			}
-		}
-	}
+	chapters := parseChapters(meta["chapters"])
+
+	// yt-dlp describes the format the selector picked at the top level
+	chosenURL := safeString(meta["url"])
+	chosenExt := safeString(meta["ext"])
+	chosenCodec := safeString(meta["acodec"])
+	chosenAbr := safeFloat64(meta["abr"])
 	if chosenURL == "" {
 		// Many YouTube formats may use SABR or lack a direct URL in formats; fall back to the page URL
 		// so mpv (which supports youtube URLs) can resolve it itself.
-		return provider.Stream{URL: target, Meta: map[string]string{"note": "fallback to page URL"}}, nil
+		return provider.Stream{URL: target, Meta: map[string]string{"note": "fallback to page URL", "ytdl-format": selector}, Chapters: chapters}, nil
 	}
 
 	// Some direct format URLs (googlevideo/videoplayback) are short-lived or require
@@ -179,7 +303,21 @@ func (y *YouTubeProvider) ResolveStream(track provider.Track, qualityPreference
 	// HTTP 403. Prefer letting mpv resolve the original YouTube page URL so it can
 	// use its internal extractor (youtube.lua/yt-dlp) which handles required headers.
 	if strings.Contains(chosenURL, "googlevideo.com") || strings.Contains(chosenURL, "rr") {
-		return provider.Stream{URL: target, Meta: map[string]string{"note": "fallback to page URL (direct googlevideo URL skipped)"}}, nil
+		// The direct URL is kept for players that can't open the page themselves
+		return provider.Stream{
+			URL:       target,
+			Container: chosenExt,
+			Codec:     chosenCodec,
+			Bitrate:   int(chosenAbr),
+			Lossless:  isLossless(chosenCodec),
+			ExpiresAt: urlExpiry(chosenURL),
+			Meta: map[string]string{
+				"note":        "fallback to page URL (direct googlevideo URL skipped)",
+				"direct":      chosenURL,
+				"ytdl-format": selector,
+			},
+			Chapters: chapters,
+		}, nil
 	}
 
 	s := provider.Stream{
@@ -188,12 +326,135 @@ func (y *YouTubeProvider) ResolveStream(track provider.Track, qualityPreference
 		Codec:      chosenCodec,
 		Bitrate:    int(chosenAbr),
 		SampleRate: func() int { return 0 }(),
-		Lossless:   false,
+		Lossless:   isLossless(chosenCodec),
+		ExpiresAt:  urlExpiry(chosenURL),
 		Meta:       map[string]string{"orig": target},
+		Chapters:   chapters,
 	}
 	return s, nil
 }
 
+// Quality limits the streams ResolveStream picks, see SetQuality.
+type Quality struct {
+	Codecs  []string // preferred codecs, best first: "opus", "aac", "vorbis", ...
+	MinKbps int      // lowest acceptable bitrate, 0 for none
+	MaxKbps int      // highest acceptable bitrate, 0 for none
+}
+
+// quality is the policy set with SetQuality.
+var quality = Quality{Codecs: []string{"opus", "aac"}}
+
+// SetQuality sets which streams ResolveStream prefers. Codecs left empty
+// keep the default, opus before aac.
+func SetQuality(q Quality) {
+	if len(q.Codecs) == 0 {
+		q.Codecs = []string{"opus", "aac"}
+	}
+	quality = q
+}
+
+// codecIDs are the yt-dlp acodec prefixes of codec names.
+var codecIDs = map[string]string{"aac": "mp4a", "m4a": "mp4a"}
+
+// losslessCodecs are the yt-dlp acodec prefixes of lossless codecs, the
+// ones provider.QualityLosslessFirst asks for first.
+var losslessCodecs = []string{"flac", "alac"}
+
+// isLossless reports whether the yt-dlp acodec is one of losslessCodecs.
+func isLossless(acodec string) bool {
+	acodec = strings.ToLower(acodec)
+	for _, codec := range losslessCodecs {
+		if strings.HasPrefix(acodec, codec) {
+			return true
+		}
+	}
+	return false
+}
+
+// abrSteps are the bitrate floors provider.QualityLosslessFirst steps down
+// through after the lossless codecs. bestaudio on its own ranks codec
+// above bitrate, and would take 160k opus over 256k aac.
+var abrSteps = []int{320, 256, 192, 160, 128}
+
+// FormatSelector returns the yt-dlp format selector (-f) for pref under
+// the policy set with SetQuality: the preferred codecs in order within the
+// bitrate limits, then any codec within them, then anything at all.
+// provider.QualityLosslessFirst asks for the lossless codecs and then the
+// highest bitrate instead of the preferred codecs. Formats of unknown
+// bitrate pass the limits.
+func FormatSelector(pref provider.QualityPref) string {
+	q := quality
+	base, fallback := "bestaudio", "best"
+	if pref == provider.QualityLow {
+		base, fallback = "worstaudio", "worst"
+	}
+	limits := ""
+	if q.MinKbps > 0 {
+		limits += fmt.Sprintf("[abr>=?%d]", q.MinKbps)
+	}
+	if q.MaxKbps > 0 {
+		limits += fmt.Sprintf("[abr<=?%d]", q.MaxKbps)
+	}
+	var alts []string
+	if pref == provider.QualityLosslessFirst {
+		for _, codec := range losslessCodecs {
+			alts = append(alts, base+"[acodec^="+codec+"]"+limits)
+		}
+		for _, kbps := range abrSteps {
+			if kbps <= q.MinKbps || (q.MaxKbps > 0 && kbps > q.MaxKbps) {
+				continue // no different from the limits alone, or past them
+			}
+			alts = append(alts, fmt.Sprintf("%s[abr>=%d]%s", base, kbps, limits))
+		}
+	} else {
+		for _, codec := range q.Codecs {
+			codec = strings.ToLower(strings.TrimSpace(codec))
+			if id, ok := codecIDs[codec]; ok {
+				codec = id
+			}
+			alts = append(alts, base+"[acodec^="+codec+"]"+limits)
+		}
+	}
+	if limits != "" {
+		alts = append(alts, base+limits)
+	}
+	alts = append(alts, base, fallback)
+	return strings.Join(alts, "/")
+}
+
+// parseChapters reads the "chapters" of yt-dlp's JSON output, a list of
+// {"start_time", "end_time", "title"}.
+func parseChapters(v interface{}) []provider.Chapter {
+	arr, _ := v.([]interface{})
+	var chapters []provider.Chapter
+	for _, c := range arr {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		chapters = append(chapters, provider.Chapter{
+			Start: safeFloat64(m["start_time"]),
+			Title: strings.TrimSpace(safeString(m["title"])),
+		})
+	}
+	return chapters
+}
+
+// urlExpiry reads the expiry time signed into YouTube media URLs as the
+// "expire" query parameter (unix seconds). It returns the zero time when
+// the URL carries none.
+func urlExpiry(rawURL string) time.Time {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return time.Time{}
+	}
+	secs, err := strconv.ParseInt(u.Query().Get("expire"), 10, 64)
+	if err != nil || secs <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
+
 func safeString(v interface{}) string {
 	if v == nil {
 		return ""
@@ -225,62 +486,332 @@ func safeFloat64(v interface{}) float64 {
 	}
 }
 
+// Thumbnail returns the URL of the JPEG thumbnail of a YouTube video URL,
+// or "" if rawURL isn't one.
+func Thumbnail(rawURL string) string {
+	id := VideoID(rawURL)
+	if id == "" {
+		return ""
+	}
+	return "https://i.ytimg.com/vi/" + url.PathEscape(id) + "/hqdefault.jpg"
+}
+
+// VideoID extracts the video ID from youtube.com/watch, youtu.be and
+// youtube.com/shorts URLs. It returns "" for anything else (e.g. playlists).
+func VideoID(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	host = strings.TrimPrefix(host, "music.")
+	switch {
+	case host == "youtu.be":
+		return strings.Trim(u.Path, "/")
+	case host == "youtube.com" && u.Path == "/watch":
+		return u.Query().Get("v")
+	case host == "youtube.com" && strings.HasPrefix(u.Path, "/shorts/"):
+		return strings.TrimPrefix(u.Path, "/shorts/")
+	}
+	return ""
+}
+
 // FetchTracksFromURL accepts a YouTube video or playlist URL and returns one or more tracks.
 // If the URL points to a single video, a single-track slice is returned. For playlists the
-// function returns all entries found by yt-dlp's --flat-playlist JSON output. A limit <= 0
-// will use a sensible default (all entries up to 100).
+// function returns the entries found by yt-dlp's --flat-playlist JSON output: the first
+// limit of them, or all of them when limit <= 0.
 func (y *YouTubeProvider) FetchTracksFromURL(url string, limit int) ([]provider.Track, error) {
-	if limit <= 0 {
-		limit = 0 // yt-dlp will return all by default for playlists
+	var tracks []provider.Track
+	if err := y.listURL(url, limit, func(t provider.Track) { tracks = append(tracks, t) }); err != nil {
+		return nil, err
+	}
+	if len(tracks) == 0 {
+		return nil, fmt.Errorf("no tracks found for url")
+	}
+	return tracks, nil
+}
+
+// listURL passes each entry of a video or playlist URL to emit as yt-dlp
+// prints it, stopping after limit entries unless limit <= 0.
+func (y *YouTubeProvider) listURL(url string, limit int, emit func(provider.Track)) error {
+	args := []string{"-j", "--flat-playlist"}
+	if limit > 0 {
+		// yt-dlp stops listing there, and skips looking up later pages
+		args = append(args, "--playlist-end", strconv.Itoa(limit))
 	}
-	cmd := getYtDlpCmd("-j", "--flat-playlist", url)
-	out, err := cmd.Output()
+	listed := 0
+	err := runJSON(func(meta map[string]interface{}) {
+		if limit > 0 && listed >= limit {
+			return
+		}
+		if t, ok := y.trackFromMeta(meta); ok {
+			listed++
+			emit(t)
+		}
+	}, append(args, url)...)
+	if err == nil || listed > 0 {
+		// A playlist that fails part way still yields what was listed
+		return nil
+	}
+	// Try falling back to single JSON output for video URLs
+	err = runJSON(func(meta map[string]interface{}) {
+		if t, ok := y.trackFromMeta(meta); ok {
+			emit(t)
+		}
+	}, "-j", url)
 	if err != nil {
-		// Try falling back to single JSON output for video URLs
-		cmd2 := getYtDlpCmd("-j", url)
-		out, err = cmd2.Output()
-		if err != nil {
-			return nil, fmt.Errorf("yt-dlp extraction failed: %w", err)
+		if errors.Is(err, ErrYtDlpMissing) {
+			return err
 		}
+		return fmt.Errorf("yt-dlp extraction failed: %w", err)
 	}
+	return nil
+}
 
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	var tracks []provider.Track
-	for _, line := range lines {
-		if line == "" {
-			continue
+// playlistWorkers bounds how many playlist entries are looked up at once.
+const playlistWorkers = 4
+
+// StreamTracksFromURL is FetchTracksFromURL for large playlists. Entries are
+// handled as yt-dlp lists them: those its flat listing left without a
+// title, uploader or duration are looked up with up to playlistWorkers
+// concurrent yt-dlp calls. add receives the tracks in playlist order as
+// soon as they and every entry before them are ready, and progress is told
+// how many of the entries listed so far are done. It returns the number of
+// entries.
+func (y *YouTubeProvider) StreamTracksFromURL(url string, add func([]provider.Track), progress func(done, listed int)) (int, error) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		tracks []provider.Track
+		ready  []bool
+		next   int
+		done   int
+	)
+	slots := make(chan struct{}, playlistWorkers)
+
+	// flush hands over the ready run at the front. Must be called with mu held,
+	// which also keeps add calls in order.
+	flush := func() {
+		start := next
+		for next < len(tracks) && ready[next] {
+			next++
+		}
+		if next > start {
+			add(append([]provider.Track(nil), tracks[start:next]...))
+		}
+		progress(done, len(tracks))
+	}
+
+	err := y.listURL(url, 0, func(t provider.Track) {
+		mu.Lock()
+		idx := len(tracks)
+		tracks = append(tracks, t)
+		ready = append(ready, !incomplete(t))
+		if ready[idx] {
+			done++
+			flush()
+			mu.Unlock()
+			return
 		}
+		mu.Unlock()
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if full, err := y.GetTrack(t.ID); err == nil {
+				t = full
+			}
+			mu.Lock()
+			tracks[idx] = t
+			ready[idx] = true
+			done++
+			flush()
+			mu.Unlock()
+		}()
+	})
+	wg.Wait()
+	if err != nil {
+		return 0, err
+	}
+	if len(tracks) == 0 {
+		return 0, fmt.Errorf("no tracks found for url")
+	}
+	return len(tracks), nil
+}
+
+// incomplete reports whether a flat playlist entry lacks metadata that a
+// full lookup would provide.
+func incomplete(t provider.Track) bool {
+	return t.Title == "" || t.Artist == "" || t.Duration == 0
+}
+
+// trackFromMeta builds a track from one yt-dlp JSON object, with the
+// artist and title split from the video title and tidied (see package
+// trackmeta). The uploading channel is kept in the "channel" tag.
+func (y *YouTubeProvider) trackFromMeta(meta map[string]interface{}) (provider.Track, bool) {
+	uploader := safeString(meta["uploader"])
+	if uploader == "" {
+		uploader = safeString(meta["channel"])
+	}
+	artist := safeString(meta["artist"])
+	if artists, ok := meta["artists"].([]interface{}); ok && len(artists) > 0 {
+		names := make([]string, 0, len(artists))
+		for _, a := range artists {
+			if name := safeString(a); name != "" {
+				names = append(names, name)
+			}
+		}
+		artist = strings.Join(names, ", ")
+	}
+	song := trackmeta.Normalize(trackmeta.Video{
+		Title:    safeString(meta["title"]),
+		Uploader: uploader,
+		Artist:   artist,
+		Track:    safeString(meta["track"]),
+		Album:    safeString(meta["album"]),
+	})
+	duration := int(safeFloat64(meta["duration"]))
+	id := safeString(meta["id"])
+	if id == "" {
+		id = safeString(meta["url"])
+	}
+	if id == "" {
+		return provider.Track{}, false
+	}
+
+	t := provider.Track{
+		ID:       "youtube:" + id,
+		Provider: y.Name(),
+		Title:    song.Title,
+		Artist:   song.Artist,
+		Album:    song.Album,
+		Duration: duration,
+		Links:    map[string]string{"youtube": fmt.Sprintf("https://www.youtube.com/watch?v=%s", id)},
+	}
+	if uploader != "" && uploader != song.Artist {
+		t.Tags = map[string]string{"channel": uploader}
+	}
+	lang.Tag(&t, safeString(meta["language"]))
+	return t, true
+}
+
+// runJSON runs yt-dlp with args and calls each with every JSON object it
+// prints as soon as the line arrives, rather than after yt-dlp exits.
+// Lines that aren't JSON objects are skipped. A run YouTube rate limited is
+// retried as backoff says, unless it printed some objects already.
+func runJSON(each func(meta map[string]interface{}), args ...string) error {
+	for attempt := 0; ; attempt++ {
+		printed := false
+		err := runJSONOnce(func(meta map[string]interface{}) {
+			printed = true
+			each(meta)
+		}, args...)
+		if again := backoff(err, attempt); printed || !again {
+			return err
+		}
+	}
+}
+
+// runJSONOnce is runJSON without the retries.
+func runJSONOnce(each func(meta map[string]interface{}), args ...string) error {
+	limiter.Wait()
+	cmd := getYtDlpCmd(args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return ytDlpError(err)
+	}
+	r := bufio.NewReader(stdout)
+	for {
+		// Lines with full format lists can be far longer than a Scanner allows
+		line, readErr := r.ReadBytes('\n')
 		var meta map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &meta); err != nil {
-			continue
+		if err := json.Unmarshal(bytes.TrimSpace(line), &meta); err == nil {
+			each(meta)
 		}
-		title := safeString(meta["title"])
-		uploader := safeString(meta["uploader"])
-		if uploader == "" {
-			uploader = safeString(meta["channel"])
+		if readErr != nil {
+			break
 		}
-		duration := int(safeFloat64(meta["duration"]))
+	}
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return ytDlpError(err)
+}
+
+// ErrSignInRequired is returned when listing the user's playlists without
+// cookies for a signed-in account.
+var ErrSignInRequired = errors.New("sign-in required: set cookies or cookies_from_browser in the config")
+
+// Playlists lists the playlists of the account yt-dlp is signed in to with
+// the configured cookies.
+func (y *YouTubeProvider) Playlists() ([]provider.Playlist, error) {
+	if ytDlpOptions["cookies"] == "" && ytDlpOptions["cookies-from-browser"] == "" {
+		return nil, ErrSignInRequired
+	}
+	var lists []provider.Playlist
+	err := runJSON(func(meta map[string]interface{}) {
 		id := safeString(meta["id"])
 		if id == "" {
-			id = safeString(meta["url"])
-		}
-		if id == "" {
-			continue
+			return
 		}
-
-		t := provider.Track{
-			ID:       "youtube:" + id,
+		lists = append(lists, provider.Playlist{
+			ID:       id,
 			Provider: y.Name(),
-			Title:    title,
-			Artist:   uploader,
-			Duration: duration,
-			Links:    map[string]string{"youtube": fmt.Sprintf("https://www.youtube.com/watch?v=%s", id)},
-		}
-		tracks = append(tracks, t)
+			Name:     safeString(meta["title"]),
+			Count:    int(safeFloat64(meta["playlist_count"])),
+		})
+	}, "-j", "--flat-playlist", "https://www.youtube.com/feed/playlists")
+	if err != nil && len(lists) == 0 {
+		return nil, err
 	}
+	return lists, nil
+}
+
+// PlaylistTracks returns the videos of the playlist with id.
+func (y *YouTubeProvider) PlaylistTracks(id string) ([]provider.Track, error) {
+	return y.FetchTracksFromURL("https://www.youtube.com/playlist?list="+url.QueryEscape(id), 0)
+}
 
+// Related returns up to limit videos of the YouTube mix started from track,
+// leaving out track itself. Tracks from elsewhere are first looked up by
+// artist and title.
+func (y *YouTubeProvider) Related(track provider.Track, limit int) ([]provider.Track, error) {
+	id := strings.TrimPrefix(track.ID, "youtube:")
+	if track.Provider != y.Name() {
+		found, err := y.Search(strings.TrimSpace(track.Artist+" "+track.Title), provider.SearchKindTrack, 1)
+		if err != nil {
+			return nil, err
+		}
+		id = strings.TrimPrefix(found[0].ID, "youtube:")
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	mix := fmt.Sprintf("https://www.youtube.com/watch?v=%s&list=RD%s", url.QueryEscape(id), url.QueryEscape(id))
+	var tracks []provider.Track
+	err := runJSON(func(meta map[string]interface{}) {
+		if t, ok := y.trackFromMeta(meta); ok && t.ID != "youtube:"+id && t.ID != track.ID {
+			tracks = append(tracks, t)
+		}
+	}, "-j", "--flat-playlist", "--playlist-end", strconv.Itoa(limit+1), mix)
 	if len(tracks) == 0 {
-		return nil, fmt.Errorf("no tracks found for url")
+		if err != nil {
+			return nil, fmt.Errorf("youtube mix: %w", err)
+		}
+		return nil, fmt.Errorf("youtube mix: %w", provider.ErrNotFound)
+	}
+	if len(tracks) > limit {
+		tracks = tracks[:limit]
 	}
 	return tracks, nil
 }
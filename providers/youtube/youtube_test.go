@@ -0,0 +1,63 @@
+package youtube
+
+import (
+	"testing"
+
+	"audictl/internal/provider"
+)
+
+func TestFormatSelector(t *testing.T) {
+	defer SetQuality(quality)
+	for _, tc := range []struct {
+		name    string
+		quality Quality
+		pref    provider.QualityPref
+		want    string
+	}{
+		{
+			"default", Quality{}, provider.QualityAny,
+			"bestaudio[acodec^=opus]/bestaudio[acodec^=mp4a]/bestaudio/best",
+		},
+		{
+			"codecs and limits", Quality{Codecs: []string{" Vorbis", "m4a"}, MinKbps: 96, MaxKbps: 192}, provider.QualityAny,
+			"bestaudio[acodec^=vorbis][abr>=?96][abr<=?192]/bestaudio[acodec^=mp4a][abr>=?96][abr<=?192]/bestaudio[abr>=?96][abr<=?192]/bestaudio/best",
+		},
+		{
+			"low", Quality{}, provider.QualityLow,
+			"worstaudio[acodec^=opus]/worstaudio[acodec^=mp4a]/worstaudio/worst",
+		},
+		{
+			"low with a floor", Quality{MinKbps: 64}, provider.QualityLow,
+			"worstaudio[acodec^=opus][abr>=?64]/worstaudio[acodec^=mp4a][abr>=?64]/worstaudio[abr>=?64]/worstaudio/worst",
+		},
+		{
+			"lossless", Quality{}, provider.QualityLosslessFirst,
+			"bestaudio[acodec^=flac]/bestaudio[acodec^=alac]/bestaudio[abr>=320]/bestaudio[abr>=256]/bestaudio[abr>=192]/bestaudio[abr>=160]/bestaudio[abr>=128]/bestaudio/best",
+		},
+		{
+			"lossless within limits", Quality{MinKbps: 160, MaxKbps: 256}, provider.QualityLosslessFirst,
+			"bestaudio[acodec^=flac][abr>=?160][abr<=?256]/bestaudio[acodec^=alac][abr>=?160][abr<=?256]/bestaudio[abr>=256][abr>=?160][abr<=?256]/bestaudio[abr>=192][abr>=?160][abr<=?256]/bestaudio[abr>=?160][abr<=?256]/bestaudio/best",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			SetQuality(tc.quality)
+			if got := FormatSelector(tc.pref); got != tc.want {
+				t.Errorf("FormatSelector() =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsLossless(t *testing.T) {
+	for acodec, want := range map[string]bool{
+		"flac":      true,
+		"ALAC":      true,
+		"opus":      false,
+		"mp4a.40.2": false,
+		"":          false,
+	} {
+		if got := isLossless(acodec); got != want {
+			t.Errorf("isLossless(%q) = %v, want %v", acodec, got, want)
+		}
+	}
+}